@@ -0,0 +1,222 @@
+// Package apierr 将存储后端（gohbase、HBase Thrift2）和服务内部的错误转换为 gRPC status
+//
+// 转换后的 status 带有以下 errdetails：
+//   - ErrorInfo：Domain 为 seqdb，Reason 为本包定义的 Reason*，Metadata 中带有出错的 biz_id 和 seq
+//   - 出错的 SeqKey 本身，客户端可以用 KeyOf 取出
+//   - RetryInfo：仅在重试可能成功时附带，RetryDelay 为建议的等待时间，客户端可以用 RetryDelay 取出
+package apierr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "go-hbase-demo/cloudpb"
+
+	"demo/gen-go/hbase" // Thrift 生成的 HBase 客户端代码
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/tsuna/gohbase"
+	"github.com/tsuna/gohbase/region"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain 是 ErrorInfo 的 Domain
+const Domain = "seqdb"
+
+// ErrorInfo 的 Reason
+const (
+	ReasonNotFound          = "KEY_NOT_FOUND"      // key 不存在
+	ReasonTableNotFound     = "TABLE_NOT_FOUND"    // 表或 namespace 不存在
+	ReasonInvalidArgument   = "INVALID_ARGUMENT"   // 请求参数被服务端或 HBase 拒绝
+	ReasonRegionUnavailable = "REGION_UNAVAILABLE" // region 正在迁移、分裂或 RegionServer 不可用
+	ReasonBusy              = "BACKEND_BUSY"       // RegionServer 过载
+	ReasonTimeout           = "TIMEOUT"            // 请求超时
+	ReasonCanceled          = "CANCELED"           // 请求被取消
+	ReasonBackend           = "BACKEND_ERROR"      // 其他存储后端错误
+)
+
+// ErrNotFound 表示读取的 key 不存在
+var ErrNotFound = errors.New("key not found")
+
+// class 是一类错误对应的 gRPC 状态码、Reason 和重试建议，retry 为 0 表示不建议重试
+type class struct {
+	code   codes.Code
+	reason string
+	retry  time.Duration
+}
+
+var (
+	notFound          = class{codes.NotFound, ReasonNotFound, 0}
+	tableNotFound     = class{codes.NotFound, ReasonTableNotFound, 0}
+	invalidArgument   = class{codes.InvalidArgument, ReasonInvalidArgument, 0}
+	regionUnavailable = class{codes.Unavailable, ReasonRegionUnavailable, 500 * time.Millisecond}
+	busy              = class{codes.ResourceExhausted, ReasonBusy, time.Second}
+	timeout           = class{codes.DeadlineExceeded, ReasonTimeout, time.Second}
+	canceled          = class{codes.Canceled, ReasonCanceled, 0}
+	backend           = class{codes.Internal, ReasonBackend, 0}
+)
+
+// javaExceptions 按 HBase 服务端异常的类名（出现在 TIOError 和 gohbase 错误的消息中）分类，按顺序匹配
+var javaExceptions = []struct {
+	name  string
+	class class
+}{
+	{"TableNotFoundException", tableNotFound},
+	{"NamespaceNotFoundException", tableNotFound},
+	{"NotServingRegionException", regionUnavailable},
+	{"RegionMovedException", regionUnavailable},
+	{"RegionOfflineException", regionUnavailable},
+	{"RegionOpeningException", regionUnavailable},
+	{"ServerNotRunningYetException", regionUnavailable},
+	{"PleaseHoldException", regionUnavailable},
+	{"RegionTooBusyException", busy},
+	{"CallQueueTooBigException", busy},
+	{"RetriesExhaustedException", regionUnavailable},
+	{"CallTimeoutException", timeout},
+	{"SocketTimeoutException", timeout},
+	{"DoNotRetryIOException", backend},
+}
+
+// classify 判断 err 属于哪一类错误
+func classify(err error) class {
+	var (
+		ioErr      *hbase.TIOError
+		illegalErr *hbase.TIllegalArgument
+		transport  thrift.TTransportException
+		netErr     net.Error
+	)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return notFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return timeout
+	case errors.Is(err, context.Canceled):
+		return canceled
+	case errors.Is(err, gohbase.TableNotFound):
+		return tableNotFound
+	case errors.As(err, &illegalErr):
+		return invalidArgument
+	case errors.As(err, &ioErr):
+		if c, ok := matchException(ioErr.GetMessage()); ok {
+			return c
+		}
+		return regionUnavailable // 其他 IOException 通常是暂时的
+	case errors.Is(err, gohbase.ErrCannotFindRegion), isRegionError(err):
+		return regionUnavailable
+	case errors.As(err, &transport):
+		if transport.TypeId() == thrift.TIMED_OUT {
+			return timeout
+		}
+		return regionUnavailable
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return timeout
+		}
+		return regionUnavailable
+	}
+	if c, ok := matchException(err.Error()); ok {
+		return c
+	}
+	return backend
+}
+
+// isRegionError 判断 err 是否为 gohbase 的 region 错误，这些错误在 region 恢复后重试即可成功
+func isRegionError(err error) bool {
+	var (
+		notServing region.NotServingRegionError
+		retryable  region.RetryableError
+		server     region.ServerError
+		offline    region.OfflineRegionError
+	)
+	return errors.As(err, &notServing) || errors.As(err, &retryable) || errors.As(err, &server) || errors.As(err, &offline)
+}
+
+func matchException(msg string) (class, bool) {
+	for _, e := range javaExceptions {
+		if strings.Contains(msg, e.name) {
+			return e.class, true
+		}
+	}
+	return class{}, false
+}
+
+// Code 返回 err 对应的 gRPC 状态码，err 已经是 status 时返回其状态码
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if st, ok := status.FromError(err); ok {
+		return st.Code()
+	}
+	return classify(err).code
+}
+
+// Status 将 err 转换为带 errdetails 的 gRPC status 错误，key 为出错的 SeqKey，未知时为 nil
+// err 已经是 status 时原样返回；err 为 nil 时返回 nil
+func Status(err error, key *pb.SeqKey) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	c := classify(err)
+	info := &errdetails.ErrorInfo{Reason: c.reason, Domain: Domain}
+	details := []protoadapt.MessageV1{info}
+	if key != nil {
+		info.Metadata = map[string]string{"biz_id": string(key.BizId), "seq": strconv.Itoa(int(key.Seq))}
+		details = append(details, key)
+	}
+	if c.retry > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(c.retry)})
+	}
+	st, detailErr := status.New(c.code, err.Error()).WithDetails(details...)
+	if detailErr != nil {
+		return status.Error(c.code, err.Error())
+	}
+	return st.Err()
+}
+
+// KeyOf 返回 Status 附带的 SeqKey，没有时返回 nil
+func KeyOf(err error) *pb.SeqKey {
+	for _, detail := range status.Convert(err).Details() {
+		if key, ok := detail.(*pb.SeqKey); ok {
+			return key
+		}
+	}
+	return nil
+}
+
+// RetryDelay 返回 Status 附带的重试建议，不建议重试时 ok 为 false
+func RetryDelay(err error) (delay time.Duration, ok bool) {
+	for _, detail := range status.Convert(err).Details() {
+		if info, isRetry := detail.(*errdetails.RetryInfo); isRetry {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// UnaryServerInterceptor 将 handler 返回的非 status 错误转换为 gRPC status
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, Status(err, nil)
+	}
+}
+
+// StreamServerInterceptor 是 UnaryServerInterceptor 的流式版本
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return Status(handler(srv, ss), nil)
+	}
+}
@@ -0,0 +1,87 @@
+package apierr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	pb "go-hbase-demo/cloudpb"
+
+	"demo/gen-go/hbase"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/tsuna/gohbase"
+	"github.com/tsuna/gohbase/region"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestStatus(t *testing.T) {
+	message := func(msg string) *string { return &msg }
+	tests := []struct {
+		name   string
+		err    error
+		code   codes.Code
+		reason string
+		retry  bool
+	}{
+		{"not found", fmt.Errorf("get: %w", ErrNotFound), codes.NotFound, ReasonNotFound, false},
+		{"deadline", context.DeadlineExceeded, codes.DeadlineExceeded, ReasonTimeout, true},
+		{"canceled", context.Canceled, codes.Canceled, ReasonCanceled, false},
+		{"gohbase table not found", gohbase.TableNotFound, codes.NotFound, ReasonTableNotFound, false},
+		{"gohbase not serving region", region.NotServingRegionError{}, codes.Unavailable, ReasonRegionUnavailable, true},
+		{"thrift illegal argument", &hbase.TIllegalArgument{Message: message("bad row")}, codes.InvalidArgument, ReasonInvalidArgument, false},
+		{"thrift region moved", &hbase.TIOError{Message: message("org.apache.hadoop.hbase.exceptions.RegionMovedException: moved")}, codes.Unavailable, ReasonRegionUnavailable, true},
+		{"thrift too busy", &hbase.TIOError{Message: message("org.apache.hadoop.hbase.RegionTooBusyException: busy")}, codes.ResourceExhausted, ReasonBusy, true},
+		{"thrift table not found", fmt.Errorf("scan: %w", &hbase.TIOError{Message: message("TableNotFoundException: t")}), codes.NotFound, ReasonTableNotFound, false},
+		{"thrift timeout", thrift.NewTTransportException(thrift.TIMED_OUT, "i/o timeout"), codes.DeadlineExceeded, ReasonTimeout, true},
+		{"thrift connection", thrift.NewTTransportException(thrift.NOT_OPEN, "connection refused"), codes.Unavailable, ReasonRegionUnavailable, true},
+		{"java exception text", errors.New("org.apache.hadoop.hbase.NotServingRegionException: r1"), codes.Unavailable, ReasonRegionUnavailable, true},
+		{"other", errors.New("boom"), codes.Internal, ReasonBackend, false},
+	}
+	key := &pb.SeqKey{BizId: []byte("biz1"), Seq: 7}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Status(tt.err, key)
+			st := status.Convert(err)
+			if st.Code() != tt.code || Code(tt.err) != tt.code {
+				t.Fatalf("Status() code = %v, Code() = %v, want %v", st.Code(), Code(tt.err), tt.code)
+			}
+			var info *errdetails.ErrorInfo
+			for _, detail := range st.Details() {
+				if i, ok := detail.(*errdetails.ErrorInfo); ok {
+					info = i
+				}
+			}
+			if info == nil || info.Reason != tt.reason || info.Domain != Domain || info.Metadata["biz_id"] != "biz1" || info.Metadata["seq"] != "7" {
+				t.Errorf("ErrorInfo = %v, want reason %s with the key", info, tt.reason)
+			}
+			if !proto.Equal(KeyOf(err), key) {
+				t.Errorf("KeyOf() = %v, want %v", KeyOf(err), key)
+			}
+			if delay, ok := RetryDelay(err); ok != tt.retry || ok && delay <= 0 {
+				t.Errorf("RetryDelay() = %v, %v, want retry %v", delay, ok, tt.retry)
+			}
+		})
+	}
+}
+
+func TestStatus_Passthrough(t *testing.T) {
+	if Status(nil, nil) != nil {
+		t.Error("Status(nil) != nil")
+	}
+	err := status.Error(codes.AlreadyExists, "exists")
+	if got := Status(err, &pb.SeqKey{}); got != err {
+		t.Errorf("Status(status error) = %v, want unchanged", got)
+	}
+	if _, ok := RetryDelay(err); ok || KeyOf(err) != nil {
+		t.Error("status error without details has details")
+	}
+	if delay, _ := RetryDelay(Status(context.DeadlineExceeded, nil)); delay != time.Second {
+		t.Errorf("RetryDelay() = %v, want 1s", delay)
+	}
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/store"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+// putLogLease 是原子 Put 预写日志的租约，超过租约仍未删除的日志视为写入方已崩溃，
+// 下一次对同一 BizId 的原子 Put 会先恢复该日志再继续
+const putLogLease = 30 * time.Second
+
+// putLogRenewal 是原子 Put 写入 item 期间续约日志的间隔，须明显短于 putLogLease
+var putLogRenewal = putLogLease / 3
+
+var errPutInProgress = errors.New("another atomic put is in progress for this biz_id")
+
+// atomicPut 按 BizId 分组并发写入 items，每组要么全部写入，要么全部不写入
+//
+// HBase 只保证单行原子性，而同一 BizId 的 item 分布在不同的行上，因此每组的写入过程为：
+//  1. 在 BizId 前缀行（codec.Prefix，与 AllocateSeq 计数器同行）上以 CheckAndPut 写入预写日志，
+//     日志同时充当该 BizId 的写锁，同一 BizId 的原子 Put 互斥
+//  2. 读取各 key 写入前的值并记录到日志中
+//  3. 写入所有 item，失败时按日志恢复写入前的值
+//  4. 删除日志
+//
+// 租约从日志的 StartedAt 起算，第 2 步写入日志时更新 StartedAt，第 3 步期间每隔 putLogRenewal 续约，
+// 耗时超过租约的写入不会在进行中被其他写入方接管并回滚
+//
+// 原子性只对原子 Put 之间成立，普通 Put 不检查日志
+// 未写入的组中每个 item 的状态码为 Aborted；ttl 为请求的 TTL，见 retention.Policies.TTL
+func (s *server) atomicPut(ctx context.Context, items []*pb.SeqItem, ttl time.Duration) *pb.PutItemResp {
+	type group struct {
+		items      []*pb.SeqItem
+		timestamps map[string]int64
+		err        error
+	}
+	groups := map[string]*group{}
+	for _, item := range items {
+		if item.GetKey() == nil {
+			continue
+		}
+		g, ok := groups[string(item.Key.BizId)]
+		if !ok {
+			g = &group{}
+			groups[string(item.Key.BizId)] = g
+		}
+		g.items = append(g.items, item)
+	}
+
+	var wg sync.WaitGroup
+	for _, g := range groups {
+		wg.Add(1)
+		go func(g *group) {
+			defer wg.Done()
+			g.timestamps, g.err = s.putGroup(ctx, g.items, s.retention.TTL(g.items[0].Key.BizId, ttl))
+		}(g)
+	}
+	wg.Wait()
+
+	resp := &pb.PutItemResp{}
+	for _, item := range items {
+		if item.GetKey() == nil {
+			addPutStatus(resp, s.putStatus(item, 0, errMissingKey))
+			continue
+		}
+		g := groups[string(item.Key.BizId)]
+		st := s.putStatus(item, g.timestamps[string(s.codec.Encode(item.Key))], g.err)
+		if g.err != nil {
+			st.Code = int32(codes.Aborted)
+		}
+		addPutStatus(resp, st)
+	}
+	return resp
+}
+
+// putGroup 原子地以 ttl 写入同一 BizId 的 items，返回 nil 表示已全部写入，否则全部未写入
+// 写入成功时返回各 rowkey 写入的时间戳
+func (s *server) putGroup(ctx context.Context, items []*pb.SeqItem, ttl time.Duration) (map[string]int64, error) {
+	logRow := s.codec.Prefix(items[0].Key.BizId)
+	items = dedupItems(s.codec.Encode, items)
+	entries := make([]*pb.PutLogEntry, len(items))
+	keys := make([]*pb.SeqKey, len(items))
+	for i, item := range items {
+		entries[i] = &pb.PutLogEntry{Key: item.Key, After: item}
+		keys[i] = item.Key
+	}
+
+	// 先以未准备的日志加锁，再读取写入前的值，保证读到的值不会被其他原子 Put 修改
+	record := &pb.PutLog{StartedAt: time.Now().UnixMilli(), Entries: entries}
+	locked, err := s.lockPutLog(ctx, logRow, record)
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.store.Get(ctx, keys)
+	if err == nil {
+		err = s.fillExpiresAt(ctx, entries, before)
+	}
+	if err != nil {
+		return nil, s.releasePutLog(ctx, logRow, locked, fmt.Errorf("read current values: %v", err))
+	}
+	prepared := proto.Clone(record).(*pb.PutLog)
+	prepared.Prepared = true
+	prepared.StartedAt = time.Now().UnixMilli() // 续约，读取写入前的值可能耗时较长
+	for i, entry := range prepared.Entries {
+		entry.Before = before[i]
+	}
+	current, err := s.swapPutLog(ctx, logRow, locked, prepared)
+	if err != nil {
+		if errors.Is(err, errPutInProgress) {
+			return nil, err // 日志已属于其他写入方，不能删除
+		}
+		return nil, s.releasePutLog(ctx, logRow, locked, err)
+	}
+
+	stop := s.renewPutLog(ctx, logRow, current, prepared)
+	ts, err := s.store.Put(ctx, items, ttl)
+	current, renewErr := stop()
+	if errors.Is(renewErr, errPutInProgress) {
+		// 续约失败说明日志已被其他写入方接管，写入的 item 可能已被其恢复流程回滚
+		return nil, renewErr
+	}
+	if renewErr != nil {
+		log.Printf("Renew put log on %q failed: %v", logRow, renewErr)
+	}
+	if err != nil {
+		if rbErr := s.rollbackPutLog(ctx, prepared); rbErr != nil {
+			// 日志保留在前缀行上，租约过期后由下一次原子 Put 恢复
+			log.Printf("Rollback atomic put on %q failed: %v", logRow, rbErr)
+			return nil, fmt.Errorf("put failed: %v; rollback pending: %v", err, rbErr)
+		}
+		return nil, s.releasePutLog(ctx, logRow, current, fmt.Errorf("put failed: %v", err))
+	}
+	ok, err := s.store.DeleteWAL(ctx, logRow, current)
+	if err != nil {
+		// 所有 item 已写入，恢复时会发现当前值与日志中的 after 一致而不回滚
+		log.Printf("Delete put log on %q failed: %v", logRow, err)
+	} else if !ok {
+		// 租约过期后日志被其他写入方接管，写入的 item 可能已被其恢复流程回滚
+		return nil, errPutInProgress
+	}
+	timestamps := make(map[string]int64, len(items))
+	for i, item := range items {
+		timestamps[string(s.codec.Encode(item.Key))] = ts[i]
+	}
+	return timestamps, nil
+}
+
+// fillExpiresAt 读取 before 中已存在的值的时间戳，为 entries 计算写入前的值的过期时间
+// HBase 读取时不返回 cell 的 TTL，过期时间按写入时间戳加 BizId 保留策略的 TTL 计算，
+// 写入时请求了更短 TTL 的值恢复后会晚于原本的过期时间过期，但不会超过保留策略
+func (s *server) fillExpiresAt(ctx context.Context, entries []*pb.PutLogEntry, before []*pb.SeqItem) error {
+	ttl := s.retention.TTL(entries[0].Key.BizId, 0)
+	if ttl <= 0 {
+		return nil
+	}
+	errs := make([]error, len(entries))
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		if before[i] == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, entry *pb.PutLogEntry) {
+			defer wg.Done()
+			row, err := s.store.GetRow(ctx, s.codec.Encode(entry.Key), store.Versions{})
+			if err != nil || row == nil {
+				errs[i] = err
+				return
+			}
+			entry.BeforeExpiresAt = row.Timestamp + ttl.Milliseconds()
+		}(i, entry)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// dedupItems 去掉 rowkey 重复的 item，保留最后一个，与逐个写入时后者覆盖前者的结果一致
+func dedupItems(encode func(*pb.SeqKey) []byte, items []*pb.SeqItem) []*pb.SeqItem {
+	last := make(map[string]int, len(items))
+	for i, item := range items {
+		last[string(encode(item.Key))] = i
+	}
+	if len(last) == len(items) {
+		return items
+	}
+	deduped := make([]*pb.SeqItem, 0, len(last))
+	for i, item := range items {
+		if last[string(encode(item.Key))] == i {
+			deduped = append(deduped, item)
+		}
+	}
+	return deduped
+}
+
+// lockPutLog 在 logRow 上写入 record 作为锁，返回写入的日志内容
+// 已有日志且租约未过期时返回 errPutInProgress；租约已过期时先恢复旧日志再接管
+func (s *server) lockPutLog(ctx context.Context, logRow []byte, record *pb.PutLog) ([]byte, error) {
+	data, err := proto.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.store.PutWAL(ctx, logRow, nil, data)
+	if err != nil || ok {
+		return data, err
+	}
+
+	current, err := s.store.GetWAL(ctx, logRow)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, errPutInProgress // 日志刚被删除，由调用方重试
+	}
+	stale := &pb.PutLog{}
+	if err := proto.Unmarshal(current, stale); err != nil {
+		return nil, fmt.Errorf("corrupted put log: %v", err)
+	}
+	if time.Since(time.UnixMilli(stale.StartedAt)) < putLogLease {
+		return nil, errPutInProgress
+	}
+	if stale.Prepared {
+		if err := s.recoverPutLog(ctx, stale); err != nil {
+			return nil, fmt.Errorf("recover stale put log: %v", err)
+		}
+	}
+	ok, err = s.store.PutWAL(ctx, logRow, current, data)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errPutInProgress
+	}
+	return data, nil
+}
+
+// swapPutLog 将 logRow 上的日志从 current 替换为 record，返回写入的日志内容
+func (s *server) swapPutLog(ctx context.Context, logRow, current []byte, record *pb.PutLog) ([]byte, error) {
+	data, err := proto.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.store.PutWAL(ctx, logRow, current, data)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errPutInProgress // 租约过期后被其他写入方接管
+	}
+	return data, nil
+}
+
+// renewPutLog 每隔 putLogRenewal 以新的 StartedAt 将 logRow 上的日志从 current 替换为 record，直到调用返回的 stop
+// stop 返回日志的最新内容，续约失败时停止续约并由 stop 返回错误，日志已被接管时为 errPutInProgress
+func (s *server) renewPutLog(ctx context.Context, logRow, current []byte, record *pb.PutLog) (stop func() ([]byte, error)) {
+	record = proto.Clone(record).(*pb.PutLog)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	var renewErr error
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(putLogRenewal)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			record.StartedAt = time.Now().UnixMilli()
+			data, err := s.swapPutLog(ctx, logRow, current, record)
+			if err != nil {
+				renewErr = err
+				return
+			}
+			current = data
+		}
+	}()
+	return func() ([]byte, error) {
+		close(done)
+		<-exited
+		return current, renewErr
+	}
+}
+
+// releasePutLog 删除本次写入的日志 current 并返回 cause，用于放弃本次原子 Put
+// 日志已被其他写入方接管时不删除，返回 errPutInProgress
+func (s *server) releasePutLog(ctx context.Context, logRow, current []byte, cause error) error {
+	ok, err := s.store.DeleteWAL(ctx, logRow, current)
+	if err != nil {
+		log.Printf("Delete put log on %q failed: %v", logRow, err)
+	} else if !ok {
+		return errPutInProgress
+	}
+	return cause
+}
+
+// recoverPutLog 处理崩溃的原子 Put 留下的已准备日志：
+// 所有 key 的当前值都等于 after 时说明写入已完成，否则回滚到 before
+func (s *server) recoverPutLog(ctx context.Context, record *pb.PutLog) error {
+	keys := make([]*pb.SeqKey, len(record.Entries))
+	for i, entry := range record.Entries {
+		keys[i] = entry.Key
+	}
+	current, err := s.store.Get(ctx, keys)
+	if err != nil {
+		return err
+	}
+	for i, entry := range record.Entries {
+		if !proto.Equal(current[i], entry.After) {
+			return s.rollbackPutLog(ctx, record)
+		}
+	}
+	return nil
+}
+
+// rollbackPutLog 将日志中的 key 恢复为写入前的值，原本不存在的 key 被删除
+// 恢复的值在日志记录的原过期时间过期，已过原过期时间的值不再恢复而是删除
+func (s *server) rollbackPutLog(ctx context.Context, record *pb.PutLog) error {
+	var (
+		restore = map[int64][]*pb.SeqItem{} // 按过期时间分组，每组以相同的剩余 TTL 写入
+		remove  [][]byte
+	)
+	now := time.Now().UnixMilli()
+	for _, entry := range record.Entries {
+		if entry.Before != nil && (entry.BeforeExpiresAt == 0 || entry.BeforeExpiresAt > now) {
+			restore[entry.BeforeExpiresAt] = append(restore[entry.BeforeExpiresAt], entry.Before)
+		} else {
+			remove = append(remove, s.codec.Encode(entry.Key))
+		}
+	}
+	for expiresAt, items := range restore {
+		var ttl time.Duration
+		if expiresAt > 0 {
+			ttl = time.Duration(expiresAt-now) * time.Millisecond
+		}
+		if _, err := s.store.Put(ctx, items, ttl); err != nil {
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		return s.store.Delete(ctx, remove)
+	}
+	return nil
+}
@@ -2,39 +2,25 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
-
-	// "crypto/tls"
-	// "crypto/x509"
+	"flag"
 	"fmt"
 	"log"
-
-	// "os"
+	"os"
 	"time"
 
 	pb "go-hbase-demo/cloudpb"
-
-	"google.golang.org/grpc"
-	// "google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/credentials"
-	//"google.golang.org/grpc/credentials/insecure"
+	"go-hbase-demo/config"
+	"go-hbase-demo/dial"
 )
 
 func main() {
-	// 连接到 gRPC 服务器
-
-	// proxyCA := "/var/tmp/fullchain.pem" // CA cert that signed the proxy
-	// f, err := os.ReadFile(proxyCA)
-
-	// p := x509.NewCertPool()
-	// p.AppendCertsFromPEM(f)
-	// tlsConfig := &tls.Config{
-	// 	RootCAs: p,
-	// }
-	conn, err := grpc.Dial("ld-7xv325q01b2720rk9-proxy-lindorm-pub.lindorm.rds.aliyuncs.com:9190", grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})))
-
-	// conn, err := grpc.NewClient("ld-7xv325q01b2720rk9-proxy-lindorm-pub.lindorm.rds.aliyuncs.com:9190", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
 
+	// 连接到 gRPC 服务器
+	conn, err := dial.Dial(cfg.Client)
 	if err != nil {
 		log.Fatalf("did not connect: %v", err)
 	}
@@ -86,7 +72,7 @@ func main() {
 	time.Sleep(2 * time.Second)
 
 	// 测试 Get 方法
-	getReq := &pb.SeqKey{
+	getReq := &pb.GetReq{
 		BizId: []byte("biz1"),
 		Seq:   1,
 	}
@@ -97,29 +83,28 @@ func main() {
 	}
 	fmt.Printf("Get request successful: %v\n", getResp)
 
-	// 测试BatchGet方法
-	//  batchGetReq := []*pb.SeqKey{
-	//     {
-	//         BizId: []byte("biz1"),
-	//         Seq:   1,
-	//     },
-	//     {
-	//         BizId: []byte("biz2"),
-	//         Seq:   2,
-	//     },
-	//     // 添加更多的测试数据...
-	// }
-
-	// // 调用 BatchGet 方法
-	// batchGetResp, err := client.BatchGet(context.Background(), batchGetReq)
-	// if err != nil {
-	//     log.Fatalf("BatchGet failed: %v", err)
-	// }
-
-	// // 打印结果
-	// fmt.Printf("BatchGet request successful:\n")
-	// for _, item := range batchGetResp.Items {
-	//     fmt.Printf("Retrieved item: %v\n", item)
+	// 测试 BatchGet 方法
+	batchGetReq := &pb.SeqKeys{
+		Keys: []*pb.SeqKey{
+			{
+				BizId: []byte("biz1"),
+				Seq:   1,
+			},
+			{
+				BizId: []byte("biz2"),
+				Seq:   2,
+			},
+		},
+	}
+
+	batchGetResp, err := client.BatchGet(context.Background(), batchGetReq)
+	if err != nil {
+		log.Fatalf("BatchGet failed: %v", err)
+	}
+	fmt.Printf("BatchGet request successful:\n")
+	for _, result := range batchGetResp.Results {
+		fmt.Printf("Key: %v, found: %v, item: %v\n", result.Key, result.Found, result.Item)
+	}
 
 	// 测试 GetMaxKey 方法
 	getMaxKeyReq := &pb.SeqKey{
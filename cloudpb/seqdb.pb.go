@@ -72,6 +72,7 @@ func (RangeOption) EnumDescriptor() ([]byte, []int) {
 	return file_seqdb_proto_rawDescGZIP(), []int{0}
 }
 
+// 请求中的 SeqKey 须满足 validate 包的规则：biz_id 非空且不超过 1024 字节，seq 非负
 type SeqKey struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -134,6 +135,9 @@ type SeqItem struct {
 
 	Key   *SeqKey `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	Value []byte  `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// 以下字段只在指定了 as_of_timestamp、time_range 或 max_versions 的读取中返回，写入时忽略
+	Timestamp int64             `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // value 的 HBase 时间戳，Unix 毫秒
+	Versions  []*SeqItemVersion `protobuf:"bytes,4,rep,name=versions,proto3" json:"versions,omitempty"`    // 满足条件的所有版本，按时间戳从新到旧，第一个即 value
 }
 
 func (x *SeqItem) Reset() {
@@ -182,18 +186,228 @@ func (x *SeqItem) GetValue() []byte {
 	return nil
 }
 
+func (x *SeqItem) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *SeqItem) GetVersions() []*SeqItemVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type SeqItemVersion struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Timestamp int64  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Value     []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *SeqItemVersion) Reset() {
+	*x = SeqItemVersion{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SeqItemVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeqItemVersion) ProtoMessage() {}
+
+func (x *SeqItemVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeqItemVersion.ProtoReflect.Descriptor instead.
+func (*SeqItemVersion) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SeqItemVersion) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *SeqItemVersion) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+// TimeRange 是 HBase 时间戳区间 [min, max)，单位 Unix 毫秒，max 为 0 表示不限制
+type TimeRange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Min int64 `protobuf:"varint,1,opt,name=min,proto3" json:"min,omitempty"`
+	Max int64 `protobuf:"varint,2,opt,name=max,proto3" json:"max,omitempty"`
+}
+
+func (x *TimeRange) Reset() {
+	*x = TimeRange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TimeRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeRange) ProtoMessage() {}
+
+func (x *TimeRange) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeRange.ProtoReflect.Descriptor instead.
+func (*TimeRange) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TimeRange) GetMin() int64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *TimeRange) GetMax() int64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+// GetReq 的前两个字段与 SeqKey 相同，以 SeqKey 编码的旧请求仍然有效
+type GetReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BizId         []byte     `protobuf:"bytes,1,opt,name=biz_id,json=bizId,proto3" json:"biz_id,omitempty"`
+	Seq           int32      `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	AsOfTimestamp int64      `protobuf:"varint,3,opt,name=as_of_timestamp,json=asOfTimestamp,proto3" json:"as_of_timestamp,omitempty"` // 只读取时间戳不大于 as_of_timestamp 的版本，0 表示不限制
+	TimeRange     *TimeRange `protobuf:"bytes,4,opt,name=time_range,json=timeRange,proto3" json:"time_range,omitempty"`                // 只读取时间戳在区间内的版本
+	MaxVersions   int32      `protobuf:"varint,5,opt,name=max_versions,json=maxVersions,proto3" json:"max_versions,omitempty"`         // 最多返回的版本数，0 表示 1
+}
+
+func (x *GetReq) Reset() {
+	*x = GetReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReq) ProtoMessage() {}
+
+func (x *GetReq) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReq.ProtoReflect.Descriptor instead.
+func (*GetReq) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetReq) GetBizId() []byte {
+	if x != nil {
+		return x.BizId
+	}
+	return nil
+}
+
+func (x *GetReq) GetSeq() int32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *GetReq) GetAsOfTimestamp() int64 {
+	if x != nil {
+		return x.AsOfTimestamp
+	}
+	return 0
+}
+
+func (x *GetReq) GetTimeRange() *TimeRange {
+	if x != nil {
+		return x.TimeRange
+	}
+	return nil
+}
+
+func (x *GetReq) GetMaxVersions() int32 {
+	if x != nil {
+		return x.MaxVersions
+	}
+	return 0
+}
+
 type SeqItems struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Items []*SeqItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Items         []*SeqItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	NextPageToken []byte     `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // 仅 QueryRange 使用，还有后续数据时非空，作为下一页请求的 page_token
+	Atomic        bool       `protobuf:"varint,3,opt,name=atomic,proto3" json:"atomic,omitempty"`                                     // 仅 Put 使用，为 true 时同一 BizId 的 items 要么全部写入，要么全部不写入
+	// 仅写入使用（Put、BatchPut、PutIfAbsent），大于 0 时 items 在 ttl_seconds 秒后过期；
+	// BizId 的保留策略设置了更短的 TTL 时以保留策略为准
+	TtlSeconds int64 `protobuf:"varint,4,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
 }
 
 func (x *SeqItems) Reset() {
 	*x = SeqItems{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_seqdb_proto_msgTypes[2]
+		mi := &file_seqdb_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -206,7 +420,7 @@ func (x *SeqItems) String() string {
 func (*SeqItems) ProtoMessage() {}
 
 func (x *SeqItems) ProtoReflect() protoreflect.Message {
-	mi := &file_seqdb_proto_msgTypes[2]
+	mi := &file_seqdb_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -219,7 +433,7 @@ func (x *SeqItems) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SeqItems.ProtoReflect.Descriptor instead.
 func (*SeqItems) Descriptor() ([]byte, []int) {
-	return file_seqdb_proto_rawDescGZIP(), []int{2}
+	return file_seqdb_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *SeqItems) GetItems() []*SeqItem {
@@ -229,29 +443,147 @@ func (x *SeqItems) GetItems() []*SeqItem {
 	return nil
 }
 
-type PutItemResp struct {
+func (x *SeqItems) GetNextPageToken() []byte {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return nil
+}
+
+func (x *SeqItems) GetAtomic() bool {
+	if x != nil {
+		return x.Atomic
+	}
+	return false
+}
+
+func (x *SeqItems) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type SeqItemsList struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	ItemsList []*SeqItems `protobuf:"bytes,1,rep,name=items_list,json=itemsList,proto3" json:"items_list,omitempty"`
 }
 
-func (x *PutItemResp) Reset() {
-	*x = PutItemResp{}
+func (x *SeqItemsList) Reset() {
+	*x = SeqItemsList{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_seqdb_proto_msgTypes[3]
+		mi := &file_seqdb_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *PutItemResp) String() string {
+func (x *SeqItemsList) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PutItemResp) ProtoMessage() {}
+func (*SeqItemsList) ProtoMessage() {}
 
-func (x *PutItemResp) ProtoReflect() protoreflect.Message {
-	mi := &file_seqdb_proto_msgTypes[3]
+func (x *SeqItemsList) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeqItemsList.ProtoReflect.Descriptor instead.
+func (*SeqItemsList) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SeqItemsList) GetItemsList() []*SeqItems {
+	if x != nil {
+		return x.ItemsList
+	}
+	return nil
+}
+
+type SeqKeys struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Keys []*SeqKey `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+}
+
+func (x *SeqKeys) Reset() {
+	*x = SeqKeys{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SeqKeys) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeqKeys) ProtoMessage() {}
+
+func (x *SeqKeys) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeqKeys.ProtoReflect.Descriptor instead.
+func (*SeqKeys) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SeqKeys) GetKeys() []*SeqKey {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type PutFailure struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key   *SeqKey `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Error string  `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *PutFailure) Reset() {
+	*x = PutFailure{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PutFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutFailure) ProtoMessage() {}
+
+func (x *PutFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -259,37 +591,1028 @@ func (x *PutItemResp) ProtoReflect() protoreflect.Message {
 		}
 		return ms
 	}
-	return mi.MessageOf(x)
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutFailure.ProtoReflect.Descriptor instead.
+func (*PutFailure) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PutFailure) GetKey() *SeqKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *PutFailure) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PutStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key       *SeqKey `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	RowKey    []byte  `protobuf:"bytes,2,opt,name=row_key,json=rowKey,proto3" json:"row_key,omitempty"` // 写入的 rowkey
+	Timestamp int64   `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`        // 写入的 HBase 时间戳，由服务端在写入时指定，Unix 毫秒，写入失败时为 0
+	Code      int32   `protobuf:"varint,4,opt,name=code,proto3" json:"code,omitempty"`                  // gRPC 状态码（google.golang.org/grpc/codes），0 表示写入成功
+	Error     string  `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`                 // 写入失败的原因
+}
+
+func (x *PutStatus) Reset() {
+	*x = PutStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PutStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutStatus) ProtoMessage() {}
+
+func (x *PutStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutStatus.ProtoReflect.Descriptor instead.
+func (*PutStatus) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PutStatus) GetKey() *SeqKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *PutStatus) GetRowKey() []byte {
+	if x != nil {
+		return x.RowKey
+	}
+	return nil
+}
+
+func (x *PutStatus) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *PutStatus) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PutStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PutItemResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Committed []*SeqKey     `protobuf:"bytes,1,rep,name=committed,proto3" json:"committed,omitempty"` // 已写入的 key
+	Failed    []*PutFailure `protobuf:"bytes,2,rep,name=failed,proto3" json:"failed,omitempty"`       // 未写入的 key 及原因，为空表示全部写入
+	Statuses  []*PutStatus  `protobuf:"bytes,3,rep,name=statuses,proto3" json:"statuses,omitempty"`   // 每个 item 的写入结果，与请求中的 items 按顺序一一对应
+}
+
+func (x *PutItemResp) Reset() {
+	*x = PutItemResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PutItemResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutItemResp) ProtoMessage() {}
+
+func (x *PutItemResp) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutItemResp.ProtoReflect.Descriptor instead.
+func (*PutItemResp) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PutItemResp) GetCommitted() []*SeqKey {
+	if x != nil {
+		return x.Committed
+	}
+	return nil
+}
+
+func (x *PutItemResp) GetFailed() []*PutFailure {
+	if x != nil {
+		return x.Failed
+	}
+	return nil
+}
+
+func (x *PutItemResp) GetStatuses() []*PutStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+// PutLog 是原子 Put 写在 BizId 前缀行上的预写日志，仅供服务端内部使用
+type PutLog struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StartedAt int64          `protobuf:"varint,1,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"` // 开始时间，Unix 毫秒
+	Prepared  bool           `protobuf:"varint,2,opt,name=prepared,proto3" json:"prepared,omitempty"`                    // 是否已记录写入前的值，为 false 时尚未写入任何 item
+	Entries   []*PutLogEntry `protobuf:"bytes,3,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *PutLog) Reset() {
+	*x = PutLog{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PutLog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutLog) ProtoMessage() {}
+
+func (x *PutLog) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutLog.ProtoReflect.Descriptor instead.
+func (*PutLog) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PutLog) GetStartedAt() int64 {
+	if x != nil {
+		return x.StartedAt
+	}
+	return 0
+}
+
+func (x *PutLog) GetPrepared() bool {
+	if x != nil {
+		return x.Prepared
+	}
+	return false
+}
+
+func (x *PutLog) GetEntries() []*PutLogEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type PutLogEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key             *SeqKey  `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Before          *SeqItem `protobuf:"bytes,2,opt,name=before,proto3" json:"before,omitempty"`                                             // 写入前的值，为空表示 key 原本不存在
+	After           *SeqItem `protobuf:"bytes,3,opt,name=after,proto3" json:"after,omitempty"`                                               // 要写入的值
+	BeforeExpiresAt int64    `protobuf:"varint,4,opt,name=before_expires_at,json=beforeExpiresAt,proto3" json:"before_expires_at,omitempty"` // before 的过期时间，Unix 毫秒，0 表示不过期
+}
+
+func (x *PutLogEntry) Reset() {
+	*x = PutLogEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PutLogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutLogEntry) ProtoMessage() {}
+
+func (x *PutLogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutLogEntry.ProtoReflect.Descriptor instead.
+func (*PutLogEntry) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PutLogEntry) GetKey() *SeqKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *PutLogEntry) GetBefore() *SeqItem {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
+
+func (x *PutLogEntry) GetAfter() *SeqItem {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
+
+func (x *PutLogEntry) GetBeforeExpiresAt() int64 {
+	if x != nil {
+		return x.BeforeExpiresAt
+	}
+	return 0
+}
+
+type DelRangeResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Deleted     int64   `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`                  // 删除的行数，dry_run 时为将被删除的行数
+	FirstKey    *SeqKey `protobuf:"bytes,2,opt,name=first_key,json=firstKey,proto3" json:"first_key,omitempty"` // 第一个删除的 key，按 RangeReq 的结果顺序，rowkey 无法解码时为空
+	LastKey     *SeqKey `protobuf:"bytes,3,opt,name=last_key,json=lastKey,proto3" json:"last_key,omitempty"`    // 最后一个删除的 key
+	FirstRowKey []byte  `protobuf:"bytes,4,opt,name=first_row_key,json=firstRowKey,proto3" json:"first_row_key,omitempty"`
+	LastRowKey  []byte  `protobuf:"bytes,5,opt,name=last_row_key,json=lastRowKey,proto3" json:"last_row_key,omitempty"`
+	Truncated   bool    `protobuf:"varint,6,opt,name=truncated,proto3" json:"truncated,omitempty"` // 达到 max_rows 时区间内仍有未删除的行
+}
+
+func (x *DelRangeResp) Reset() {
+	*x = DelRangeResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DelRangeResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DelRangeResp) ProtoMessage() {}
+
+func (x *DelRangeResp) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DelRangeResp.ProtoReflect.Descriptor instead.
+func (*DelRangeResp) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DelRangeResp) GetDeleted() int64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+func (x *DelRangeResp) GetFirstKey() *SeqKey {
+	if x != nil {
+		return x.FirstKey
+	}
+	return nil
+}
+
+func (x *DelRangeResp) GetLastKey() *SeqKey {
+	if x != nil {
+		return x.LastKey
+	}
+	return nil
+}
+
+func (x *DelRangeResp) GetFirstRowKey() []byte {
+	if x != nil {
+		return x.FirstRowKey
+	}
+	return nil
+}
+
+func (x *DelRangeResp) GetLastRowKey() []byte {
+	if x != nil {
+		return x.LastRowKey
+	}
+	return nil
+}
+
+func (x *DelRangeResp) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type GetResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key   *SeqKey  `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Found bool     `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"` // false 表示该 key 不存在
+	Item  *SeqItem `protobuf:"bytes,3,opt,name=item,proto3" json:"item,omitempty"`    // found 为 true 时有效
+}
+
+func (x *GetResult) Reset() {
+	*x = GetResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResult) ProtoMessage() {}
+
+func (x *GetResult) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResult.ProtoReflect.Descriptor instead.
+func (*GetResult) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetResult) GetKey() *SeqKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *GetResult) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *GetResult) GetItem() *SeqItem {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+type BatchGetResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results []*GetResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // 与请求中的 keys 一一对应
+}
+
+func (x *BatchGetResp) Reset() {
+	*x = BatchGetResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchGetResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetResp) ProtoMessage() {}
+
+func (x *BatchGetResp) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetResp.ProtoReflect.Descriptor instead.
+func (*BatchGetResp) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *BatchGetResp) GetResults() []*GetResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type RangeReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Start     *SeqKey     `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	End       *SeqKey     `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	Reverse   bool        `protobuf:"varint,3,opt,name=reverse,proto3" json:"reverse,omitempty"`                        // 默认 [start -> end], true 时 [end -> start] 受 limit 约束
+	Option    RangeOption `protobuf:"varint,4,opt,name=option,proto3,enum=cloudpb.RangeOption" json:"option,omitempty"` // 默认闭区间，可选择去除左右区间
+	Limit     int32       `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`                            // 最多返回的条数，0 表示不限制
+	PageToken []byte      `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`    // 上一页返回的 next_page_token，为空表示从区间起点开始
+	DryRun    bool        `protobuf:"varint,7,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`            // 仅 DeleteRange 使用，为 true 时只统计将被删除的行，不实际删除
+	MaxRows   int64       `protobuf:"varint,8,opt,name=max_rows,json=maxRows,proto3" json:"max_rows,omitempty"`         // 仅 DeleteRange 使用，最多删除的行数，0 表示不限制
+	// 以下字段仅 QueryRange 和 StreamRange 使用，含义与 GetReq 相同，没有满足条件的版本的 key 不返回
+	AsOfTimestamp int64      `protobuf:"varint,9,opt,name=as_of_timestamp,json=asOfTimestamp,proto3" json:"as_of_timestamp,omitempty"`
+	TimeRange     *TimeRange `protobuf:"bytes,10,opt,name=time_range,json=timeRange,proto3" json:"time_range,omitempty"`
+	MaxVersions   int32      `protobuf:"varint,11,opt,name=max_versions,json=maxVersions,proto3" json:"max_versions,omitempty"`
+	// 以下字段仅 QueryRange 和 StreamRange 使用，二者只能设置一个，设置后查询多个 BizId：
+	// start 和 end 只提供 seq 区间（biz_id 须为空，为空的 start 表示 seq 0，为空的 end 表示最大的 seq），
+	// 对每个 BizId 查询该区间，结果按 (BizId, seq) 升序排列，reverse 为 true 时按降序排列
+	BizIds    [][]byte `protobuf:"bytes,12,rep,name=biz_ids,json=bizIds,proto3" json:"biz_ids,omitempty"`          // 查询的 BizId，重复的只查询一次
+	BizPrefix []byte   `protobuf:"bytes,13,opt,name=biz_prefix,json=bizPrefix,proto3" json:"biz_prefix,omitempty"` // 查询所有以 biz_prefix 开头的 BizId
+}
+
+func (x *RangeReq) Reset() {
+	*x = RangeReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RangeReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RangeReq) ProtoMessage() {}
+
+func (x *RangeReq) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RangeReq.ProtoReflect.Descriptor instead.
+func (*RangeReq) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RangeReq) GetStart() *SeqKey {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *RangeReq) GetEnd() *SeqKey {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+func (x *RangeReq) GetReverse() bool {
+	if x != nil {
+		return x.Reverse
+	}
+	return false
+}
+
+func (x *RangeReq) GetOption() RangeOption {
+	if x != nil {
+		return x.Option
+	}
+	return RangeOption_WithBoth
+}
+
+func (x *RangeReq) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *RangeReq) GetPageToken() []byte {
+	if x != nil {
+		return x.PageToken
+	}
+	return nil
+}
+
+func (x *RangeReq) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *RangeReq) GetMaxRows() int64 {
+	if x != nil {
+		return x.MaxRows
+	}
+	return 0
+}
+
+func (x *RangeReq) GetAsOfTimestamp() int64 {
+	if x != nil {
+		return x.AsOfTimestamp
+	}
+	return 0
+}
+
+func (x *RangeReq) GetTimeRange() *TimeRange {
+	if x != nil {
+		return x.TimeRange
+	}
+	return nil
+}
+
+func (x *RangeReq) GetMaxVersions() int32 {
+	if x != nil {
+		return x.MaxVersions
+	}
+	return 0
+}
+
+func (x *RangeReq) GetBizIds() [][]byte {
+	if x != nil {
+		return x.BizIds
+	}
+	return nil
+}
+
+func (x *RangeReq) GetBizPrefix() []byte {
+	if x != nil {
+		return x.BizPrefix
+	}
+	return nil
+}
+
+type AllocateSeqReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BizId []byte `protobuf:"bytes,1,opt,name=biz_id,json=bizId,proto3" json:"biz_id,omitempty"`
+	Count int32  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"` // 需要分配的 seq 个数，必须大于 0
+}
+
+func (x *AllocateSeqReq) Reset() {
+	*x = AllocateSeqReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AllocateSeqReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocateSeqReq) ProtoMessage() {}
+
+func (x *AllocateSeqReq) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocateSeqReq.ProtoReflect.Descriptor instead.
+func (*AllocateSeqReq) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AllocateSeqReq) GetBizId() []byte {
+	if x != nil {
+		return x.BizId
+	}
+	return nil
+}
+
+func (x *AllocateSeqReq) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type AllocateSeqResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BizId []byte `protobuf:"bytes,1,opt,name=biz_id,json=bizId,proto3" json:"biz_id,omitempty"`
+	First int32  `protobuf:"varint,2,opt,name=first,proto3" json:"first,omitempty"` // 分配到的 seq 为闭区间 [first, last]
+	Last  int32  `protobuf:"varint,3,opt,name=last,proto3" json:"last,omitempty"`
+}
+
+func (x *AllocateSeqResp) Reset() {
+	*x = AllocateSeqResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AllocateSeqResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocateSeqResp) ProtoMessage() {}
+
+func (x *AllocateSeqResp) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocateSeqResp.ProtoReflect.Descriptor instead.
+func (*AllocateSeqResp) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AllocateSeqResp) GetBizId() []byte {
+	if x != nil {
+		return x.BizId
+	}
+	return nil
+}
+
+func (x *AllocateSeqResp) GetFirst() int32 {
+	if x != nil {
+		return x.First
+	}
+	return 0
+}
+
+func (x *AllocateSeqResp) GetLast() int32 {
+	if x != nil {
+		return x.Last
+	}
+	return 0
+}
+
+type KeyCountResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BizId []byte `protobuf:"bytes,1,opt,name=biz_id,json=bizId,proto3" json:"biz_id,omitempty"`
+	Count int64  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (x *KeyCountResp) Reset() {
+	*x = KeyCountResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KeyCountResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyCountResp) ProtoMessage() {}
+
+func (x *KeyCountResp) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyCountResp.ProtoReflect.Descriptor instead.
+func (*KeyCountResp) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *KeyCountResp) GetBizId() []byte {
+	if x != nil {
+		return x.BizId
+	}
+	return nil
+}
+
+func (x *KeyCountResp) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type CasItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Item          *SeqItem `protobuf:"bytes,1,opt,name=item,proto3" json:"item,omitempty"`                                        // 要写入的新值
+	ExpectedValue []byte   `protobuf:"bytes,2,opt,name=expected_value,json=expectedValue,proto3" json:"expected_value,omitempty"` // 仅当当前值等于 expected_value 时写入
+}
+
+func (x *CasItem) Reset() {
+	*x = CasItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CasItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CasItem) ProtoMessage() {}
+
+func (x *CasItem) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CasItem.ProtoReflect.Descriptor instead.
+func (*CasItem) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CasItem) GetItem() *SeqItem {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+func (x *CasItem) GetExpectedValue() []byte {
+	if x != nil {
+		return x.ExpectedValue
+	}
+	return nil
+}
+
+type CasReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items      []*CasItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	TtlSeconds int64      `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"` // 同 SeqItems.ttl_seconds
+}
+
+func (x *CasReq) Reset() {
+	*x = CasReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CasReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CasReq) ProtoMessage() {}
+
+func (x *CasReq) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CasReq.ProtoReflect.Descriptor instead.
+func (*CasReq) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CasReq) GetItems() []*CasItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *CasReq) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type ConditionalResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key     *SeqKey  `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Applied bool     `protobuf:"varint,2,opt,name=applied,proto3" json:"applied,omitempty"` // true 表示已写入
+	Current *SeqItem `protobuf:"bytes,3,opt,name=current,proto3" json:"current,omitempty"`  // 条件不满足时的当前值，key 不存在时为空
+	Error   string   `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`      // 非空表示写入出错，此时 applied 为 false
+}
+
+func (x *ConditionalResult) Reset() {
+	*x = ConditionalResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_seqdb_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConditionalResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConditionalResult) ProtoMessage() {}
+
+func (x *ConditionalResult) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConditionalResult.ProtoReflect.Descriptor instead.
+func (*ConditionalResult) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ConditionalResult) GetKey() *SeqKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *ConditionalResult) GetApplied() bool {
+	if x != nil {
+		return x.Applied
+	}
+	return false
+}
+
+func (x *ConditionalResult) GetCurrent() *SeqItem {
+	if x != nil {
+		return x.Current
+	}
+	return nil
 }
 
-// Deprecated: Use PutItemResp.ProtoReflect.Descriptor instead.
-func (*PutItemResp) Descriptor() ([]byte, []int) {
-	return file_seqdb_proto_rawDescGZIP(), []int{3}
+func (x *ConditionalResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
 }
 
-type DelRangeResp struct {
+type ConditionalResp struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Results []*ConditionalResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // 与请求中的 items 一一对应
 }
 
-func (x *DelRangeResp) Reset() {
-	*x = DelRangeResp{}
+func (x *ConditionalResp) Reset() {
+	*x = ConditionalResp{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_seqdb_proto_msgTypes[4]
+		mi := &file_seqdb_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *DelRangeResp) String() string {
+func (x *ConditionalResp) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DelRangeResp) ProtoMessage() {}
+func (*ConditionalResp) ProtoMessage() {}
 
-func (x *DelRangeResp) ProtoReflect() protoreflect.Message {
-	mi := &file_seqdb_proto_msgTypes[4]
+func (x *ConditionalResp) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -300,39 +1623,48 @@ func (x *DelRangeResp) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DelRangeResp.ProtoReflect.Descriptor instead.
-func (*DelRangeResp) Descriptor() ([]byte, []int) {
-	return file_seqdb_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ConditionalResp.ProtoReflect.Descriptor instead.
+func (*ConditionalResp) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{23}
 }
 
-type RangeReq struct {
+func (x *ConditionalResp) GetResults() []*ConditionalResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// Watch 先按 seq 升序回放 biz_id 中 seq 不小于 from_seq 的 SeqItem，再按写入顺序推送之后写入的 SeqItem
+// 推送的是本服务实例上写入成功的 SeqItem（Put、BatchPut、PutIfAbsent、CompareAndSwap），seq 小于 from_seq 的不推送；
+// 消费过慢时服务端以 Aborted 结束 Watch，错误附带的 SeqKey 为续传的位置，
+// 重连时以收到的最大 seq + 1 作为 from_seq 即可从断开处继续
+type WatchReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Start   *SeqKey     `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
-	End     *SeqKey     `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
-	Reverse bool        `protobuf:"varint,3,opt,name=reverse,proto3" json:"reverse,omitempty"`                        // 默认 [start -> end], true 时 [end -> start] 受 limit 约束
-	Option  RangeOption `protobuf:"varint,4,opt,name=option,proto3,enum=cloudpb.RangeOption" json:"option,omitempty"` // 默认闭区间，可选择去除左右区间
+	BizId   []byte `protobuf:"bytes,1,opt,name=biz_id,json=bizId,proto3" json:"biz_id,omitempty"`
+	FromSeq int32  `protobuf:"varint,2,opt,name=from_seq,json=fromSeq,proto3" json:"from_seq,omitempty"`
 }
 
-func (x *RangeReq) Reset() {
-	*x = RangeReq{}
+func (x *WatchReq) Reset() {
+	*x = WatchReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_seqdb_proto_msgTypes[5]
+		mi := &file_seqdb_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *RangeReq) String() string {
+func (x *WatchReq) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RangeReq) ProtoMessage() {}
+func (*WatchReq) ProtoMessage() {}
 
-func (x *RangeReq) ProtoReflect() protoreflect.Message {
-	mi := &file_seqdb_proto_msgTypes[5]
+func (x *WatchReq) ProtoReflect() protoreflect.Message {
+	mi := &file_seqdb_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -343,37 +1675,23 @@ func (x *RangeReq) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RangeReq.ProtoReflect.Descriptor instead.
-func (*RangeReq) Descriptor() ([]byte, []int) {
-	return file_seqdb_proto_rawDescGZIP(), []int{5}
-}
-
-func (x *RangeReq) GetStart() *SeqKey {
-	if x != nil {
-		return x.Start
-	}
-	return nil
+// Deprecated: Use WatchReq.ProtoReflect.Descriptor instead.
+func (*WatchReq) Descriptor() ([]byte, []int) {
+	return file_seqdb_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *RangeReq) GetEnd() *SeqKey {
+func (x *WatchReq) GetBizId() []byte {
 	if x != nil {
-		return x.End
+		return x.BizId
 	}
 	return nil
 }
 
-func (x *RangeReq) GetReverse() bool {
-	if x != nil {
-		return x.Reverse
-	}
-	return false
-}
-
-func (x *RangeReq) GetOption() RangeOption {
+func (x *WatchReq) GetFromSeq() int32 {
 	if x != nil {
-		return x.Option
+		return x.FromSeq
 	}
-	return RangeOption_WithBoth
+	return 0
 }
 
 var File_seqdb_proto protoreflect.FileDescriptor
@@ -383,17 +1701,119 @@ var file_seqdb_proto_rawDesc = []byte{
 	0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x22, 0x31, 0x0a, 0x06, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79,
 	0x12, 0x15, 0x0a, 0x06, 0x62, 0x69, 0x7a, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
 	0x52, 0x05, 0x62, 0x69, 0x7a, 0x49, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x73, 0x65, 0x71, 0x22, 0x42, 0x0a, 0x07, 0x53, 0x65, 0x71,
-	0x49, 0x74, 0x65, 0x6d, 0x12, 0x21, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b,
-	0x65, 0x79, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x32, 0x0a,
-	0x08, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x26, 0x0a, 0x05, 0x69, 0x74, 0x65,
-	0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64,
-	0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d,
-	0x73, 0x22, 0x0d, 0x0a, 0x0b, 0x50, 0x75, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x73, 0x70,
-	0x22, 0x0e, 0x0a, 0x0c, 0x44, 0x65, 0x6c, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70,
-	0x22, 0x9c, 0x01, 0x0a, 0x08, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x71, 0x12, 0x25, 0x0a,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x73, 0x65, 0x71, 0x22, 0x95, 0x01, 0x0a, 0x07, 0x53, 0x65,
+	0x71, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x21, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71,
+	0x4b, 0x65, 0x79, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1c,
+	0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x33, 0x0a, 0x08,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17,
+	0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x22, 0x44, 0x0a, 0x0e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x2f, 0x0a, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x52,
+	0x61, 0x6e, 0x67, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x03, 0x6d, 0x69, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x61, 0x78, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x03, 0x6d, 0x61, 0x78, 0x22, 0xaf, 0x01, 0x0a, 0x06, 0x47, 0x65, 0x74,
+	0x52, 0x65, 0x71, 0x12, 0x15, 0x0a, 0x06, 0x62, 0x69, 0x7a, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x05, 0x62, 0x69, 0x7a, 0x49, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65,
+	0x71, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x73, 0x65, 0x71, 0x12, 0x26, 0x0a, 0x0f,
+	0x61, 0x73, 0x5f, 0x6f, 0x66, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x61, 0x73, 0x4f, 0x66, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x12, 0x31, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x72, 0x61, 0x6e,
+	0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64,
+	0x70, 0x62, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x61, 0x78, 0x5f, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6d,
+	0x61, 0x78, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x93, 0x01, 0x0a, 0x08, 0x53,
+	0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x26, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62,
+	0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x12,
+	0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b,
+	0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61,
+	0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x74, 0x6f, 0x6d, 0x69,
+	0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x61, 0x74, 0x6f, 0x6d, 0x69, 0x63, 0x12,
+	0x1f, 0x0a, 0x0b, 0x74, 0x74, 0x6c, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74, 0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73,
+	0x22, 0x40, 0x0a, 0x0c, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x4c, 0x69, 0x73, 0x74,
+	0x12, 0x30, 0x0a, 0x0a, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53,
+	0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x52, 0x09, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x4c, 0x69,
+	0x73, 0x74, 0x22, 0x2e, 0x0a, 0x07, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x73, 0x12, 0x23, 0x0a,
+	0x04, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6c,
+	0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x52, 0x04, 0x6b, 0x65,
+	0x79, 0x73, 0x22, 0x45, 0x0a, 0x0a, 0x50, 0x75, 0x74, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65,
+	0x12, 0x21, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e,
+	0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x8f, 0x01, 0x0a, 0x09, 0x50, 0x75,
+	0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x21, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53,
+	0x65, 0x71, 0x4b, 0x65, 0x79, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6f,
+	0x77, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x72, 0x6f, 0x77,
+	0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x99, 0x01, 0x0a, 0x0b,
+	0x50, 0x75, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x12, 0x2d, 0x0a, 0x09, 0x63,
+	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f,
+	0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x52,
+	0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x12, 0x2b, 0x0a, 0x06, 0x66, 0x61,
+	0x69, 0x6c, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x6c, 0x6f,
+	0x75, 0x64, 0x70, 0x62, 0x2e, 0x50, 0x75, 0x74, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x52,
+	0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x2e, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x6c, 0x6f, 0x75,
+	0x64, 0x70, 0x62, 0x2e, 0x50, 0x75, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x08, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x22, 0x73, 0x0a, 0x06, 0x50, 0x75, 0x74, 0x4c, 0x6f,
+	0x67, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x12, 0x2e, 0x0a, 0x07,
+	0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e,
+	0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x50, 0x75, 0x74, 0x4c, 0x6f, 0x67, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0xae, 0x01, 0x0a,
+	0x0b, 0x50, 0x75, 0x74, 0x4c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x21, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75,
+	0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x28, 0x0a, 0x06, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65,
+	0x6d, 0x52, 0x06, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x26, 0x0a, 0x05, 0x61, 0x66, 0x74,
+	0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64,
+	0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x61, 0x66, 0x74, 0x65,
+	0x72, 0x12, 0x2a, 0x0a, 0x11, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x5f, 0x65, 0x78, 0x70, 0x69,
+	0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x62, 0x65,
+	0x66, 0x6f, 0x72, 0x65, 0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x22, 0xe6, 0x01,
+	0x0a, 0x0c, 0x44, 0x65, 0x6c, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x12, 0x18,
+	0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x2c, 0x0a, 0x09, 0x66, 0x69, 0x72, 0x73,
+	0x74, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6c,
+	0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x52, 0x08, 0x66, 0x69,
+	0x72, 0x73, 0x74, 0x4b, 0x65, 0x79, 0x12, 0x2a, 0x0a, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x6b,
+	0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64,
+	0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x52, 0x07, 0x6c, 0x61, 0x73, 0x74, 0x4b,
+	0x65, 0x79, 0x12, 0x22, 0x0a, 0x0d, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x72, 0x6f, 0x77, 0x5f,
+	0x6b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x66, 0x69, 0x72, 0x73, 0x74,
+	0x52, 0x6f, 0x77, 0x4b, 0x65, 0x79, 0x12, 0x20, 0x0a, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x72,
+	0x6f, 0x77, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x6c, 0x61,
+	0x73, 0x74, 0x52, 0x6f, 0x77, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x72, 0x75, 0x6e,
+	0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x74, 0x72, 0x75,
+	0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x22, 0x6a, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x12, 0x21, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65,
+	0x79, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x24, 0x0a, 0x04,
+	0x69, 0x74, 0x65, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x63, 0x6c, 0x6f,
+	0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x04, 0x69, 0x74,
+	0x65, 0x6d, 0x22, 0x3c, 0x0a, 0x0c, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x12, 0x2c, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x47, 0x65,
+	0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73,
+	0x22, 0xbb, 0x03, 0x0a, 0x08, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x71, 0x12, 0x25, 0x0a,
 	0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63,
 	0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x52, 0x05, 0x73,
 	0x74, 0x61, 0x72, 0x74, 0x12, 0x21, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
@@ -402,30 +1822,120 @@ var file_seqdb_proto_rawDesc = []byte{
 	0x73, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x65, 0x76, 0x65, 0x72, 0x73,
 	0x65, 0x12, 0x2c, 0x0a, 0x06, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
 	0x0e, 0x32, 0x14, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x52, 0x61, 0x6e, 0x67,
-	0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x2a,
-	0x4e, 0x0a, 0x0b, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0c,
-	0x0a, 0x08, 0x57, 0x69, 0x74, 0x68, 0x42, 0x6f, 0x74, 0x68, 0x10, 0x00, 0x12, 0x10, 0x0a, 0x0c,
-	0x57, 0x69, 0x74, 0x68, 0x6f, 0x75, 0x74, 0x53, 0x74, 0x61, 0x72, 0x74, 0x10, 0x01, 0x12, 0x0e,
-	0x0a, 0x0a, 0x57, 0x69, 0x74, 0x68, 0x6f, 0x75, 0x74, 0x45, 0x6e, 0x64, 0x10, 0x02, 0x12, 0x0f,
-	0x0a, 0x0b, 0x57, 0x69, 0x74, 0x68, 0x6f, 0x75, 0x74, 0x42, 0x6f, 0x74, 0x68, 0x10, 0x03, 0x32,
-	0xfd, 0x01, 0x0a, 0x05, 0x53, 0x65, 0x71, 0x44, 0x62, 0x12, 0x2e, 0x0a, 0x03, 0x50, 0x75, 0x74,
-	0x12, 0x11, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x49, 0x74,
-	0x65, 0x6d, 0x73, 0x1a, 0x14, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x50, 0x75,
-	0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x12, 0x28, 0x0a, 0x03, 0x47, 0x65, 0x74,
-	0x12, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65,
-	0x79, 0x1a, 0x10, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x49,
-	0x74, 0x65, 0x6d, 0x12, 0x2d, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x4d, 0x61, 0x78, 0x4b, 0x65, 0x79,
-	0x12, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65,
-	0x79, 0x1a, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b,
-	0x65, 0x79, 0x12, 0x32, 0x0a, 0x0a, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x61, 0x6e, 0x67, 0x65,
-	0x12, 0x11, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x52, 0x61, 0x6e, 0x67, 0x65,
-	0x52, 0x65, 0x71, 0x1a, 0x11, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65,
-	0x71, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x37, 0x0a, 0x0b, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
-	0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x11, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e,
-	0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x15, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64,
-	0x70, 0x62, 0x2e, 0x44, 0x65, 0x6c, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x42,
-	0x0a, 0x5a, 0x08, 0x2f, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f,
+	0x6b, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x12, 0x19, 0x0a,
+	0x08, 0x6d, 0x61, 0x78, 0x5f, 0x72, 0x6f, 0x77, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x07, 0x6d, 0x61, 0x78, 0x52, 0x6f, 0x77, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x61, 0x73, 0x5f, 0x6f,
+	0x66, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0d, 0x61, 0x73, 0x4f, 0x66, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x12, 0x31, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x52, 0x61,
+	0x6e, 0x67, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x61, 0x78, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x62, 0x69, 0x7a, 0x5f, 0x69, 0x64,
+	0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x62, 0x69, 0x7a, 0x49, 0x64, 0x73, 0x12,
+	0x1d, 0x0a, 0x0a, 0x62, 0x69, 0x7a, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x0d, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x09, 0x62, 0x69, 0x7a, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x22, 0x3d,
+	0x0a, 0x0e, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x53, 0x65, 0x71, 0x52, 0x65, 0x71,
+	0x12, 0x15, 0x0a, 0x06, 0x62, 0x69, 0x7a, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x05, 0x62, 0x69, 0x7a, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x52, 0x0a,
+	0x0f, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x53, 0x65, 0x71, 0x52, 0x65, 0x73, 0x70,
+	0x12, 0x15, 0x0a, 0x06, 0x62, 0x69, 0x7a, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x05, 0x62, 0x69, 0x7a, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x72, 0x73, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x66, 0x69, 0x72, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x6c, 0x61, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6c, 0x61, 0x73,
+	0x74, 0x22, 0x3b, 0x0a, 0x0c, 0x4b, 0x65, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x12, 0x15, 0x0a, 0x06, 0x62, 0x69, 0x7a, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x05, 0x62, 0x69, 0x7a, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x56,
+	0x0a, 0x07, 0x43, 0x61, 0x73, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x24, 0x0a, 0x04, 0x69, 0x74, 0x65,
+	0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70,
+	0x62, 0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x04, 0x69, 0x74, 0x65, 0x6d, 0x12,
+	0x25, 0x0a, 0x0e, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x51, 0x0a, 0x06, 0x43, 0x61, 0x73, 0x52, 0x65, 0x71,
+	0x12, 0x26, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x43, 0x61, 0x73, 0x49, 0x74, 0x65,
+	0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x74, 0x6c, 0x5f,
+	0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74,
+	0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x92, 0x01, 0x0a, 0x11, 0x43, 0x6f,
+	0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12,
+	0x21, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63,
+	0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x12, 0x2a, 0x0a, 0x07,
+	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e,
+	0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x52,
+	0x07, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x47,
+	0x0a, 0x0f, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73,
+	0x70, 0x12, 0x34, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6e,
+	0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0x3c, 0x0a, 0x08, 0x57, 0x61, 0x74, 0x63, 0x68,
+	0x52, 0x65, 0x71, 0x12, 0x15, 0x0a, 0x06, 0x62, 0x69, 0x7a, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x05, 0x62, 0x69, 0x7a, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x66, 0x72,
+	0x6f, 0x6d, 0x5f, 0x73, 0x65, 0x71, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x66, 0x72,
+	0x6f, 0x6d, 0x53, 0x65, 0x71, 0x2a, 0x4e, 0x0a, 0x0b, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0c, 0x0a, 0x08, 0x57, 0x69, 0x74, 0x68, 0x42, 0x6f, 0x74, 0x68,
+	0x10, 0x00, 0x12, 0x10, 0x0a, 0x0c, 0x57, 0x69, 0x74, 0x68, 0x6f, 0x75, 0x74, 0x53, 0x74, 0x61,
+	0x72, 0x74, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x57, 0x69, 0x74, 0x68, 0x6f, 0x75, 0x74, 0x45,
+	0x6e, 0x64, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x57, 0x69, 0x74, 0x68, 0x6f, 0x75, 0x74, 0x42,
+	0x6f, 0x74, 0x68, 0x10, 0x03, 0x32, 0xf2, 0x05, 0x0a, 0x05, 0x53, 0x65, 0x71, 0x44, 0x62, 0x12,
+	0x2e, 0x0a, 0x03, 0x50, 0x75, 0x74, 0x12, 0x11, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62,
+	0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x1a, 0x14, 0x2e, 0x63, 0x6c, 0x6f, 0x75,
+	0x64, 0x70, 0x62, 0x2e, 0x50, 0x75, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x12,
+	0x28, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62,
+	0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x10, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70,
+	0x62, 0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x2d, 0x0a, 0x09, 0x47, 0x65, 0x74,
+	0x4d, 0x61, 0x78, 0x4b, 0x65, 0x79, 0x12, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62,
+	0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x1a, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70,
+	0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x12, 0x2d, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x4d,
+	0x69, 0x6e, 0x4b, 0x65, 0x79, 0x12, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e,
+	0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x1a, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62,
+	0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x12, 0x35, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x4b, 0x65,
+	0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62,
+	0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65, 0x79, 0x1a, 0x15, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70,
+	0x62, 0x2e, 0x4b, 0x65, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x12, 0x32,
+	0x0a, 0x0a, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x11, 0x2e, 0x63,
+	0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x71, 0x1a,
+	0x11, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65,
+	0x6d, 0x73, 0x12, 0x37, 0x0a, 0x0b, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x61, 0x6e, 0x67,
+	0x65, 0x12, 0x11, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x52, 0x61, 0x6e, 0x67,
+	0x65, 0x52, 0x65, 0x71, 0x1a, 0x15, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x44,
+	0x65, 0x6c, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x12, 0x37, 0x0a, 0x08, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x50, 0x75, 0x74, 0x12, 0x15, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70,
+	0x62, 0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x1a, 0x14,
+	0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x50, 0x75, 0x74, 0x49, 0x74, 0x65, 0x6d,
+	0x52, 0x65, 0x73, 0x70, 0x12, 0x33, 0x0a, 0x08, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74,
+	0x12, 0x10, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x4b, 0x65,
+	0x79, 0x73, 0x1a, 0x15, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x12, 0x3a, 0x0a, 0x0b, 0x50, 0x75, 0x74,
+	0x49, 0x66, 0x41, 0x62, 0x73, 0x65, 0x6e, 0x74, 0x12, 0x11, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64,
+	0x70, 0x62, 0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x1a, 0x18, 0x2e, 0x63, 0x6c,
+	0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61,
+	0x6c, 0x52, 0x65, 0x73, 0x70, 0x12, 0x3b, 0x0a, 0x0e, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x65,
+	0x41, 0x6e, 0x64, 0x53, 0x77, 0x61, 0x70, 0x12, 0x0f, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70,
+	0x62, 0x2e, 0x43, 0x61, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x18, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64,
+	0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x52, 0x65,
+	0x73, 0x70, 0x12, 0x40, 0x0a, 0x0b, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x53, 0x65,
+	0x71, 0x12, 0x17, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x41, 0x6c, 0x6c, 0x6f,
+	0x63, 0x61, 0x74, 0x65, 0x53, 0x65, 0x71, 0x52, 0x65, 0x71, 0x1a, 0x18, 0x2e, 0x63, 0x6c, 0x6f,
+	0x75, 0x64, 0x70, 0x62, 0x2e, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x53, 0x65, 0x71,
+	0x52, 0x65, 0x73, 0x70, 0x12, 0x34, 0x0a, 0x0b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x61,
+	0x6e, 0x67, 0x65, 0x12, 0x11, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x52, 0x61,
+	0x6e, 0x67, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x10, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62,
+	0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x30, 0x01, 0x12, 0x2e, 0x0a, 0x05, 0x57, 0x61,
+	0x74, 0x63, 0x68, 0x12, 0x11, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x2e, 0x57, 0x61,
+	0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x1a, 0x10, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62,
+	0x2e, 0x53, 0x65, 0x71, 0x49, 0x74, 0x65, 0x6d, 0x30, 0x01, 0x42, 0x0a, 0x5a, 0x08, 0x2f, 0x63,
+	0x6c, 0x6f, 0x75, 0x64, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -441,37 +1951,98 @@ func file_seqdb_proto_rawDescGZIP() []byte {
 }
 
 var file_seqdb_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_seqdb_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_seqdb_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
 var file_seqdb_proto_goTypes = []interface{}{
-	(RangeOption)(0),     // 0: cloudpb.RangeOption
-	(*SeqKey)(nil),       // 1: cloudpb.SeqKey
-	(*SeqItem)(nil),      // 2: cloudpb.SeqItem
-	(*SeqItems)(nil),     // 3: cloudpb.SeqItems
-	(*PutItemResp)(nil),  // 4: cloudpb.PutItemResp
-	(*DelRangeResp)(nil), // 5: cloudpb.DelRangeResp
-	(*RangeReq)(nil),     // 6: cloudpb.RangeReq
+	(RangeOption)(0),          // 0: cloudpb.RangeOption
+	(*SeqKey)(nil),            // 1: cloudpb.SeqKey
+	(*SeqItem)(nil),           // 2: cloudpb.SeqItem
+	(*SeqItemVersion)(nil),    // 3: cloudpb.SeqItemVersion
+	(*TimeRange)(nil),         // 4: cloudpb.TimeRange
+	(*GetReq)(nil),            // 5: cloudpb.GetReq
+	(*SeqItems)(nil),          // 6: cloudpb.SeqItems
+	(*SeqItemsList)(nil),      // 7: cloudpb.SeqItemsList
+	(*SeqKeys)(nil),           // 8: cloudpb.SeqKeys
+	(*PutFailure)(nil),        // 9: cloudpb.PutFailure
+	(*PutStatus)(nil),         // 10: cloudpb.PutStatus
+	(*PutItemResp)(nil),       // 11: cloudpb.PutItemResp
+	(*PutLog)(nil),            // 12: cloudpb.PutLog
+	(*PutLogEntry)(nil),       // 13: cloudpb.PutLogEntry
+	(*DelRangeResp)(nil),      // 14: cloudpb.DelRangeResp
+	(*GetResult)(nil),         // 15: cloudpb.GetResult
+	(*BatchGetResp)(nil),      // 16: cloudpb.BatchGetResp
+	(*RangeReq)(nil),          // 17: cloudpb.RangeReq
+	(*AllocateSeqReq)(nil),    // 18: cloudpb.AllocateSeqReq
+	(*AllocateSeqResp)(nil),   // 19: cloudpb.AllocateSeqResp
+	(*KeyCountResp)(nil),      // 20: cloudpb.KeyCountResp
+	(*CasItem)(nil),           // 21: cloudpb.CasItem
+	(*CasReq)(nil),            // 22: cloudpb.CasReq
+	(*ConditionalResult)(nil), // 23: cloudpb.ConditionalResult
+	(*ConditionalResp)(nil),   // 24: cloudpb.ConditionalResp
+	(*WatchReq)(nil),          // 25: cloudpb.WatchReq
 }
 var file_seqdb_proto_depIdxs = []int32{
 	1,  // 0: cloudpb.SeqItem.key:type_name -> cloudpb.SeqKey
-	2,  // 1: cloudpb.SeqItems.items:type_name -> cloudpb.SeqItem
-	1,  // 2: cloudpb.RangeReq.start:type_name -> cloudpb.SeqKey
-	1,  // 3: cloudpb.RangeReq.end:type_name -> cloudpb.SeqKey
-	0,  // 4: cloudpb.RangeReq.option:type_name -> cloudpb.RangeOption
-	3,  // 5: cloudpb.SeqDb.Put:input_type -> cloudpb.SeqItems
-	1,  // 6: cloudpb.SeqDb.Get:input_type -> cloudpb.SeqKey
-	1,  // 7: cloudpb.SeqDb.GetMaxKey:input_type -> cloudpb.SeqKey
-	6,  // 8: cloudpb.SeqDb.QueryRange:input_type -> cloudpb.RangeReq
-	6,  // 9: cloudpb.SeqDb.DeleteRange:input_type -> cloudpb.RangeReq
-	4,  // 10: cloudpb.SeqDb.Put:output_type -> cloudpb.PutItemResp
-	2,  // 11: cloudpb.SeqDb.Get:output_type -> cloudpb.SeqItem
-	1,  // 12: cloudpb.SeqDb.GetMaxKey:output_type -> cloudpb.SeqKey
-	3,  // 13: cloudpb.SeqDb.QueryRange:output_type -> cloudpb.SeqItems
-	5,  // 14: cloudpb.SeqDb.DeleteRange:output_type -> cloudpb.DelRangeResp
-	10, // [10:15] is the sub-list for method output_type
-	5,  // [5:10] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	3,  // 1: cloudpb.SeqItem.versions:type_name -> cloudpb.SeqItemVersion
+	4,  // 2: cloudpb.GetReq.time_range:type_name -> cloudpb.TimeRange
+	2,  // 3: cloudpb.SeqItems.items:type_name -> cloudpb.SeqItem
+	6,  // 4: cloudpb.SeqItemsList.items_list:type_name -> cloudpb.SeqItems
+	1,  // 5: cloudpb.SeqKeys.keys:type_name -> cloudpb.SeqKey
+	1,  // 6: cloudpb.PutFailure.key:type_name -> cloudpb.SeqKey
+	1,  // 7: cloudpb.PutStatus.key:type_name -> cloudpb.SeqKey
+	1,  // 8: cloudpb.PutItemResp.committed:type_name -> cloudpb.SeqKey
+	9,  // 9: cloudpb.PutItemResp.failed:type_name -> cloudpb.PutFailure
+	10, // 10: cloudpb.PutItemResp.statuses:type_name -> cloudpb.PutStatus
+	13, // 11: cloudpb.PutLog.entries:type_name -> cloudpb.PutLogEntry
+	1,  // 12: cloudpb.PutLogEntry.key:type_name -> cloudpb.SeqKey
+	2,  // 13: cloudpb.PutLogEntry.before:type_name -> cloudpb.SeqItem
+	2,  // 14: cloudpb.PutLogEntry.after:type_name -> cloudpb.SeqItem
+	1,  // 15: cloudpb.DelRangeResp.first_key:type_name -> cloudpb.SeqKey
+	1,  // 16: cloudpb.DelRangeResp.last_key:type_name -> cloudpb.SeqKey
+	1,  // 17: cloudpb.GetResult.key:type_name -> cloudpb.SeqKey
+	2,  // 18: cloudpb.GetResult.item:type_name -> cloudpb.SeqItem
+	15, // 19: cloudpb.BatchGetResp.results:type_name -> cloudpb.GetResult
+	1,  // 20: cloudpb.RangeReq.start:type_name -> cloudpb.SeqKey
+	1,  // 21: cloudpb.RangeReq.end:type_name -> cloudpb.SeqKey
+	0,  // 22: cloudpb.RangeReq.option:type_name -> cloudpb.RangeOption
+	4,  // 23: cloudpb.RangeReq.time_range:type_name -> cloudpb.TimeRange
+	2,  // 24: cloudpb.CasItem.item:type_name -> cloudpb.SeqItem
+	21, // 25: cloudpb.CasReq.items:type_name -> cloudpb.CasItem
+	1,  // 26: cloudpb.ConditionalResult.key:type_name -> cloudpb.SeqKey
+	2,  // 27: cloudpb.ConditionalResult.current:type_name -> cloudpb.SeqItem
+	23, // 28: cloudpb.ConditionalResp.results:type_name -> cloudpb.ConditionalResult
+	6,  // 29: cloudpb.SeqDb.Put:input_type -> cloudpb.SeqItems
+	5,  // 30: cloudpb.SeqDb.Get:input_type -> cloudpb.GetReq
+	1,  // 31: cloudpb.SeqDb.GetMaxKey:input_type -> cloudpb.SeqKey
+	1,  // 32: cloudpb.SeqDb.GetMinKey:input_type -> cloudpb.SeqKey
+	1,  // 33: cloudpb.SeqDb.GetKeyCount:input_type -> cloudpb.SeqKey
+	17, // 34: cloudpb.SeqDb.QueryRange:input_type -> cloudpb.RangeReq
+	17, // 35: cloudpb.SeqDb.DeleteRange:input_type -> cloudpb.RangeReq
+	7,  // 36: cloudpb.SeqDb.BatchPut:input_type -> cloudpb.SeqItemsList
+	8,  // 37: cloudpb.SeqDb.BatchGet:input_type -> cloudpb.SeqKeys
+	6,  // 38: cloudpb.SeqDb.PutIfAbsent:input_type -> cloudpb.SeqItems
+	22, // 39: cloudpb.SeqDb.CompareAndSwap:input_type -> cloudpb.CasReq
+	18, // 40: cloudpb.SeqDb.AllocateSeq:input_type -> cloudpb.AllocateSeqReq
+	17, // 41: cloudpb.SeqDb.StreamRange:input_type -> cloudpb.RangeReq
+	25, // 42: cloudpb.SeqDb.Watch:input_type -> cloudpb.WatchReq
+	11, // 43: cloudpb.SeqDb.Put:output_type -> cloudpb.PutItemResp
+	2,  // 44: cloudpb.SeqDb.Get:output_type -> cloudpb.SeqItem
+	1,  // 45: cloudpb.SeqDb.GetMaxKey:output_type -> cloudpb.SeqKey
+	1,  // 46: cloudpb.SeqDb.GetMinKey:output_type -> cloudpb.SeqKey
+	20, // 47: cloudpb.SeqDb.GetKeyCount:output_type -> cloudpb.KeyCountResp
+	6,  // 48: cloudpb.SeqDb.QueryRange:output_type -> cloudpb.SeqItems
+	14, // 49: cloudpb.SeqDb.DeleteRange:output_type -> cloudpb.DelRangeResp
+	11, // 50: cloudpb.SeqDb.BatchPut:output_type -> cloudpb.PutItemResp
+	16, // 51: cloudpb.SeqDb.BatchGet:output_type -> cloudpb.BatchGetResp
+	24, // 52: cloudpb.SeqDb.PutIfAbsent:output_type -> cloudpb.ConditionalResp
+	24, // 53: cloudpb.SeqDb.CompareAndSwap:output_type -> cloudpb.ConditionalResp
+	19, // 54: cloudpb.SeqDb.AllocateSeq:output_type -> cloudpb.AllocateSeqResp
+	2,  // 55: cloudpb.SeqDb.StreamRange:output_type -> cloudpb.SeqItem
+	2,  // 56: cloudpb.SeqDb.Watch:output_type -> cloudpb.SeqItem
+	43, // [43:57] is the sub-list for method output_type
+	29, // [29:43] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
 }
 
 func init() { file_seqdb_proto_init() }
@@ -505,7 +2076,7 @@ func file_seqdb_proto_init() {
 			}
 		}
 		file_seqdb_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SeqItems); i {
+			switch v := v.(*SeqItemVersion); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -517,7 +2088,7 @@ func file_seqdb_proto_init() {
 			}
 		}
 		file_seqdb_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PutItemResp); i {
+			switch v := v.(*TimeRange); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -529,7 +2100,7 @@ func file_seqdb_proto_init() {
 			}
 		}
 		file_seqdb_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DelRangeResp); i {
+			switch v := v.(*GetReq); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -541,6 +2112,138 @@ func file_seqdb_proto_init() {
 			}
 		}
 		file_seqdb_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SeqItems); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SeqItemsList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SeqKeys); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PutFailure); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PutStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PutItemResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PutLog); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PutLogEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DelRangeResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchGetResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*RangeReq); i {
 			case 0:
 				return &v.state
@@ -552,6 +2255,102 @@ func file_seqdb_proto_init() {
 				return nil
 			}
 		}
+		file_seqdb_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AllocateSeqReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AllocateSeqResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KeyCountResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CasItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CasReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConditionalResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConditionalResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_seqdb_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -559,7 +2358,7 @@ func file_seqdb_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_seqdb_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   6,
+			NumMessages:   25,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
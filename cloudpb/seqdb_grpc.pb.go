@@ -23,10 +23,19 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type SeqDbClient interface {
 	Put(ctx context.Context, in *SeqItems, opts ...grpc.CallOption) (*PutItemResp, error)
-	Get(ctx context.Context, in *SeqKey, opts ...grpc.CallOption) (*SeqItem, error)
+	Get(ctx context.Context, in *GetReq, opts ...grpc.CallOption) (*SeqItem, error)
 	GetMaxKey(ctx context.Context, in *SeqKey, opts ...grpc.CallOption) (*SeqKey, error)
+	GetMinKey(ctx context.Context, in *SeqKey, opts ...grpc.CallOption) (*SeqKey, error)
+	GetKeyCount(ctx context.Context, in *SeqKey, opts ...grpc.CallOption) (*KeyCountResp, error)
 	QueryRange(ctx context.Context, in *RangeReq, opts ...grpc.CallOption) (*SeqItems, error)
 	DeleteRange(ctx context.Context, in *RangeReq, opts ...grpc.CallOption) (*DelRangeResp, error)
+	BatchPut(ctx context.Context, in *SeqItemsList, opts ...grpc.CallOption) (*PutItemResp, error)
+	BatchGet(ctx context.Context, in *SeqKeys, opts ...grpc.CallOption) (*BatchGetResp, error)
+	PutIfAbsent(ctx context.Context, in *SeqItems, opts ...grpc.CallOption) (*ConditionalResp, error)
+	CompareAndSwap(ctx context.Context, in *CasReq, opts ...grpc.CallOption) (*ConditionalResp, error)
+	AllocateSeq(ctx context.Context, in *AllocateSeqReq, opts ...grpc.CallOption) (*AllocateSeqResp, error)
+	StreamRange(ctx context.Context, in *RangeReq, opts ...grpc.CallOption) (SeqDb_StreamRangeClient, error)
+	Watch(ctx context.Context, in *WatchReq, opts ...grpc.CallOption) (SeqDb_WatchClient, error)
 }
 
 type seqDbClient struct {
@@ -46,7 +55,7 @@ func (c *seqDbClient) Put(ctx context.Context, in *SeqItems, opts ...grpc.CallOp
 	return out, nil
 }
 
-func (c *seqDbClient) Get(ctx context.Context, in *SeqKey, opts ...grpc.CallOption) (*SeqItem, error) {
+func (c *seqDbClient) Get(ctx context.Context, in *GetReq, opts ...grpc.CallOption) (*SeqItem, error) {
 	out := new(SeqItem)
 	err := c.cc.Invoke(ctx, "/cloudpb.SeqDb/Get", in, out, opts...)
 	if err != nil {
@@ -64,6 +73,24 @@ func (c *seqDbClient) GetMaxKey(ctx context.Context, in *SeqKey, opts ...grpc.Ca
 	return out, nil
 }
 
+func (c *seqDbClient) GetMinKey(ctx context.Context, in *SeqKey, opts ...grpc.CallOption) (*SeqKey, error) {
+	out := new(SeqKey)
+	err := c.cc.Invoke(ctx, "/cloudpb.SeqDb/GetMinKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *seqDbClient) GetKeyCount(ctx context.Context, in *SeqKey, opts ...grpc.CallOption) (*KeyCountResp, error) {
+	out := new(KeyCountResp)
+	err := c.cc.Invoke(ctx, "/cloudpb.SeqDb/GetKeyCount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *seqDbClient) QueryRange(ctx context.Context, in *RangeReq, opts ...grpc.CallOption) (*SeqItems, error) {
 	out := new(SeqItems)
 	err := c.cc.Invoke(ctx, "/cloudpb.SeqDb/QueryRange", in, out, opts...)
@@ -82,15 +109,133 @@ func (c *seqDbClient) DeleteRange(ctx context.Context, in *RangeReq, opts ...grp
 	return out, nil
 }
 
+func (c *seqDbClient) BatchPut(ctx context.Context, in *SeqItemsList, opts ...grpc.CallOption) (*PutItemResp, error) {
+	out := new(PutItemResp)
+	err := c.cc.Invoke(ctx, "/cloudpb.SeqDb/BatchPut", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *seqDbClient) BatchGet(ctx context.Context, in *SeqKeys, opts ...grpc.CallOption) (*BatchGetResp, error) {
+	out := new(BatchGetResp)
+	err := c.cc.Invoke(ctx, "/cloudpb.SeqDb/BatchGet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *seqDbClient) PutIfAbsent(ctx context.Context, in *SeqItems, opts ...grpc.CallOption) (*ConditionalResp, error) {
+	out := new(ConditionalResp)
+	err := c.cc.Invoke(ctx, "/cloudpb.SeqDb/PutIfAbsent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *seqDbClient) CompareAndSwap(ctx context.Context, in *CasReq, opts ...grpc.CallOption) (*ConditionalResp, error) {
+	out := new(ConditionalResp)
+	err := c.cc.Invoke(ctx, "/cloudpb.SeqDb/CompareAndSwap", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *seqDbClient) AllocateSeq(ctx context.Context, in *AllocateSeqReq, opts ...grpc.CallOption) (*AllocateSeqResp, error) {
+	out := new(AllocateSeqResp)
+	err := c.cc.Invoke(ctx, "/cloudpb.SeqDb/AllocateSeq", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *seqDbClient) StreamRange(ctx context.Context, in *RangeReq, opts ...grpc.CallOption) (SeqDb_StreamRangeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SeqDb_ServiceDesc.Streams[0], "/cloudpb.SeqDb/StreamRange", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &seqDbStreamRangeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SeqDb_StreamRangeClient interface {
+	Recv() (*SeqItem, error)
+	grpc.ClientStream
+}
+
+type seqDbStreamRangeClient struct {
+	grpc.ClientStream
+}
+
+func (x *seqDbStreamRangeClient) Recv() (*SeqItem, error) {
+	m := new(SeqItem)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *seqDbClient) Watch(ctx context.Context, in *WatchReq, opts ...grpc.CallOption) (SeqDb_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SeqDb_ServiceDesc.Streams[1], "/cloudpb.SeqDb/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &seqDbWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SeqDb_WatchClient interface {
+	Recv() (*SeqItem, error)
+	grpc.ClientStream
+}
+
+type seqDbWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *seqDbWatchClient) Recv() (*SeqItem, error) {
+	m := new(SeqItem)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // SeqDbServer is the server API for SeqDb service.
 // All implementations must embed UnimplementedSeqDbServer
 // for forward compatibility
 type SeqDbServer interface {
 	Put(context.Context, *SeqItems) (*PutItemResp, error)
-	Get(context.Context, *SeqKey) (*SeqItem, error)
+	Get(context.Context, *GetReq) (*SeqItem, error)
 	GetMaxKey(context.Context, *SeqKey) (*SeqKey, error)
+	GetMinKey(context.Context, *SeqKey) (*SeqKey, error)
+	GetKeyCount(context.Context, *SeqKey) (*KeyCountResp, error)
 	QueryRange(context.Context, *RangeReq) (*SeqItems, error)
 	DeleteRange(context.Context, *RangeReq) (*DelRangeResp, error)
+	BatchPut(context.Context, *SeqItemsList) (*PutItemResp, error)
+	BatchGet(context.Context, *SeqKeys) (*BatchGetResp, error)
+	PutIfAbsent(context.Context, *SeqItems) (*ConditionalResp, error)
+	CompareAndSwap(context.Context, *CasReq) (*ConditionalResp, error)
+	AllocateSeq(context.Context, *AllocateSeqReq) (*AllocateSeqResp, error)
+	StreamRange(*RangeReq, SeqDb_StreamRangeServer) error
+	Watch(*WatchReq, SeqDb_WatchServer) error
 	mustEmbedUnimplementedSeqDbServer()
 }
 
@@ -101,18 +246,45 @@ type UnimplementedSeqDbServer struct {
 func (UnimplementedSeqDbServer) Put(context.Context, *SeqItems) (*PutItemResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Put not implemented")
 }
-func (UnimplementedSeqDbServer) Get(context.Context, *SeqKey) (*SeqItem, error) {
+func (UnimplementedSeqDbServer) Get(context.Context, *GetReq) (*SeqItem, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
 }
 func (UnimplementedSeqDbServer) GetMaxKey(context.Context, *SeqKey) (*SeqKey, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMaxKey not implemented")
 }
+func (UnimplementedSeqDbServer) GetMinKey(context.Context, *SeqKey) (*SeqKey, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMinKey not implemented")
+}
+func (UnimplementedSeqDbServer) GetKeyCount(context.Context, *SeqKey) (*KeyCountResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKeyCount not implemented")
+}
 func (UnimplementedSeqDbServer) QueryRange(context.Context, *RangeReq) (*SeqItems, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QueryRange not implemented")
 }
 func (UnimplementedSeqDbServer) DeleteRange(context.Context, *RangeReq) (*DelRangeResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteRange not implemented")
 }
+func (UnimplementedSeqDbServer) BatchPut(context.Context, *SeqItemsList) (*PutItemResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchPut not implemented")
+}
+func (UnimplementedSeqDbServer) BatchGet(context.Context, *SeqKeys) (*BatchGetResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGet not implemented")
+}
+func (UnimplementedSeqDbServer) PutIfAbsent(context.Context, *SeqItems) (*ConditionalResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutIfAbsent not implemented")
+}
+func (UnimplementedSeqDbServer) CompareAndSwap(context.Context, *CasReq) (*ConditionalResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompareAndSwap not implemented")
+}
+func (UnimplementedSeqDbServer) AllocateSeq(context.Context, *AllocateSeqReq) (*AllocateSeqResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AllocateSeq not implemented")
+}
+func (UnimplementedSeqDbServer) StreamRange(*RangeReq, SeqDb_StreamRangeServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRange not implemented")
+}
+func (UnimplementedSeqDbServer) Watch(*WatchReq, SeqDb_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
 func (UnimplementedSeqDbServer) mustEmbedUnimplementedSeqDbServer() {}
 
 // UnsafeSeqDbServer may be embedded to opt out of forward compatibility for this service.
@@ -145,7 +317,7 @@ func _SeqDb_Put_Handler(srv interface{}, ctx context.Context, dec func(interface
 }
 
 func _SeqDb_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SeqKey)
+	in := new(GetReq)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -157,7 +329,7 @@ func _SeqDb_Get_Handler(srv interface{}, ctx context.Context, dec func(interface
 		FullMethod: "/cloudpb.SeqDb/Get",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SeqDbServer).Get(ctx, req.(*SeqKey))
+		return srv.(SeqDbServer).Get(ctx, req.(*GetReq))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -180,6 +352,42 @@ func _SeqDb_GetMaxKey_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SeqDb_GetMinKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SeqKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeqDbServer).GetMinKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cloudpb.SeqDb/GetMinKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeqDbServer).GetMinKey(ctx, req.(*SeqKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SeqDb_GetKeyCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SeqKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeqDbServer).GetKeyCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cloudpb.SeqDb/GetKeyCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeqDbServer).GetKeyCount(ctx, req.(*SeqKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SeqDb_QueryRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RangeReq)
 	if err := dec(in); err != nil {
@@ -216,6 +424,138 @@ func _SeqDb_DeleteRange_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SeqDb_BatchPut_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SeqItemsList)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeqDbServer).BatchPut(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cloudpb.SeqDb/BatchPut",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeqDbServer).BatchPut(ctx, req.(*SeqItemsList))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SeqDb_BatchGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SeqKeys)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeqDbServer).BatchGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cloudpb.SeqDb/BatchGet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeqDbServer).BatchGet(ctx, req.(*SeqKeys))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SeqDb_PutIfAbsent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SeqItems)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeqDbServer).PutIfAbsent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cloudpb.SeqDb/PutIfAbsent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeqDbServer).PutIfAbsent(ctx, req.(*SeqItems))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SeqDb_CompareAndSwap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CasReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeqDbServer).CompareAndSwap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cloudpb.SeqDb/CompareAndSwap",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeqDbServer).CompareAndSwap(ctx, req.(*CasReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SeqDb_AllocateSeq_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AllocateSeqReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeqDbServer).AllocateSeq(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cloudpb.SeqDb/AllocateSeq",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeqDbServer).AllocateSeq(ctx, req.(*AllocateSeqReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SeqDb_StreamRange_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RangeReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SeqDbServer).StreamRange(m, &seqDbStreamRangeServer{stream})
+}
+
+type SeqDb_StreamRangeServer interface {
+	Send(*SeqItem) error
+	grpc.ServerStream
+}
+
+type seqDbStreamRangeServer struct {
+	grpc.ServerStream
+}
+
+func (x *seqDbStreamRangeServer) Send(m *SeqItem) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SeqDb_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SeqDbServer).Watch(m, &seqDbWatchServer{stream})
+}
+
+type SeqDb_WatchServer interface {
+	Send(*SeqItem) error
+	grpc.ServerStream
+}
+
+type seqDbWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *seqDbWatchServer) Send(m *SeqItem) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // SeqDb_ServiceDesc is the grpc.ServiceDesc for SeqDb service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -235,6 +575,14 @@ var SeqDb_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMaxKey",
 			Handler:    _SeqDb_GetMaxKey_Handler,
 		},
+		{
+			MethodName: "GetMinKey",
+			Handler:    _SeqDb_GetMinKey_Handler,
+		},
+		{
+			MethodName: "GetKeyCount",
+			Handler:    _SeqDb_GetKeyCount_Handler,
+		},
 		{
 			MethodName: "QueryRange",
 			Handler:    _SeqDb_QueryRange_Handler,
@@ -243,7 +591,38 @@ var SeqDb_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteRange",
 			Handler:    _SeqDb_DeleteRange_Handler,
 		},
+		{
+			MethodName: "BatchPut",
+			Handler:    _SeqDb_BatchPut_Handler,
+		},
+		{
+			MethodName: "BatchGet",
+			Handler:    _SeqDb_BatchGet_Handler,
+		},
+		{
+			MethodName: "PutIfAbsent",
+			Handler:    _SeqDb_PutIfAbsent_Handler,
+		},
+		{
+			MethodName: "CompareAndSwap",
+			Handler:    _SeqDb_CompareAndSwap_Handler,
+		},
+		{
+			MethodName: "AllocateSeq",
+			Handler:    _SeqDb_AllocateSeq_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamRange",
+			Handler:       _SeqDb_StreamRange_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _SeqDb_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "seqdb.proto",
 }
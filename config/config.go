@@ -0,0 +1,294 @@
+// Package config 加载 SeqDb 各个程序共用的配置
+//
+// 配置按以下优先级合并（后者覆盖前者）：内置默认值、YAML 配置文件、SEQDB_* 环境变量、命令行参数
+// 每个配置项的命令行参数名与 YAML 路径一致，例如 hbase.quorum 对应 -hbase.quorum 和 SEQDB_HBASE_QUORUM
+// 配置文件路径由 -config 或 SEQDB_CONFIG 指定
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config 是 SeqDb 的完整配置
+type Config struct {
+	Server ServerConfig `yaml:"server"`
+	Client ClientConfig `yaml:"client"`
+	HBase  HBaseConfig  `yaml:"hbase"`
+	Thrift ThriftConfig `yaml:"thrift"`
+	Table  TableConfig  `yaml:"table"`
+	// Retention 是数据保留策略
+	Retention RetentionConfig `yaml:"retention"`
+	Tenant    TenantConfig    `yaml:"tenant"`
+	// Schema 是 seqdb-admin migrate 管理的表结构
+	Schema SchemaConfig `yaml:"schema"`
+}
+
+// ServerConfig 是 gRPC 服务端配置
+type ServerConfig struct {
+	Listen      string    `yaml:"listen"`       // 监听地址
+	Backend     string    `yaml:"backend"`      // 存储后端：hbase、thrift 或 memory
+	DebugListen string    `yaml:"debug_listen"` // 在该地址的 /debug/vars 提供 expvar 指标，为空表示不启用
+	TLS         TLSConfig `yaml:"tls"`
+}
+
+// ClientConfig 是 gRPC 客户端配置
+type ClientConfig struct {
+	Target    string    `yaml:"target"`    // SeqDb 服务地址
+	Namespace string    `yaml:"namespace"` // 租户 namespace，通过 gRPC metadata 发送，为空表示不指定
+	TLS       TLSConfig `yaml:"tls"`
+}
+
+// TLSConfig 描述 gRPC 连接的 TLS 设置
+// 服务端在 CertFile 和 KeyFile 都设置时启用 TLS；客户端在 Enabled 为 true 时启用 TLS
+type TLSConfig struct {
+	Enabled            bool   `yaml:"enabled"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	CAFile             string `yaml:"ca_file"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// HBaseConfig 是 gohbase 原生客户端配置
+type HBaseConfig struct {
+	Quorum        string `yaml:"quorum"`         // ZooKeeper quorum，或 Lindorm 的 HBase 兼容地址
+	ZkRoot        string `yaml:"zk_root"`        // 为空时使用 gohbase 默认值 /hbase
+	EffectiveUser string `yaml:"effective_user"` // 为空时使用 gohbase 默认值 root
+}
+
+// ThriftConfig 是 HBase Thrift2 客户端配置
+type ThriftConfig struct {
+	Host     string `yaml:"host"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+}
+
+// TableConfig 描述 SeqItem 所在的表
+type TableConfig struct {
+	Name        string `yaml:"name"`
+	Family      string `yaml:"family"`
+	Qualifier   string `yaml:"qualifier"`
+	Counter     string `yaml:"counter"`      // AllocateSeq 计数器的列名
+	WAL         string `yaml:"wal"`          // 原子 Put 预写日志的列名
+	RowKey      string `yaml:"row_key"`      // rowkey 编码：legacy、binary 或 salted
+	SaltBuckets int    `yaml:"salt_buckets"` // salted 编码的桶数
+	SaltHash    string `yaml:"salt_hash"`    // salted 编码的哈希：murmur3 或 xxhash
+	Compat      bool   `yaml:"compat"`       // 兼容模式，读取时接受 legacy rowkey 和非 SeqItem 的 value
+}
+
+// TenantConfig 描述多租户路由
+// 请求指定了 namespace 时访问 namespace:Table 表，否则访问 TableConfig.Name
+type TenantConfig struct {
+	Table         string `yaml:"table"`          // 租户表名，不含 namespace
+	AutoProvision bool   `yaml:"auto_provision"` // 首次访问租户时自动创建 namespace 和表，默认关闭，租户表须事先通过 seqdb-admin migrate -namespace 创建
+}
+
+// SchemaConfig 描述 TableConfig.Family 列族的期望属性，Compression、BloomFilter 为空或 MaxVersions 为 0 时不管理对应属性
+type SchemaConfig struct {
+	Compression string        `yaml:"compression"`  // 压缩算法：NONE、GZ、SNAPPY、LZ4、LZO、BZIP2 或 ZSTD
+	BloomFilter string        `yaml:"bloom_filter"` // 布隆过滤器：NONE、ROW、ROWCOL 或 ROWPREFIX_FIXED_LENGTH
+	TTL         time.Duration `yaml:"ttl"`          // 列族 TTL，0 表示不过期
+	MaxVersions int           `yaml:"max_versions"` // 每个 cell 保留的版本数
+	PreSplit    bool          `yaml:"pre_split"`    // 创建表时按 rowkey 编码预分区
+	Regions     int           `yaml:"regions"`      // 预分区的 region 数，salted 编码固定为每个桶一个 region
+}
+
+// RetentionConfig 描述数据保留策略
+// TTL 和 MaxSeqs 是默认策略，Biz 为指定 BizId 的策略，指定后完全替代默认策略；Biz 只能在配置文件中设置
+type RetentionConfig struct {
+	TTL          time.Duration              `yaml:"ttl"`           // 写入的数据在 TTL 后过期，0 表示不过期
+	MaxSeqs      int                        `yaml:"max_seqs"`      // 每个 BizId 只保留最新的 MaxSeqs 个 seq，0 表示不限制
+	ReapInterval time.Duration              `yaml:"reap_interval"` // 清理超出 MaxSeqs 的数据的间隔，0 表示不清理
+	Biz          map[string]RetentionPolicy `yaml:"biz"`
+}
+
+// RetentionPolicy 是单个 BizId 的保留策略，字段含义与 RetentionConfig 相同
+type RetentionPolicy struct {
+	TTL     time.Duration `yaml:"ttl"`
+	MaxSeqs int           `yaml:"max_seqs"`
+}
+
+// Default 返回内置默认配置，集群地址和凭据不设默认值，必须通过配置文件、环境变量或命令行参数提供，
+// 示例见 seqdb.example.yaml
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Listen:  ":30060",
+			Backend: "hbase",
+		},
+		Client: ClientConfig{
+			Target: "localhost:30060",
+		},
+		Table: TableConfig{
+			Name:        "my_table",
+			Family:      "cf",
+			Qualifier:   "value",
+			Counter:     "seq",
+			WAL:         "wal",
+			RowKey:      "legacy",
+			SaltBuckets: 16,
+			SaltHash:    "murmur3",
+		},
+		Retention: RetentionConfig{
+			ReapInterval: time.Hour,
+		},
+		Tenant: TenantConfig{
+			Table: "seqdb",
+		},
+		Schema: SchemaConfig{
+			BloomFilter: "ROW",
+			PreSplit:    true,
+			Regions:     16,
+		},
+	}
+}
+
+// Load 在 fs 上注册 -config 及所有配置项参数，解析 args 并按优先级合并出最终配置
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	path := fs.String("config", os.Getenv("SEQDB_CONFIG"), "YAML 配置文件路径")
+	cfg := Default()
+	fields := cfg.fields()
+	flags := make([]*flagValue, len(fields))
+	for i, f := range fields {
+		// 参数只记录原始值，待配置文件和环境变量合并后再写入，保证命令行优先级最高
+		flags[i] = &flagValue{isBool: f.b != nil}
+		fs.Var(flags[i], f.name, f.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *path != "" {
+		data, err := os.ReadFile(*path)
+		if err != nil {
+			return nil, fmt.Errorf("read config file: %v", err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("parse config file %s: %v", *path, err)
+		}
+	}
+	for i, f := range fields {
+		if value, ok := os.LookupEnv(f.env()); ok {
+			if err := f.set(value); err != nil {
+				return nil, fmt.Errorf("%s: %v", f.env(), err)
+			}
+		}
+		if flags[i].set {
+			if err := f.set(flags[i].value); err != nil {
+				return nil, fmt.Errorf("-%s: %v", f.name, err)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// flagValue 记录命令行中显式设置的参数值
+type flagValue struct {
+	value  string
+	set    bool
+	isBool bool
+}
+
+func (v *flagValue) String() string { return v.value }
+
+func (v *flagValue) Set(value string) error {
+	v.value, v.set = value, true
+	return nil
+}
+
+// IsBoolFlag 使布尔配置项支持 -name 的简写形式
+func (v *flagValue) IsBoolFlag() bool { return v.isBool }
+
+// field 是一个可通过环境变量和命令行参数覆盖的配置项，str、b、n、d 只设置其一
+type field struct {
+	name  string
+	usage string
+	str   *string
+	b     *bool
+	n     *int
+	d     *time.Duration
+}
+
+// env 返回配置项对应的环境变量名
+func (f field) env() string {
+	return "SEQDB_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(f.name))
+}
+
+func (f field) set(value string) error {
+	if f.b != nil {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*f.b = v
+		return nil
+	}
+	if f.n != nil {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*f.n = v
+		return nil
+	}
+	if f.d != nil {
+		v, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*f.d = v
+		return nil
+	}
+	*f.str = value
+	return nil
+}
+
+// fields 列出所有配置项，名称与 YAML 路径一致
+func (c *Config) fields() []field {
+	return []field{
+		{name: "server.listen", usage: "gRPC 服务监听地址", str: &c.Server.Listen},
+		{name: "server.backend", usage: "存储后端：hbase、thrift 或 memory", str: &c.Server.Backend},
+		{name: "server.debug_listen", usage: "expvar 指标（/debug/vars）的监听地址，为空表示不启用", str: &c.Server.DebugListen},
+		{name: "server.tls.cert_file", usage: "服务端 TLS 证书文件", str: &c.Server.TLS.CertFile},
+		{name: "server.tls.key_file", usage: "服务端 TLS 私钥文件", str: &c.Server.TLS.KeyFile},
+		{name: "client.target", usage: "SeqDb 服务地址", str: &c.Client.Target},
+		{name: "client.namespace", usage: "租户 namespace，为空表示不指定", str: &c.Client.Namespace},
+		{name: "client.tls.enabled", usage: "客户端是否使用 TLS", b: &c.Client.TLS.Enabled},
+		{name: "client.tls.ca_file", usage: "客户端校验服务端证书的 CA 文件", str: &c.Client.TLS.CAFile},
+		{name: "client.tls.server_name", usage: "客户端校验的服务端证书名称", str: &c.Client.TLS.ServerName},
+		{name: "client.tls.insecure_skip_verify", usage: "客户端跳过服务端证书校验", b: &c.Client.TLS.InsecureSkipVerify},
+		{name: "hbase.quorum", usage: "HBase ZooKeeper quorum 或 Lindorm HBase 兼容地址", str: &c.HBase.Quorum},
+		{name: "hbase.zk_root", usage: "HBase 在 ZooKeeper 中的根节点", str: &c.HBase.ZkRoot},
+		{name: "hbase.effective_user", usage: "访问 HBase 使用的用户", str: &c.HBase.EffectiveUser},
+		{name: "thrift.host", usage: "HBase Thrift2 服务地址", str: &c.Thrift.Host},
+		{name: "thrift.user", usage: "Thrift 服务用户名", str: &c.Thrift.User},
+		{name: "thrift.password", usage: "Thrift 服务密码", str: &c.Thrift.Password},
+		{name: "table.name", usage: "SeqItem 所在的表", str: &c.Table.Name},
+		{name: "table.family", usage: "SeqItem 所在的列族", str: &c.Table.Family},
+		{name: "table.qualifier", usage: "存放 SeqItem 的列名", str: &c.Table.Qualifier},
+		{name: "table.counter", usage: "AllocateSeq 计数器的列名", str: &c.Table.Counter},
+		{name: "table.wal", usage: "原子 Put 预写日志的列名", str: &c.Table.WAL},
+		{name: "table.row_key", usage: "rowkey 编码：legacy、binary 或 salted", str: &c.Table.RowKey},
+		{name: "table.salt_buckets", usage: "salted 编码的桶数", n: &c.Table.SaltBuckets},
+		{name: "table.salt_hash", usage: "salted 编码的哈希：murmur3 或 xxhash", str: &c.Table.SaltHash},
+		{name: "table.compat", usage: "兼容模式：读取时接受 legacy rowkey 和非 SeqItem 的 value", b: &c.Table.Compat},
+		{name: "retention.ttl", usage: "默认的数据 TTL，如 720h，0 表示不过期", d: &c.Retention.TTL},
+		{name: "retention.max_seqs", usage: "默认每个 BizId 保留的最新 seq 数，0 表示不限制", n: &c.Retention.MaxSeqs},
+		{name: "retention.reap_interval", usage: "清理超出 max_seqs 的数据的间隔，0 表示不清理", d: &c.Retention.ReapInterval},
+		{name: "tenant.table", usage: "租户表名，租户 ns 的数据在 ns:表名", str: &c.Tenant.Table},
+		{name: "tenant.auto_provision", usage: "首次访问租户时自动创建 namespace 和表", b: &c.Tenant.AutoProvision},
+		{name: "schema.compression", usage: "列族压缩算法，为空表示不管理", str: &c.Schema.Compression},
+		{name: "schema.bloom_filter", usage: "列族布隆过滤器，为空表示不管理", str: &c.Schema.BloomFilter},
+		{name: "schema.ttl", usage: "列族 TTL，0 表示不过期", d: &c.Schema.TTL},
+		{name: "schema.max_versions", usage: "列族保留的版本数，0 表示不管理", n: &c.Schema.MaxVersions},
+		{name: "schema.pre_split", usage: "创建表时按 rowkey 编码预分区", b: &c.Schema.PreSplit},
+		{name: "schema.regions", usage: "预分区的 region 数，salted 编码固定为每个桶一个 region", n: &c.Schema.Regions},
+	}
+}
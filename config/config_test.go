@@ -0,0 +1,151 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "seqdb.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Default(t *testing.T) {
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("Load() = %+v, want %+v", cfg, Default())
+	}
+	// 集群地址必须显式配置，默认不跳过证书校验
+	if cfg.HBase.Quorum != "" || cfg.Thrift.Host != "" || cfg.Client.TLS.InsecureSkipVerify {
+		t.Errorf("Default() has quorum %q, thrift host %q, insecure_skip_verify %v", cfg.HBase.Quorum, cfg.Thrift.Host, cfg.Client.TLS.InsecureSkipVerify)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeConfig(t, `
+server:
+  listen: ":1000"
+  backend: thrift
+thrift:
+  user: file-user
+  password: file-password
+table:
+  name: file_table
+client:
+  tls:
+    enabled: false
+`)
+	t.Setenv("SEQDB_THRIFT_PASSWORD", "env-password")
+	t.Setenv("SEQDB_TABLE_NAME", "env_table")
+	t.Setenv("SEQDB_TABLE_SALT_BUCKETS", "32")
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError),
+		[]string{"-config", path, "-table.name", "flag_table", "-client.tls.enabled"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"file overrides default", cfg.Server.Listen, ":1000"},
+		{"file overrides default", cfg.Thrift.User, "file-user"},
+		{"env overrides file", cfg.Thrift.Password, "env-password"},
+		{"flag overrides env", cfg.Table.Name, "flag_table"},
+		{"bool flag without value", cfg.Client.TLS.Enabled, true},
+		{"default kept when unset", cfg.Table.Family, "cf"},
+		{"int from env", cfg.Table.SaltBuckets, 32},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoad_ConfigFromEnv(t *testing.T) {
+	t.Setenv("SEQDB_CONFIG", writeConfig(t, "hbase:\n  quorum: zk1,zk2\n"))
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.HBase.Quorum != "zk1,zk2" {
+		t.Errorf("HBase.Quorum = %q, want %q", cfg.HBase.Quorum, "zk1,zk2")
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+	}{
+		{name: "missing file", args: []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}},
+		{name: "invalid yaml", args: []string{"-config", writeConfig(t, "server: [")}},
+		{name: "invalid bool env", env: map[string]string{"SEQDB_CLIENT_TLS_ENABLED": "maybe"}},
+		{name: "invalid int env", env: map[string]string{"SEQDB_TABLE_SALT_BUCKETS": "many"}},
+		{name: "invalid bool flag", args: []string{"-client.tls.insecure_skip_verify=maybe"}},
+		{name: "unknown flag", args: []string{"-no-such-flag"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			if _, err := Load(fs, tt.args); err == nil {
+				t.Errorf("Load(%v) error = nil, want error", tt.args)
+			}
+		})
+	}
+}
+
+func TestLoad_Retention(t *testing.T) {
+	path := writeConfig(t, `
+retention:
+  ttl: 720h
+  max_seqs: 1000
+  biz:
+    audit:
+      ttl: 8760h
+    hot:
+      max_seqs: 10
+`)
+	t.Setenv("SEQDB_RETENTION_REAP_INTERVAL", "10m")
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", path, "-retention.max_seqs", "500"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := RetentionConfig{
+		TTL:          720 * time.Hour,
+		MaxSeqs:      500,
+		ReapInterval: 10 * time.Minute,
+		Biz: map[string]RetentionPolicy{
+			"audit": {TTL: 8760 * time.Hour},
+			"hot":   {MaxSeqs: 10},
+		},
+	}
+	if !reflect.DeepEqual(cfg.Retention, want) {
+		t.Errorf("Load() retention = %+v, want %+v", cfg.Retention, want)
+	}
+
+	t.Setenv("SEQDB_RETENTION_TTL", "30")
+	if _, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil); err == nil {
+		t.Error("Load() with duration missing unit error = nil")
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/store"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// decodeRow 将扫描到的行还原为写入时的 SeqItem
+//
+// 正常情况下 value 是序列化的 SeqItem，且其 Key 与 rowkey 解码出的 SeqKey 一致，否则返回错误
+// 兼容模式（s.compat）下还接受其他格式写入的行：
+//   - rowkey 不是当前编码时按 rowkey.Legacy 解码，仍无法解码则以 value 中的 SeqKey 为准
+//   - value 不是 SeqItem 时（例如 demo.go 直接写入的字符串），以 rowkey 解码出的 SeqKey 和原始 value 组成 SeqItem
+func (s *server) decodeRow(row *store.Row) (*pb.SeqItem, error) {
+	key, keyErr := s.decodeKey(row.Key)
+
+	item := &pb.SeqItem{}
+	isItem := proto.Unmarshal(row.Value, item) == nil && item.Key != nil
+	switch {
+	case isItem && keyErr == nil && proto.Equal(item.Key, key):
+		return item, nil
+	case !s.compat && keyErr != nil:
+		return nil, keyErr
+	case !s.compat && isItem:
+		return nil, fmt.Errorf("row %q: stored key %v does not match row key %v", row.Key, item.Key, key)
+	case !s.compat:
+		return nil, fmt.Errorf("row %q: value is not a SeqItem", row.Key)
+	case keyErr == nil:
+		// value 不是 SeqItem，或与 rowkey 不一致时以 rowkey 为准
+		return &pb.SeqItem{Key: key, Value: row.Value}, nil
+	case isItem:
+		return item, nil
+	}
+	return nil, fmt.Errorf("row %q: cannot decode row key or value: %v", row.Key, keyErr)
+}
+
+// decodeKey 将 rowkey 解码为 SeqKey，兼容模式下当前编码无法解码时按 rowkey.Legacy 解码
+func (s *server) decodeKey(rowKey []byte) (*pb.SeqKey, error) {
+	key, err := s.codec.Decode(rowKey)
+	if err != nil && s.compat {
+		if _, isLegacy := s.codec.(rowkey.Legacy); !isLegacy {
+			key, err = rowkey.Legacy{}.Decode(rowKey)
+		}
+	}
+	return key, err
+}
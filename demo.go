@@ -1,12 +1,18 @@
+//go:build ignore
+
+// Thrift 示例程序，与 main.go 中的 gRPC 服务相互独立，单独通过 go run demo.go 运行
 package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 
-	"./go/demo/gen-go/hbase" // Thrift 生成的 HBase 客户端代码
+	"demo/gen-go/hbase" // Thrift 生成的 HBase 客户端代码
+	"go-hbase-demo/config"
+
 	"github.com/apache/thrift/lib/go/thrift"
 )
 
@@ -26,28 +32,22 @@ func generateRowKey(fileID string, revision int32) string {
 	return rowKey
 }
 
-// 创建 Thrift 客户端并连接到 HBase
-func createThriftClient() (*hbase.THBaseServiceClient, error) {
-	const (
-		HOST     = "ld-7xv325q01b2720rk9-proxy-lindorm-pub.lindorm.rds.aliyuncs.com:9190"
-		USER     = "root"
-		PASSWORD = "GkWWCUPb"
-	)
-
+// 创建 Thrift 客户端并连接到 HBase，地址和凭据来自配置
+func createThriftClient(cfg config.ThriftConfig) (*hbase.THBaseServiceClient, error) {
 	protocolFactory := thrift.NewTBinaryProtocolFactoryDefault()
-	trans, err := thrift.NewTHttpClient(HOST)
+	trans, err := thrift.NewTHttpClient(cfg.Host)
 	if err != nil {
 		return nil, fmt.Errorf("error resolving address: %v", err)
 	}
 
 	// 设置用户名和密码
 	httpClient := trans.(*thrift.THttpClient)
-	httpClient.SetHeader("ACCESSKEYID", USER)
-	httpClient.SetHeader("ACCESSSIGNATURE", PASSWORD)
+	httpClient.SetHeader("ACCESSKEYID", cfg.User)
+	httpClient.SetHeader("ACCESSSIGNATURE", cfg.Password)
 
 	client := hbase.NewTHBaseServiceClientFactory(trans, protocolFactory)
 	if err := trans.Open(); err != nil {
-		return nil, fmt.Errorf("Error opening %s: %v", HOST, err)
+		return nil, fmt.Errorf("Error opening %s: %v", cfg.Host, err)
 	}
 	return client, nil
 }
@@ -124,17 +124,22 @@ func scanItems(client *hbase.THBaseServiceClient, ctx context.Context, tableName
 }
 
 func main() {
-	client, err := createThriftClient()
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	client, err := createThriftClient(cfg.Thrift)
 	if err != nil {
 		log.Fatalf("failed to create Thrift client: %v", err)
 	}
 
 	ctx := context.Background()
 
-	tableName := "my_table"
+	tableName := cfg.Table.Name
 	rowKey := generateRowKey("example_file_id", 1)
-	family := "cf"
-	qualifier := "value"
+	family := cfg.Table.Family
+	qualifier := cfg.Table.Qualifier
 	value := "example_value"
 
 	// 插入数据
@@ -163,4 +168,3 @@ func main() {
 		log.Fatalf("failed to delete item: %v", err)
 	}
 }
-
@@ -0,0 +1,54 @@
+// Package dial 按配置连接 SeqDb 服务或其存储后端，供 SeqDb 服务、示例客户端和 seqdb-import、seqdb-export 等工具共用
+package dial
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"go-hbase-demo/config"
+	"go-hbase-demo/tenant"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Credentials 根据 TLS 配置生成连接凭据
+func Credentials(cfg config.TLSConfig) (grpc.DialOption, error) {
+	if !cfg.Enabled {
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		f, err := os.ReadFile(cfg.CAFile) // 签发服务端证书的 CA
+		if err != nil {
+			return nil, err
+		}
+		p := x509.NewCertPool()
+		if !p.AppendCertsFromPEM(f) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = p
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), nil
+}
+
+// Dial 连接 cfg.Target，cfg.Namespace 非空时每个请求都带上租户 namespace
+func Dial(cfg config.ClientConfig) (*grpc.ClientConn, error) {
+	if cfg.Target == "" {
+		return nil, fmt.Errorf("client.target is not set")
+	}
+	creds, err := Credentials(cfg.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS config: %v", err)
+	}
+	return grpc.Dial(cfg.Target, creds,
+		grpc.WithChainUnaryInterceptor(tenant.UnaryClientInterceptor(cfg.Namespace)),
+		grpc.WithChainStreamInterceptor(tenant.StreamClientInterceptor(cfg.Namespace)),
+	)
+}
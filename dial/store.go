@@ -0,0 +1,81 @@
+package dial
+
+import (
+	"fmt"
+
+	"go-hbase-demo/config"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/store"
+	"go-hbase-demo/tenant"
+
+	"github.com/tsuna/gohbase"
+)
+
+// Backend 是按 server.backend 连接的存储后端
+type Backend struct {
+	Store       store.SeqStore                    // table.name 表
+	Open        func(table string) store.SeqStore // 打开同一后端上的其他表，与 Store 共用连接，见 tenant.Store
+	Provisioner tenant.Provisioner                // 为租户建表，未开启 tenant.auto_provision 或不需要建表时为 nil
+}
+
+// StoreOptions 根据表配置和 rowkey 编码生成存储布局
+func StoreOptions(table config.TableConfig, codec rowkey.Codec) store.Options {
+	return store.Options{
+		Table:     table.Name,
+		Family:    table.Family,
+		Qualifier: table.Qualifier,
+		RowKey:    codec.Encode,
+		Counter:   table.Counter,
+		WAL:       table.WAL,
+	}
+}
+
+// OpenStore 按 cfg.Server.Backend 连接 cfg.Table 描述的表，供 SeqDb 服务和 seqdb-export 等直接读写存储的工具共用
+// backend 可选 hbase（gohbase 原生 RPC）、thrift（HBase Thrift2 接口）和 memory（内存存储）
+func OpenStore(cfg *config.Config, codec rowkey.Codec) (*Backend, error) {
+	opts := StoreOptions(cfg.Table, codec)
+	b := &Backend{}
+	switch cfg.Server.Backend {
+	case "hbase":
+		if cfg.HBase.Quorum == "" {
+			return nil, fmt.Errorf("hbase.quorum is not set")
+		}
+		var options []gohbase.Option
+		if cfg.HBase.ZkRoot != "" {
+			options = append(options, gohbase.ZookeeperRoot(cfg.HBase.ZkRoot))
+		}
+		if cfg.HBase.EffectiveUser != "" {
+			options = append(options, gohbase.EffectiveUser(cfg.HBase.EffectiveUser))
+		}
+		hbaseStore := store.NewHBaseStore(gohbase.NewClient(cfg.HBase.Quorum, options...), opts)
+		b.Store = hbaseStore
+		b.Open = func(table string) store.SeqStore { return hbaseStore.WithTable(table) }
+		if cfg.Tenant.AutoProvision {
+			b.Provisioner = store.NewHBaseProvisioner(gohbase.NewAdminClient(cfg.HBase.Quorum, options...), cfg.Table.Family)
+		}
+	case "thrift":
+		if cfg.Thrift.Host == "" {
+			return nil, fmt.Errorf("thrift.host is not set")
+		}
+		thriftStore, err := store.DialThriftStore(cfg.Thrift.Host, cfg.Thrift.User, cfg.Thrift.Password, opts)
+		if err != nil {
+			return nil, err
+		}
+		b.Store = thriftStore
+		b.Open = func(table string) store.SeqStore { return thriftStore.WithTable(table) }
+		if cfg.Tenant.AutoProvision {
+			b.Provisioner = thriftStore
+		}
+	case "memory":
+		b.Store = store.NewMemoryStore(opts)
+		// 每个租户一个独立的内存存储，不需要建表
+		b.Open = func(table string) store.SeqStore {
+			opts := opts
+			opts.Table = table
+			return store.NewMemoryStore(opts)
+		}
+	default:
+		return nil, fmt.Errorf("unknown backend: %s", cfg.Server.Backend)
+	}
+	return b, nil
+}
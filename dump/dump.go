@@ -0,0 +1,210 @@
+// Package dump 实现 SeqItem 的导出文件格式，供 seqdb-export 和 seqdb-import 使用
+//
+// 导出文件是一串 SeqItem，格式为以下两种之一，可以再用 gzip 或 zstd 压缩：
+//   - pb：长度前缀（uvarint）分隔的 protobuf，见 google.golang.org/protobuf/encoding/protodelim
+//   - jsonl：每行一个 protojson 编码的 SeqItem
+//
+// 一次导出写入一个目录，数据按 rowkey 顺序分成若干个 part 文件，每写完一个文件记录一次检查点，
+// 中断后可以从最后一个完整的文件之后继续；文件名的扩展名标明格式和压缩方式，如 part-00000.pb.zst
+package dump
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	pb "go-hbase-demo/cloudpb"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Format 是导出文件中 SeqItem 的编码
+type Format string
+
+const (
+	Protobuf Format = "pb"    // 长度前缀分隔的 protobuf
+	JSON     Format = "jsonl" // JSON lines
+)
+
+// Compression 是导出文件的压缩方式
+type Compression string
+
+const (
+	None Compression = "none"
+	Gzip Compression = "gzip"
+	Zstd Compression = "zstd"
+)
+
+// ParseFormat 解析格式名
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case Protobuf, JSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("dump: unknown format %q, want pb or jsonl", s)
+}
+
+// ParseCompression 解析压缩方式，空字符串表示不压缩
+func ParseCompression(s string) (Compression, error) {
+	switch c := Compression(s); c {
+	case "":
+		return None, nil
+	case None, Gzip, Zstd:
+		return c, nil
+	}
+	return "", fmt.Errorf("dump: unknown compression %q, want none, gzip or zstd", s)
+}
+
+// extensions 是压缩方式对应的文件扩展名
+var extensions = map[Compression]string{None: "", Gzip: ".gz", Zstd: ".zst"}
+
+// FileName 返回第 index 个 part 文件的文件名
+func FileName(index int, format Format, compression Compression) string {
+	return fmt.Sprintf("part-%05d.%s%s", index, format, extensions[compression])
+}
+
+// ParseFileName 根据文件名的扩展名判断格式和压缩方式，如 items.jsonl.gz
+func ParseFileName(name string) (Format, Compression, error) {
+	compression := None
+	for c, ext := range extensions {
+		if ext != "" && strings.HasSuffix(name, ext) {
+			compression, name = c, strings.TrimSuffix(name, ext)
+		}
+	}
+	for _, format := range []Format{Protobuf, JSON} {
+		if strings.HasSuffix(name, "."+string(format)) {
+			return format, compression, nil
+		}
+	}
+	return "", "", fmt.Errorf("dump: cannot tell the format of %s, want a .pb or .jsonl file, optionally with .gz or .zst", name)
+}
+
+// Writer 将 SeqItem 编码写入底层的 io.Writer
+type Writer struct {
+	w          *bufio.Writer
+	compressor io.WriteCloser // 不压缩时为 nil
+	format     Format
+}
+
+// NewWriter 创建写入 w 的 Writer，Close 不会关闭 w
+func NewWriter(w io.Writer, format Format, compression Compression) (*Writer, error) {
+	writer := &Writer{format: format}
+	switch compression {
+	case None:
+	case Gzip:
+		writer.compressor = gzip.NewWriter(w)
+	case Zstd:
+		encoder, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		writer.compressor = encoder
+	default:
+		return nil, fmt.Errorf("dump: unknown compression %q", compression)
+	}
+	if writer.compressor != nil {
+		w = writer.compressor
+	}
+	writer.w = bufio.NewWriter(w)
+	return writer, nil
+}
+
+// Write 写入一个 SeqItem
+func (w *Writer) Write(item *pb.SeqItem) error {
+	switch w.format {
+	case Protobuf:
+		_, err := protodelim.MarshalTo(w.w, item)
+		return err
+	case JSON:
+		line, err := protojson.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if _, err := w.w.Write(line); err != nil {
+			return err
+		}
+		return w.w.WriteByte('\n')
+	}
+	return fmt.Errorf("dump: unknown format %q", w.format)
+}
+
+// Close 写出缓冲的数据并结束压缩流
+func (w *Writer) Close() error {
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	if w.compressor != nil {
+		return w.compressor.Close()
+	}
+	return nil
+}
+
+// Reader 从底层的 io.Reader 中逐个读取 SeqItem
+type Reader struct {
+	r      *bufio.Reader
+	close  func() // 释放解压缩器，不压缩时为 nil
+	format Format
+}
+
+// NewReader 创建读取 r 的 Reader，Close 不会关闭 r
+func NewReader(r io.Reader, format Format, compression Compression) (*Reader, error) {
+	reader := &Reader{format: format}
+	switch compression {
+	case None:
+	case Gzip:
+		decompressor, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		r, reader.close = decompressor, func() { decompressor.Close() }
+	case Zstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		r, reader.close = decoder, decoder.Close
+	default:
+		return nil, fmt.Errorf("dump: unknown compression %q", compression)
+	}
+	reader.r = bufio.NewReader(r)
+	return reader, nil
+}
+
+// Read 返回下一个 SeqItem，读完时返回 io.EOF，文件在记录中间结束时返回 io.ErrUnexpectedEOF
+func (r *Reader) Read() (*pb.SeqItem, error) {
+	item := &pb.SeqItem{}
+	switch r.format {
+	case Protobuf:
+		// SeqItem 的大小不受 gRPC 消息大小的限制
+		if err := (protodelim.UnmarshalOptions{MaxSize: -1}).UnmarshalFrom(r.r, item); err != nil {
+			return nil, err
+		}
+		return item, nil
+	case JSON:
+		line, err := r.r.ReadBytes('\n')
+		if err == io.EOF && len(line) > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := protojson.Unmarshal(bytes.TrimSpace(line), item); err != nil {
+			return nil, err
+		}
+		return item, nil
+	}
+	return nil, fmt.Errorf("dump: unknown format %q", r.format)
+}
+
+// Close 释放解压缩器
+func (r *Reader) Close() error {
+	if r.close != nil {
+		r.close()
+	}
+	return nil
+}
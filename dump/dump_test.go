@@ -0,0 +1,270 @@
+package dump
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/store"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func testItems(n int) []*pb.SeqItem {
+	items := make([]*pb.SeqItem, n)
+	for i := range items {
+		items[i] = &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte("biz"), Seq: int32(i)}, Value: []byte(fmt.Sprintf("value\n%d", i))}
+	}
+	return items
+}
+
+func checkItems(t *testing.T, got, want []*pb.SeqItem) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d items, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if !proto.Equal(got[i], want[i]) {
+			t.Fatalf("item %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWriterReader(t *testing.T) {
+	items := testItems(10)
+	for _, format := range []Format{Protobuf, JSON} {
+		for _, compression := range []Compression{None, Gzip, Zstd} {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, format, compression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, item := range items {
+				if err := w.Write(item); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := NewReader(&buf, format, compression)
+			if err != nil {
+				t.Fatalf("%s/%s: %v", format, compression, err)
+			}
+			var got []*pb.SeqItem
+			for {
+				item, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%s/%s: Read() error = %v", format, compression, err)
+				}
+				got = append(got, item)
+			}
+			r.Close()
+			checkItems(t, got, items)
+		}
+	}
+}
+
+func TestParseFileName(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      Format
+		compression Compression
+		wantErr     bool
+	}{
+		{FileName(3, Protobuf, Zstd), Protobuf, Zstd, false},
+		{"dir/items.jsonl.gz", JSON, Gzip, false},
+		{"items.pb", Protobuf, None, false},
+		{"items.json", "", "", true},
+		{"items.gz", "", "", true},
+	}
+	for _, tt := range tests {
+		format, compression, err := ParseFileName(tt.name)
+		if (err != nil) != tt.wantErr || format != tt.format || compression != tt.compression {
+			t.Errorf("ParseFileName(%q) = %q, %q, %v", tt.name, format, compression, err)
+		}
+	}
+}
+
+// failingStore 的扫描在返回 failAfter 行后出错，模拟导出中断
+type failingStore struct {
+	store.SeqStore
+	failAfter int
+}
+
+func (s *failingStore) Scan(ctx context.Context, rng store.Range) (store.Scanner, error) {
+	scanner, err := s.SeqStore.Scan(ctx, rng)
+	return &failingScanner{Scanner: scanner, remaining: s.failAfter}, err
+}
+
+type failingScanner struct {
+	store.Scanner
+	remaining int
+}
+
+func (s *failingScanner) Next() (*store.Row, error) {
+	if s.remaining == 0 {
+		return nil, errors.New("region server went away")
+	}
+	s.remaining--
+	return s.Scanner.Next()
+}
+
+// readDir 按 part 文件顺序读取导出目录中的所有 SeqItem
+func readDir(t *testing.T, dir string) []*pb.SeqItem {
+	t.Helper()
+	files, err := ListFiles([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []*pb.SeqItem
+	_, err = Import(context.Background(), func(ctx context.Context, batch []*pb.SeqItem) error {
+		items = append(items, batch...)
+		return nil
+	}, files, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return items
+}
+
+func TestExport_Resume(t *testing.T) {
+	codec := rowkey.Binary{}
+	st := store.NewMemoryStore(store.Options{RowKey: codec.Encode})
+	items := testItems(10)
+	if _, err := st.Put(context.Background(), items, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Increment(context.Background(), codec.Prefix([]byte("biz")), 1); err != nil {
+		t.Fatal(err)
+	}
+	scanner, err := st.Scan(context.Background(), store.Range{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []*pb.SeqItem // 按 rowkey 顺序
+	for {
+		row, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		item, _ := RowDecoder(codec)(row)
+		if item != nil {
+			want = append(want, item)
+		}
+	}
+
+	dir := t.TempDir()
+	opts := ExportOptions{Dir: dir, Format: JSON, Compression: Gzip, PartItems: 3, Decode: RowDecoder(codec)}
+	// 第 8 行出错时已完整写入 2 个文件，第 3 个文件写了一半
+	stats, err := Export(context.Background(), &failingStore{SeqStore: st, failAfter: 7}, store.Range{}, opts)
+	if err == nil || stats.Items != 6 || stats.Parts != 2 {
+		t.Fatalf("Export() = %+v, %v, want an error after 2 parts", stats, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName(2, JSON, Gzip))); err != nil {
+		t.Fatalf("partial part missing: %v", err)
+	}
+
+	other := opts
+	other.Format = Protobuf
+	if _, err := Export(context.Background(), st, store.Range{}, other); err == nil {
+		t.Error("Export() with a different format into the same directory error = nil")
+	}
+
+	stats, err = Export(context.Background(), st, store.Range{}, opts)
+	if err != nil || stats.Items != 10 || stats.Parts != 4 {
+		t.Fatalf("Export() resumed = %+v, %v, want 10 items in 4 parts", stats, err)
+	}
+	checkItems(t, readDir(t, dir), want)
+
+	// 已完成的导出不再扫描
+	if stats, err := Export(context.Background(), &failingStore{SeqStore: st}, store.Range{}, opts); err != nil || stats.Items != 10 {
+		t.Errorf("Export() after done = %+v, %v", stats, err)
+	}
+}
+
+func TestExport_Salted(t *testing.T) {
+	codec, err := rowkey.NewSalted(4, rowkey.XXHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := store.NewMemoryStore(store.Options{RowKey: codec.Encode})
+	var want []*pb.SeqItem
+	for _, biz := range []string{"a", "bb", "c", "dd", "e", "ff"} {
+		for seq := int32(0); seq < 3; seq++ {
+			want = append(want, &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte(biz), Seq: seq}, Value: []byte(biz)})
+		}
+	}
+	if _, err := st.Put(context.Background(), want, 0); err != nil {
+		t.Fatal(err)
+	}
+	// 整张表按去掉盐值后的 rowkey 导出，即 Binary 编码的顺序
+	sort.Slice(want, func(i, j int) bool {
+		return bytes.Compare(rowkey.Binary{}.Encode(want[i].Key), rowkey.Binary{}.Encode(want[j].Key)) < 0
+	})
+
+	dir := t.TempDir()
+	opts := ExportOptions{Dir: dir, Format: Protobuf, Compression: None, PartItems: 2, Decode: RowDecoder(codec), Codec: codec}
+	// 每个桶扫描 2 行后出错
+	if stats, err := Export(context.Background(), &failingStore{SeqStore: st, failAfter: 2}, store.Range{}, opts); err == nil || stats.Parts == 0 {
+		t.Fatalf("Export() = %+v, %v, want an error after some parts", stats, err)
+	}
+	if stats, err := Export(context.Background(), st, store.Range{}, opts); err != nil || stats.Items != int64(len(want)) {
+		t.Fatalf("Export() resumed = %+v, %v", stats, err)
+	}
+	checkItems(t, readDir(t, dir), want)
+}
+
+func TestImport_Resume(t *testing.T) {
+	dir := t.TempDir()
+	items := testItems(10)
+	file := filepath.Join(dir, "items.pb.zst")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, _ := NewWriter(f, Protobuf, Zstd)
+	for _, item := range items {
+		w.Write(item)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	var (
+		imported []*pb.SeqItem
+		fail     = true
+	)
+	put := func(ctx context.Context, batch []*pb.SeqItem) error {
+		if fail && len(imported) == 4 {
+			return errors.New("unavailable")
+		}
+		imported = append(imported, batch...)
+		return nil
+	}
+	opts := ImportOptions{Checkpoint: filepath.Join(dir, "import.json"), BatchSize: 4}
+	if stats, err := Import(context.Background(), put, []string{file}, opts); err == nil || stats.Items != 4 {
+		t.Fatalf("Import() = %+v, %v, want an error after 4 items", stats, err)
+	}
+	fail = false
+	if stats, err := Import(context.Background(), put, []string{file}, opts); err != nil || stats.Items != 10 {
+		t.Fatalf("Import() resumed = %+v, %v, want 10 items", stats, err)
+	}
+	checkItems(t, imported, items)
+	if stats, err := Import(context.Background(), put, []string{file}, opts); err != nil || stats.Items != 10 || len(imported) != 10 {
+		t.Errorf("Import() after done = %+v, %v, imported %d, want nothing new", stats, err, len(imported))
+	}
+}
@@ -0,0 +1,270 @@
+package dump
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/fanout"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/store"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// CheckpointFile 是导出目录中检查点文件的文件名
+const CheckpointFile = "checkpoint.json"
+
+// DefaultPartItems 是每个 part 文件默认包含的 SeqItem 数
+const DefaultPartItems = 100000
+
+// Checkpoint 记录导出的进度，只在 part 文件完整写入后更新
+type Checkpoint struct {
+	Format      Format      `json:"format"`
+	Compression Compression `json:"compression"`
+	StartRow    []byte      `json:"start_row"` // 导出的 rowkey 区间，续传时必须与本次导出一致
+	StopRow     []byte      `json:"stop_row"`
+	Parts       int         `json:"parts"`        // 已完整写入的 part 文件数
+	Items       int64       `json:"items"`        // 已完整写入的 SeqItem 数
+	LastRowKey  []byte      `json:"last_row_key"` // 最后一个已写入的行，续传时从其后开始扫描
+	Done        bool        `json:"done"`
+}
+
+// ExportOptions 描述一次导出
+type ExportOptions struct {
+	Dir         string // 导出目录，不存在时创建
+	Format      Format
+	Compression Compression
+	PartItems   int                                   // 每个 part 文件的 SeqItem 数，0 表示 DefaultPartItems
+	Decode      func(*store.Row) (*pb.SeqItem, error) // 将行还原为 SeqItem，返回 nil 表示跳过该行，见 RowDecoder
+	Codec       rowkey.Codec                          // 表的 rowkey 编码，加盐时整张表按去掉盐值后的顺序导出；nil 表示按 rowkey 顺序
+}
+
+// Stats 是导出或导入的统计
+type Stats struct {
+	Items   int64 // 累计处理的 SeqItem 数，包括续传前已处理的
+	Skipped int64 // 本次跳过的行数
+	Parts   int   // 导出的 part 文件数
+}
+
+// RowDecoder 返回 ExportOptions.Decode 的默认实现：
+// value 是带 Key 的 SeqItem 时原样导出，否则以 rowkey 解码出的 SeqKey 和原始 value 组成 SeqItem；
+// 没有 value 的行（如计数器所在的 BizId 前缀行）被跳过
+func RowDecoder(codec rowkey.Codec) func(*store.Row) (*pb.SeqItem, error) {
+	return func(row *store.Row) (*pb.SeqItem, error) {
+		if len(row.Value) == 0 {
+			return nil, nil
+		}
+		item := &pb.SeqItem{}
+		if proto.Unmarshal(row.Value, item) == nil && item.Key != nil {
+			return item, nil
+		}
+		key, err := codec.Decode(row.Key)
+		if err != nil {
+			return nil, fmt.Errorf("row %q: value is not a SeqItem and row key cannot be decoded: %v", row.Key, err)
+		}
+		return &pb.SeqItem{Key: key, Value: row.Value}, nil
+	}
+}
+
+// Export 按 rowkey 升序将 rng 内的行导出到 opts.Dir
+// rng 为整张表且 opts.Codec 加盐时，每个盐值桶并行扫描，按去掉盐值后的 rowkey 归并，同一 BizId 的行连续导出
+// 目录中已有同一区间的检查点时从检查点继续，删除检查点之后未写完的 part 文件；已完成的导出直接返回
+func Export(ctx context.Context, st store.SeqStore, rng store.Range, opts ExportOptions) (Stats, error) {
+	if rng.Reverse {
+		return Stats{}, errors.New("dump: export scans in ascending row key order")
+	}
+	if opts.PartItems <= 0 {
+		opts.PartItems = DefaultPartItems
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return Stats{}, err
+	}
+	cpPath := filepath.Join(opts.Dir, CheckpointFile)
+	cp := Checkpoint{Format: opts.Format, Compression: opts.Compression, StartRow: rng.StartRow, StopRow: rng.StopRow}
+	if err := loadJSON(cpPath, &cp); err != nil {
+		return Stats{}, err
+	}
+	if cp.Format != opts.Format || cp.Compression != opts.Compression ||
+		!bytes.Equal(cp.StartRow, rng.StartRow) || !bytes.Equal(cp.StopRow, rng.StopRow) {
+		return Stats{}, fmt.Errorf("dump: %s belongs to a different export (%s, %s, [%q, %q))",
+			cpPath, cp.Format, cp.Compression, cp.StartRow, cp.StopRow)
+	}
+	stats := Stats{Items: cp.Items, Parts: cp.Parts}
+	if cp.Done {
+		return stats, nil
+	}
+	if err := removeParts(opts.Dir, cp.Parts); err != nil {
+		return stats, err
+	}
+	var (
+		sources []fanout.Source
+		less    fanout.Less
+	)
+	if len(rng.StartRow) == 0 && len(rng.StopRow) == 0 {
+		// 整张表按逻辑顺序导出，续传时每个桶从逻辑上紧随 LastRowKey 的位置开始
+		if cp.LastRowKey != nil {
+			rng.StartRow = append(bytes.Clone(fanout.Logical(opts.Codec, cp.LastRowKey)), 0)
+		}
+		sources, less = fanout.Buckets(opts.Codec, rng)
+	} else {
+		if cp.LastRowKey != nil {
+			rng.StartRow = append(bytes.Clone(cp.LastRowKey), 0) // 紧随 LastRowKey 之后的 rowkey
+		}
+		sources, less = []fanout.Source{{rng}}, fanout.ByRowKey(false)
+	}
+	scanner := fanout.Scan(ctx, st, sources, less, fanout.Options{})
+	defer scanner.Close()
+
+	var part *partWriter
+	defer func() {
+		if part != nil {
+			part.abort()
+		}
+	}()
+	var lastRowKey []byte
+	for {
+		row, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return stats, err
+		}
+		item, err := opts.Decode(row)
+		if err != nil {
+			return stats, err
+		}
+		if item == nil {
+			stats.Skipped++
+			continue
+		}
+		if part == nil {
+			if part, err = createPart(opts.Dir, FileName(cp.Parts, opts.Format, opts.Compression), opts.Format, opts.Compression); err != nil {
+				return stats, err
+			}
+		}
+		if err := part.w.Write(item); err != nil {
+			return stats, err
+		}
+		part.items++
+		lastRowKey = row.Key
+		if part.items == opts.PartItems {
+			if err := finishPart(cpPath, &cp, part, lastRowKey); err != nil {
+				return stats, err
+			}
+			part = nil
+			stats.Items, stats.Parts = cp.Items, cp.Parts
+		}
+	}
+	if part != nil {
+		if err := finishPart(cpPath, &cp, part, lastRowKey); err != nil {
+			return stats, err
+		}
+		part = nil
+	}
+	cp.Done = true
+	stats.Items, stats.Parts = cp.Items, cp.Parts
+	return stats, saveJSON(cpPath, cp)
+}
+
+// partWriter 是正在写入的 part 文件
+type partWriter struct {
+	f     *os.File
+	w     *Writer
+	items int
+}
+
+func createPart(dir, name string, format Format, compression Compression) (*partWriter, error) {
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewWriter(f, format, compression)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &partWriter{f: f, w: w}, nil
+}
+
+// close 结束写入并将文件落盘
+func (p *partWriter) close() error {
+	if err := p.w.Close(); err != nil {
+		p.f.Close()
+		return err
+	}
+	if err := p.f.Sync(); err != nil {
+		p.f.Close()
+		return err
+	}
+	return p.f.Close()
+}
+
+// abort 关闭未写完的文件，文件在续传时被删除
+func (p *partWriter) abort() {
+	p.f.Close()
+}
+
+// finishPart 关闭 part 文件并记录检查点
+func finishPart(cpPath string, cp *Checkpoint, part *partWriter, lastRowKey []byte) error {
+	if err := part.close(); err != nil {
+		return err
+	}
+	cp.Parts++
+	cp.Items += int64(part.items)
+	cp.LastRowKey = bytes.Clone(lastRowKey)
+	return saveJSON(cpPath, *cp)
+}
+
+// removeParts 删除 dir 中编号不小于 from 的 part 文件
+func removeParts(dir string, from int) error {
+	names, err := filepath.Glob(filepath.Join(dir, "part-*"))
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		var index int
+		if _, err := fmt.Sscanf(filepath.Base(name), "part-%05d.", &index); err != nil || index < from {
+			continue
+		}
+		if err := os.Remove(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadJSON 读取 JSON 文件到 v，文件不存在时保持 v 不变
+func loadJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("dump: %s: %v", path, err)
+	}
+	return nil
+}
+
+// saveJSON 先写临时文件再重命名，中断时不会留下不完整的文件
+func saveJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,150 @@
+package dump
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	pb "go-hbase-demo/cloudpb"
+)
+
+// DefaultBatchSize 是导入时每次写入的 SeqItem 数
+const DefaultBatchSize = 500
+
+// ImportCheckpoint 记录导入的进度，key 为导入文件的路径
+type ImportCheckpoint struct {
+	Files map[string]FileProgress `json:"files"`
+}
+
+// FileProgress 是一个文件的导入进度
+type FileProgress struct {
+	Items int64 `json:"items"` // 已写入的 SeqItem 数，续传时跳过
+	Done  bool  `json:"done"`
+}
+
+// ImportOptions 描述一次导入
+type ImportOptions struct {
+	Checkpoint string // 检查点文件路径，为空时不记录进度
+	BatchSize  int    // 每次 put 的 SeqItem 数，0 表示 DefaultBatchSize
+}
+
+// ListFiles 将 paths 展开为要导入的文件：目录展开为其中按文件名排序的 part 文件，文件原样保留
+func ListFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		parts, err := filepath.Glob(filepath.Join(path, "part-*"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(parts)
+		files = append(files, parts...)
+	}
+	return files, nil
+}
+
+// Import 按顺序读取 files 并以 BatchSize 为一批调用 put 写入，格式和压缩方式由文件名判断
+// 每批写入成功后更新检查点，再次导入时跳过已写入的 SeqItem；put 须将整批写入成功才返回 nil
+func Import(ctx context.Context, put func(context.Context, []*pb.SeqItem) error, files []string, opts ImportOptions) (Stats, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	cp := ImportCheckpoint{}
+	if opts.Checkpoint != "" {
+		if err := loadJSON(opts.Checkpoint, &cp); err != nil {
+			return Stats{}, err
+		}
+	}
+	if cp.Files == nil {
+		cp.Files = map[string]FileProgress{}
+	}
+	save := func() error {
+		if opts.Checkpoint == "" {
+			return nil
+		}
+		return saveJSON(opts.Checkpoint, cp)
+	}
+
+	var stats Stats
+	for _, file := range files {
+		progress := cp.Files[file]
+		stats.Items += progress.Items
+		if progress.Done {
+			continue
+		}
+		err := importFile(ctx, file, progress.Items, opts.BatchSize, func(batch []*pb.SeqItem) error {
+			if err := put(ctx, batch); err != nil {
+				return err
+			}
+			progress.Items += int64(len(batch))
+			stats.Items += int64(len(batch))
+			cp.Files[file] = progress
+			return save()
+		})
+		if err != nil {
+			return stats, fmt.Errorf("import %s: %v", file, err)
+		}
+		progress.Done = true
+		cp.Files[file] = progress
+		if err := save(); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// importFile 跳过 file 中的前 skip 个 SeqItem，将其余的按 batchSize 分批交给 flush
+func importFile(ctx context.Context, file string, skip int64, batchSize int, flush func([]*pb.SeqItem) error) error {
+	format, compression, err := ParseFileName(file)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := NewReader(f, format, compression)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	batch := make([]*pb.SeqItem, 0, batchSize)
+	for n := int64(0); ; n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		item, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("item %d: %v", n, err)
+		}
+		if n < skip {
+			continue
+		}
+		batch = append(batch, item)
+		if len(batch) == batchSize {
+			if err := flush(batch); err != nil {
+				return err
+			}
+			batch = make([]*pb.SeqItem, 0, batchSize)
+		}
+	}
+	if len(batch) > 0 {
+		return flush(batch)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package fanout
+
+import (
+	"bytes"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/store"
+)
+
+// Buckets 返回按逻辑顺序扫描 rng 的数据源及归并顺序
+// codec 为 rowkey.Salted 时 rng 的 StartRow、StopRow 是去掉盐值后的 rowkey（即 Binary 格式），为空表示不设边界，
+// 每个盐值桶一个数据源，按 ByLogicalKey 归并；其他编码的 rowkey 本身即为逻辑顺序，rng 是唯一的数据源，按 ByRowKey 归并
+func Buckets(codec rowkey.Codec, rng store.Range) ([]Source, Less) {
+	salted, ok := codec.(*rowkey.Salted)
+	if !ok {
+		return []Source{{rng}}, ByRowKey(rng.Reverse)
+	}
+	sources := make([]Source, salted.Buckets())
+	for b := range sources {
+		bucket := []byte{byte(b)}
+		var next []byte // 下一个桶的起点，最后一个可能的桶没有
+		if b < rowkey.MaxBuckets-1 {
+			next = []byte{byte(b + 1)}
+		}
+		r := rng
+		if !rng.Reverse {
+			// [b+StartRow, b+StopRow)
+			r.StartRow = append(bytes.Clone(bucket), rng.StartRow...)
+			r.StopRow = next
+			if len(rng.StopRow) > 0 {
+				r.StopRow = append(bytes.Clone(bucket), rng.StopRow...)
+			}
+		} else {
+			// 从 b+StartRow（含）向下到 b+StopRow（不含），StartRow 为空时从桶的末尾开始
+			r.StartRow = next
+			if len(rng.StartRow) > 0 {
+				r.StartRow = append(bytes.Clone(bucket), rng.StartRow...)
+			}
+			r.StopRow = append(bytes.Clone(bucket), rng.StopRow...)
+		}
+		sources[b] = Source{r}
+	}
+	return sources, ByLogicalKey(codec, rng.Reverse)
+}
+
+// Group 将按结果顺序排列的 ranges 按盐值桶分组，每组保持原来的顺序；codec 不加盐时所有范围为一组
+// ranges 中的每个范围须位于一个桶内，如 seqrange 为单个 BizId 计算的范围
+func Group(codec rowkey.Codec, ranges []store.Range) []Source {
+	if _, ok := codec.(*rowkey.Salted); !ok {
+		if len(ranges) == 0 {
+			return nil
+		}
+		return []Source{ranges}
+	}
+	var (
+		sources []Source
+		index   = map[byte]int{} // 桶号到 sources 下标
+	)
+	for _, rng := range ranges {
+		b := rng.StartRow[0]
+		i, ok := index[b]
+		if !ok {
+			i = len(sources)
+			index[b] = i
+			sources = append(sources, nil)
+		}
+		sources[i] = append(sources[i], rng)
+	}
+	return sources
+}
+
+// Logical 返回 rowKey 去掉盐值后的部分，codec 不加盐时原样返回
+func Logical(codec rowkey.Codec, rowKey []byte) []byte {
+	if _, ok := codec.(*rowkey.Salted); ok && len(rowKey) > 0 {
+		return rowKey[1:]
+	}
+	return rowKey
+}
+
+// ByLogicalKey 按去掉盐值后的 rowkey 归并，reverse 为 true 时按降序
+func ByLogicalKey(codec rowkey.Codec, reverse bool) Less {
+	return func(a, b *store.Row) bool {
+		c := bytes.Compare(Logical(codec, a.Key), Logical(codec, b.Key))
+		if reverse {
+			return c > 0
+		}
+		return c < 0
+	}
+}
+
+// ByKey 按解码后的 (BizId, seq) 归并，reverse 为 true 时按降序；无法解码的行按 rowkey 比较
+// 每行只在进入堆时解码一次
+func ByKey(codec rowkey.Codec, reverse bool) Order {
+	return byKey{codec: codec, reverse: reverse}
+}
+
+type byKey struct {
+	codec   rowkey.Codec
+	reverse bool
+}
+
+// decodedRow 是 byKey 的排序键，rowkey 无法解码时 key 为 nil
+type decodedRow struct {
+	key    *pb.SeqKey
+	rowKey []byte
+}
+
+func (o byKey) Key(row *store.Row) any {
+	key, err := o.codec.Decode(row.Key)
+	if err != nil {
+		key = nil
+	}
+	return decodedRow{key: key, rowKey: row.Key}
+}
+
+func (o byKey) Before(a, b any) bool {
+	ra, rb := a.(decodedRow), b.(decodedRow)
+	var c int
+	if ra.key == nil || rb.key == nil {
+		c = bytes.Compare(ra.rowKey, rb.rowKey)
+	} else if c = bytes.Compare(ra.key.BizId, rb.key.BizId); c == 0 {
+		switch {
+		case ra.key.Seq < rb.key.Seq:
+			c = -1
+		case ra.key.Seq > rb.key.Seq:
+			c = 1
+		}
+	}
+	if o.reverse {
+		return c > 0
+	}
+	return c < 0
+}
@@ -0,0 +1,234 @@
+// Package fanout 并行扫描多个有序的数据源，用堆将结果归并为全局有序的输出
+//
+// 加盐的 rowkey（rowkey.Salted）把表分散到多个盐值桶，每个桶内按 rowkey 有序，桶之间却没有逻辑顺序，
+// 按 rowkey 顺序扫描整张表得到的是逐桶拼接的结果。Buckets 和 Group 为每个桶生成一个数据源，
+// Scan 为每个数据源启动一个并行扫描，按 Order 归并：
+//   - ByLogicalKey：去掉盐值后的 rowkey 顺序，即 Binary 编码下整张表的顺序
+//   - ByKey：(BizId, seq) 顺序，用于跨 BizId 的范围查询
+//   - ByRowKey：原始 rowkey 顺序，只借助并行扫描预取
+package fanout
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"io"
+	"sync"
+
+	"go-hbase-demo/store"
+)
+
+// DefaultBuffer 是每个数据源默认预取的行数
+const DefaultBuffer = 256
+
+// Source 是依次扫描的若干范围，其结果须已按归并顺序排列
+type Source []store.Range
+
+// Order 是归并顺序，每行进入堆时调用一次 Key 计算排序键，堆中的比较只调用 Before
+type Order interface {
+	Key(row *store.Row) any
+	Before(a, b any) bool // 判断排序键 a 是否应排在 b 之前
+}
+
+// Less 判断 a 是否应排在 b 之前，以行本身为排序键，用于比较开销很小的顺序
+type Less func(a, b *store.Row) bool
+
+func (less Less) Key(row *store.Row) any { return row }
+func (less Less) Before(a, b any) bool   { return less(a.(*store.Row), b.(*store.Row)) }
+
+// Options 是 Scan 的可选参数
+type Options struct {
+	Limit  int // 最多返回的行数，0 表示不限制
+	Buffer int // 每个数据源预取的行数，0 表示 DefaultBuffer
+}
+
+// result 是数据源扫描到的一行或扫描出错
+type result struct {
+	row *store.Row
+	err error
+}
+
+// Scan 为每个数据源启动一个 goroutine 并行扫描，按 order 归并为一个 Scanner
+// ctx 取消或调用 Close 时停止所有扫描；任一数据源出错时 Next 返回该错误
+func Scan(ctx context.Context, st store.SeqStore, sources []Source, order Order, opts Options) store.Scanner {
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultBuffer
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	m := &mergeScanner{ctx: ctx, cancel: cancel, heap: &rowHeap{order: order}, popped: -1, limit: opts.Limit}
+	for _, source := range sources {
+		if len(source) == 0 {
+			continue
+		}
+		ch := make(chan result, opts.Buffer)
+		m.chans = append(m.chans, ch)
+		m.wg.Add(1)
+		go func(source Source, ch chan<- result) {
+			defer m.wg.Done()
+			defer close(ch)
+			scanSource(ctx, st, source, opts.Limit, ch)
+		}(source, ch)
+	}
+	return m
+}
+
+// scanSource 依次扫描 source 中的范围，将结果发送到 ch；limit 大于 0 时最多发送 limit 行
+func scanSource(ctx context.Context, st store.SeqStore, source Source, limit int, ch chan<- result) {
+	send := func(r result) bool {
+		select {
+		case ch <- r:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	sent := 0
+	for _, rng := range source {
+		if limit > 0 {
+			// 归并结果最多 limit 行，每个数据源也最多需要 limit 行
+			if rng.Limit <= 0 || rng.Limit > limit-sent {
+				rng.Limit = limit - sent
+			}
+		}
+		scanner, err := st.Scan(ctx, rng)
+		if err != nil {
+			send(result{err: err})
+			return
+		}
+		for {
+			row, err := scanner.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				scanner.Close()
+				send(result{err: err})
+				return
+			}
+			if !send(result{row: row}) {
+				scanner.Close()
+				return
+			}
+			if sent++; limit > 0 && sent == limit {
+				scanner.Close()
+				return
+			}
+		}
+		scanner.Close()
+	}
+}
+
+// mergeScanner 用堆归并各数据源的结果
+type mergeScanner struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	chans   []chan result
+	heap    *rowHeap
+	started bool
+	popped  int // 上一次返回的行所在的数据源，下一次 Next 时从中补充一行，-1 表示没有
+	limit   int // 最多返回的行数，0 表示不限制
+	n       int // 已返回的行数
+	err     error
+}
+
+// pull 从第 i 个数据源取下一行放入堆，数据源结束时不放入
+func (m *mergeScanner) pull(i int) error {
+	select {
+	case r, ok := <-m.chans[i]:
+		if !ok {
+			return nil
+		}
+		if r.err != nil {
+			return r.err
+		}
+		heap.Push(m.heap, entry{row: r.row, key: m.heap.order.Key(r.row), source: i})
+		return nil
+	case <-m.ctx.Done():
+		return m.ctx.Err()
+	}
+}
+
+// Next 返回归并后的下一行，扫描结束时返回 io.EOF
+func (m *mergeScanner) Next() (*store.Row, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if !m.started {
+		m.started = true
+		for i := range m.chans {
+			if err := m.pull(i); err != nil {
+				return m.fail(err)
+			}
+		}
+	}
+	if m.popped >= 0 {
+		// 数据源的出错位置在上一次返回的行之后，推迟到这里补充，使出错前的行都能返回
+		i := m.popped
+		m.popped = -1
+		if err := m.pull(i); err != nil {
+			return m.fail(err)
+		}
+	}
+	if m.heap.Len() == 0 || m.limit > 0 && m.n == m.limit {
+		return nil, io.EOF
+	}
+	e := heap.Pop(m.heap).(entry)
+	m.popped = e.source
+	m.n++
+	return e.row, nil
+}
+
+func (m *mergeScanner) fail(err error) (*store.Row, error) {
+	m.err = err
+	m.cancel()
+	return nil, err
+}
+
+// Close 停止所有扫描并等待 goroutine 退出
+func (m *mergeScanner) Close() error {
+	m.cancel()
+	m.wg.Wait()
+	return nil
+}
+
+// entry 是堆中的一行及其排序键和来源
+type entry struct {
+	row    *store.Row
+	key    any
+	source int
+}
+
+type rowHeap struct {
+	entries []entry
+	order   Order
+}
+
+func (h *rowHeap) Len() int { return len(h.entries) }
+func (h *rowHeap) Less(i, j int) bool {
+	a, b := h.entries[i], h.entries[j]
+	if h.order.Before(a.key, b.key) {
+		return true
+	}
+	if h.order.Before(b.key, a.key) {
+		return false
+	}
+	return a.source < b.source // 顺序相同时按数据源的顺序输出，使结果确定
+}
+func (h *rowHeap) Swap(i, j int) { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+func (h *rowHeap) Push(x any)    { h.entries = append(h.entries, x.(entry)) }
+func (h *rowHeap) Pop() any {
+	e := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return e
+}
+
+// ByRowKey 按 rowkey 顺序归并，reverse 为 true 时按降序
+func ByRowKey(reverse bool) Less {
+	return func(a, b *store.Row) bool {
+		if reverse {
+			return bytes.Compare(a.Key, b.Key) > 0
+		}
+		return bytes.Compare(a.Key, b.Key) < 0
+	}
+}
@@ -0,0 +1,252 @@
+package fanout
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/seqrange"
+	"go-hbase-demo/store"
+)
+
+func newSalted(t *testing.T) *rowkey.Salted {
+	t.Helper()
+	codec, err := rowkey.NewSalted(4, rowkey.XXHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return codec
+}
+
+// newStore 写入 bizs 个 BizId，每个 seqs 行
+func newStore(t *testing.T, codec rowkey.Codec, bizs, seqs int) (store.SeqStore, []*pb.SeqKey) {
+	t.Helper()
+	s := store.NewMemoryStore(store.Options{RowKey: codec.Encode})
+	var (
+		items []*pb.SeqItem
+		keys  []*pb.SeqKey
+	)
+	for i := 0; i < bizs; i++ {
+		for seq := 0; seq < seqs; seq++ {
+			key := &pb.SeqKey{BizId: []byte(fmt.Sprintf("biz%d", i)), Seq: int32(seq)}
+			items = append(items, &pb.SeqItem{Key: key})
+			keys = append(keys, key)
+		}
+	}
+	if _, err := s.Put(context.Background(), items, 0); err != nil {
+		t.Fatal(err)
+	}
+	return s, keys
+}
+
+// collect 读出 scanner 的所有行并关闭 scanner
+func collect(t *testing.T, scanner store.Scanner) [][]byte {
+	t.Helper()
+	defer scanner.Close()
+	var keys [][]byte
+	for {
+		row, err := scanner.Next()
+		if err == io.EOF {
+			return keys
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		keys = append(keys, row.Key)
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	codec := newSalted(t)
+	s, keys := newStore(t, codec, 20, 5)
+	// 逻辑顺序即 Binary 编码的顺序
+	var logical [][]byte
+	for _, key := range keys {
+		logical = append(logical, rowkey.Binary{}.Encode(key))
+	}
+	sort.Slice(logical, func(i, j int) bool { return bytes.Compare(logical[i], logical[j]) < 0 })
+	from := rowkey.Binary{}.Encode(&pb.SeqKey{BizId: []byte("biz13"), Seq: 2})
+	fromIndex := sort.Search(len(logical), func(i int) bool { return bytes.Compare(logical[i], from) >= 0 })
+
+	tests := []struct {
+		name  string
+		rng   store.Range
+		limit int
+		want  [][]byte
+	}{
+		{"all", store.Range{}, 0, logical},
+		{"limit", store.Range{}, 7, logical[:7]},
+		{"from", store.Range{StartRow: from}, 0, logical[fromIndex:]},
+		{"reverse", store.Range{Reverse: true}, 0, reversed(logical)},
+		{"reverse to", store.Range{Reverse: true, StopRow: from}, 3, reversed(logical[fromIndex+1:])[:3]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources, less := Buckets(codec, tt.rng)
+			if len(sources) != codec.Buckets() {
+				t.Fatalf("Buckets() = %d sources, want %d", len(sources), codec.Buckets())
+			}
+			var got [][]byte
+			for _, key := range collect(t, Scan(context.Background(), s, sources, less, Options{Limit: tt.limit, Buffer: 2})) {
+				got = append(got, Logical(codec, key))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// 不加盐的编码只有一个数据源
+	if sources, _ := Buckets(rowkey.Binary{}, store.Range{}); len(sources) != 1 {
+		t.Errorf("Buckets(Binary) = %d sources, want 1", len(sources))
+	}
+}
+
+func reversed(keys [][]byte) [][]byte {
+	r := make([][]byte, len(keys))
+	for i, key := range keys {
+		r[len(keys)-1-i] = key
+	}
+	return r
+}
+
+func TestGroup(t *testing.T) {
+	for _, codec := range []rowkey.Codec{rowkey.Binary{}, newSalted(t)} {
+		s, _ := newStore(t, codec, 12, 3)
+		for _, reverse := range []bool{false, true} {
+			req := &pb.RangeReq{Start: &pb.SeqKey{Seq: 1}, Reverse: reverse}
+			for i := 0; i < 12; i++ {
+				req.BizIds = append(req.BizIds, []byte(fmt.Sprintf("biz%d", i)))
+			}
+			bizIDs, err := seqrange.MultiBizIDs(context.Background(), s, codec, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var (
+				ranges []store.Range
+				want   []string
+			)
+			for _, bizID := range bizIDs {
+				rng, _, err := seqrange.MultiBounds(codec, req, bizID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ranges = append(ranges, rng)
+				if reverse {
+					want = append(want, fmt.Sprintf("%s/2", bizID), fmt.Sprintf("%s/1", bizID))
+				} else {
+					want = append(want, fmt.Sprintf("%s/1", bizID), fmt.Sprintf("%s/2", bizID))
+				}
+			}
+			var got []string
+			for _, key := range collect(t, Scan(context.Background(), s, Group(codec, ranges), ByKey(codec, reverse), Options{})) {
+				k, err := codec.Decode(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, fmt.Sprintf("%s/%d", k.BizId, k.Seq))
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%T reverse=%v: Scan() = %v, want %v", codec, reverse, got, want)
+			}
+		}
+	}
+}
+
+// countingCodec 记录 Decode 的调用次数
+type countingCodec struct {
+	rowkey.Codec
+	decodes int
+}
+
+func (c *countingCodec) Decode(row []byte) (*pb.SeqKey, error) {
+	c.decodes++
+	return c.Codec.Decode(row)
+}
+
+// ByKey 在行进入堆时解码一次，堆中的比较不再解码
+func TestByKey_DecodeOnce(t *testing.T) {
+	salted := newSalted(t)
+	s, keys := newStore(t, salted, 12, 3)
+	var ranges []store.Range
+	for i := 0; i < 12; i++ {
+		rng, _, err := seqrange.Bounds(salted, &pb.RangeReq{
+			Start: &pb.SeqKey{BizId: []byte(fmt.Sprintf("biz%d", i)), Seq: 0},
+			End:   &pb.SeqKey{BizId: []byte(fmt.Sprintf("biz%d", i)), Seq: math.MaxInt32},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ranges = append(ranges, rng)
+	}
+	codec := &countingCodec{Codec: salted}
+	rows := collect(t, Scan(context.Background(), s, Group(salted, ranges), ByKey(codec, false), Options{}))
+	if len(rows) != len(keys) {
+		t.Fatalf("Scan() returned %d rows, want %d", len(rows), len(keys))
+	}
+	if codec.decodes != len(rows) {
+		t.Errorf("Decode called %d times for %d rows, want once per row", codec.decodes, len(rows))
+	}
+}
+
+// failingStore 扫描指定桶时出错
+type failingStore struct {
+	store.SeqStore
+	bucket byte
+}
+
+var errScan = errors.New("scan failed")
+
+func (s *failingStore) Scan(ctx context.Context, rng store.Range) (store.Scanner, error) {
+	if len(rng.StartRow) > 0 && rng.StartRow[0] == s.bucket {
+		return nil, errScan
+	}
+	return s.SeqStore.Scan(ctx, rng)
+}
+
+func TestScan_Error(t *testing.T) {
+	codec := newSalted(t)
+	s, _ := newStore(t, codec, 20, 5)
+	sources, less := Buckets(codec, store.Range{})
+	scanner := Scan(context.Background(), &failingStore{SeqStore: s, bucket: 2}, sources, less, Options{Buffer: 1})
+	defer scanner.Close()
+	var err error
+	for err == nil {
+		_, err = scanner.Next()
+	}
+	if !errors.Is(err, errScan) {
+		t.Errorf("Next() error = %v, want %v", err, errScan)
+	}
+	if _, err := scanner.Next(); !errors.Is(err, errScan) {
+		t.Errorf("Next() after error = %v, want %v", err, errScan)
+	}
+}
+
+func TestScan_Cancel(t *testing.T) {
+	codec := newSalted(t)
+	s, _ := newStore(t, codec, 20, 5)
+	sources, less := Buckets(codec, store.Range{})
+	ctx, cancel := context.WithCancel(context.Background())
+	scanner := Scan(ctx, s, sources, less, Options{Buffer: 1})
+	if _, err := scanner.Next(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	var err error
+	for i := 0; err == nil && i < 100; i++ {
+		_, err = scanner.Next()
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Next() after cancel = %v, want %v", err, context.Canceled)
+	}
+	// Close 等待所有扫描 goroutine 退出
+	scanner.Close()
+}
@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"os"
 
 	"demo/gen-go/hbase" // Thrift 生成的 HBase 客户端代码
+	"go-hbase-demo/config"
 
 	"github.com/apache/thrift/lib/go/thrift"
 )
@@ -26,28 +29,22 @@ func generateRowKey(fileID string, revision int32) string {
 	return rowKey
 }
 
-// 创建 Thrift 客户端并连接到 HBase
-func createThriftClient() (*hbase.THBaseServiceClient, error) {
-	const (
-		HOST     = "http://ld-7xv325q01b2720rk9-proxy-lindorm-pub.lindorm.rds.aliyuncs.com:9190"
-		USER     = "root"
-		PASSWORD = "GkWWCUPb"
-	)
-
+// 创建 Thrift 客户端并连接到 HBase，地址和凭据来自配置
+func createThriftClient(cfg config.ThriftConfig) (*hbase.THBaseServiceClient, error) {
 	protocolFactory := thrift.NewTBinaryProtocolFactoryDefault()
-	trans, err := thrift.NewTHttpClient(HOST)
+	trans, err := thrift.NewTHttpClient(cfg.Host)
 	if err != nil {
 		return nil, fmt.Errorf("error resolving address: %v", err)
 	}
 
 	// 设置用户名和密码
 	httpClient := trans.(*thrift.THttpClient)
-	httpClient.SetHeader("ACCESSKEYID", USER)
-	httpClient.SetHeader("ACCESSSIGNATURE", PASSWORD)
+	httpClient.SetHeader("ACCESSKEYID", cfg.User)
+	httpClient.SetHeader("ACCESSSIGNATURE", cfg.Password)
 
 	client := hbase.NewTHBaseServiceClientFactory(trans, protocolFactory)
 	if err := trans.Open(); err != nil {
-		return nil, fmt.Errorf("Error opening %s: %v", HOST, err)
+		return nil, fmt.Errorf("Error opening %s: %v", cfg.Host, err)
 	}
 	return client, nil
 }
@@ -231,15 +228,20 @@ func deleteRange(client *hbase.THBaseServiceClient, ctx context.Context, tableNa
 }
 
 func main() {
-	client, err := createThriftClient()
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	client, err := createThriftClient(cfg.Thrift)
 	if err != nil {
 		log.Fatalf("failed to create Thrift client: %v", err)
 	}
 
 	ctx := context.Background()
 
-	tableName := "my_table"
-	family := "cf"
+	tableName := cfg.Table.Name
+	family := cfg.Table.Family
 
 	// 删除表（如果存在）
 	if err := deleteTable(client, ctx, tableName); err != nil {
@@ -256,7 +258,7 @@ func main() {
 		fileID := fmt.Sprintf("biz%d", i)
 		value := fmt.Sprintf("value%d", i)
 		rowKey := generateRowKey(fileID, int32(i))
-		qualifier := "value"
+		qualifier := cfg.Table.Qualifier
 
 		if err := putItem(client, ctx, tableName, rowKey, family, qualifier, value); err != nil {
 			log.Fatalf("failed to put item: %v", err)
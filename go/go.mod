@@ -2,4 +2,11 @@ module demo
 
 go 1.21.12
 
-require github.com/apache/thrift v0.12.0
+require (
+	github.com/apache/thrift v0.12.0
+	go-hbase-demo v0.0.0
+)
+
+require gopkg.in/yaml.v3 v3.0.1 // indirect
+
+replace go-hbase-demo => ../
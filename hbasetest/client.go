@@ -0,0 +1,531 @@
+// Package hbasetest 提供一个基于内存有序存储的 gohbase.Client 实现，
+// 用于在没有 HBase 集群的情况下对 SeqDb 服务做端到端测试
+//
+// 请求通过 hrpc 调用的 ToProto 解析，与 RegionServer 看到的内容一致。支持的语义：
+// 行按 rowkey 字节序排列；正序/倒序区间扫描；NumberOfRows 控制每次扫描 RPC 返回的行数；
+// 多版本 cell 与 TimeRange / MaxVersions；列族和列过滤；整行或指定列删除；
+// Increment、Append 与 CheckAndPut。Filter 不会被执行。
+package hbasetest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tsuna/gohbase"
+	"github.com/tsuna/gohbase/hrpc"
+	"github.com/tsuna/gohbase/pb"
+	"github.com/tsuna/gohbase/region"
+	"google.golang.org/protobuf/proto"
+)
+
+// Client 是 gohbase.Client 的内存实现，可被多个 goroutine 并发使用
+type Client struct {
+	mu       sync.Mutex
+	tables   map[string]*table
+	lastTS   uint64 // 上一次分配的时间戳，保证同一 Client 内单调递增
+	scanRPCs int    // 已执行的扫描 RPC 次数
+	closed   bool
+}
+
+var _ gohbase.Client = (*Client)(nil)
+
+// NewClient 创建一个空的内存 HBase
+func NewClient() *Client {
+	return &Client{tables: map[string]*table{}}
+}
+
+// table 保存按 rowkey 排序的所有行
+type table struct {
+	keys []string // 按字节序排列的 rowkey
+	rows map[string]*row
+}
+
+// row 保存一行中的所有 cell：列族 -> 列名 -> 版本（按时间戳降序）
+type row struct {
+	families map[string]map[string][]version
+}
+
+type version struct {
+	ts    uint64
+	value []byte
+}
+
+// RowKeys 返回表中所有 rowkey，按字节序排列
+func (c *Client) RowKeys(tableName string) [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, ok := c.tables[tableName]
+	if !ok {
+		return nil
+	}
+	keys := make([][]byte, len(t.keys))
+	for i, key := range t.keys {
+		keys[i] = []byte(key)
+	}
+	return keys
+}
+
+// ScanRPCs 返回已执行的扫描 RPC 次数，每次 RPC 最多返回 NumberOfRows 行
+func (c *Client) ScanRPCs() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.scanRPCs
+}
+
+// Get 读取单行
+func (c *Client) Get(g *hrpc.Get) (*hrpc.Result, error) {
+	if err := c.check(g); err != nil {
+		return nil, err
+	}
+	req := toProto(g).(*pb.GetRequest).Get
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, ok := c.tables[string(g.Table())]
+	if !ok {
+		return &hrpc.Result{}, nil
+	}
+	r, ok := t.rows[string(req.Row)]
+	if !ok {
+		return &hrpc.Result{}, nil
+	}
+	cells := r.cells(req.Row, req.Column, req.TimeRange, req.GetMaxVersions())
+	if req.GetExistenceOnly() {
+		exists := len(cells) > 0
+		return &hrpc.Result{Exists: &exists}, nil
+	}
+	return &hrpc.Result{Cells: cells}, nil
+}
+
+// Put 写入一行中的若干 cell
+func (c *Client) Put(p *hrpc.Mutate) (*hrpc.Result, error) {
+	if err := c.check(p); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.put(string(p.Table()), toProto(p).(*pb.MutateRequest).Mutation)
+	return &hrpc.Result{}, nil
+}
+
+// Delete 删除整行，或删除 Mutate 中指定的列族 / 列
+func (c *Client) Delete(d *hrpc.Mutate) (*hrpc.Result, error) {
+	if err := c.check(d); err != nil {
+		return nil, err
+	}
+	m := toProto(d).(*pb.MutateRequest).Mutation
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, ok := c.tables[string(d.Table())]
+	if !ok {
+		return &hrpc.Result{}, nil
+	}
+	r, ok := t.rows[string(m.Row)]
+	if !ok {
+		return &hrpc.Result{}, nil
+	}
+	if len(m.ColumnValue) == 0 {
+		t.remove(string(m.Row))
+		return &hrpc.Result{}, nil
+	}
+	for _, cv := range m.ColumnValue {
+		family := string(cv.Family)
+		if len(cv.QualifierValue) == 0 {
+			delete(r.families, family)
+			continue
+		}
+		for _, qv := range cv.QualifierValue {
+			delete(r.families[family], string(qv.Qualifier))
+		}
+		if len(r.families[family]) == 0 {
+			delete(r.families, family)
+		}
+	}
+	if len(r.families) == 0 {
+		t.remove(string(m.Row))
+	}
+	return &hrpc.Result{}, nil
+}
+
+// Append 将值追加到已有 cell 之后，返回追加后的 cell
+func (c *Client) Append(a *hrpc.Mutate) (*hrpc.Result, error) {
+	if err := c.check(a); err != nil {
+		return nil, err
+	}
+	m := toProto(a).(*pb.MutateRequest).Mutation
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.table(string(a.Table()))
+	var cells []*hrpc.Cell
+	for _, cv := range m.ColumnValue {
+		for _, qv := range cv.QualifierValue {
+			old := t.latest(string(m.Row), string(cv.Family), string(qv.Qualifier))
+			value := append(bytes.Clone(old), qv.Value...)
+			ts := c.timestamp(nil)
+			t.set(string(m.Row), string(cv.Family), string(qv.Qualifier), version{ts: ts, value: value})
+			cells = append(cells, newCell(m.Row, cv.Family, qv.Qualifier, ts, value))
+		}
+	}
+	return &hrpc.Result{Cells: cells}, nil
+}
+
+// Increment 将 8 字节大端编码的增量累加到 cell 上，返回第一个 cell 累加后的值
+func (c *Client) Increment(i *hrpc.Mutate) (int64, error) {
+	if err := c.check(i); err != nil {
+		return 0, err
+	}
+	m := toProto(i).(*pb.MutateRequest).Mutation
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.table(string(i.Table()))
+	var (
+		result int64
+		first  = true
+	)
+	for _, cv := range m.ColumnValue {
+		for _, qv := range cv.QualifierValue {
+			if len(qv.Value) != 8 {
+				return 0, errors.New("hbasetest: increment value must be 8 bytes")
+			}
+			var current int64
+			if old := t.latest(string(m.Row), string(cv.Family), string(qv.Qualifier)); old != nil {
+				if len(old) != 8 {
+					return 0, errors.New("hbasetest: attempted to increment field that isn't 64 bits wide")
+				}
+				current = int64(binary.BigEndian.Uint64(old))
+			}
+			current += int64(binary.BigEndian.Uint64(qv.Value))
+			value := make([]byte, 8)
+			binary.BigEndian.PutUint64(value, uint64(current))
+			t.set(string(m.Row), string(cv.Family), string(qv.Qualifier), version{ts: c.timestamp(nil), value: value})
+			if first {
+				result, first = current, false
+			}
+		}
+	}
+	return result, nil
+}
+
+// CheckAndPut 在 family:qualifier 的最新值等于 expectedValue 时执行 Put
+// expectedValue 为空表示要求该列不存在，与 HBase 一致，值为空的列视同不存在
+func (c *Client) CheckAndPut(p *hrpc.Mutate, family string, qualifier string, expectedValue []byte) (bool, error) {
+	if err := c.check(p); err != nil {
+		return false, err
+	}
+	m := toProto(p).(*pb.MutateRequest).Mutation
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	current := c.table(string(p.Table())).latest(string(m.Row), family, qualifier)
+	if len(expectedValue) == 0 {
+		if len(current) != 0 {
+			return false, nil
+		}
+	} else if !bytes.Equal(current, expectedValue) {
+		return false, nil
+	}
+	c.put(string(p.Table()), m)
+	return true, nil
+}
+
+// Scan 返回按 NumberOfRows 分批拉取的扫描器，每批读取的是当时的最新数据
+func (c *Client) Scan(s *hrpc.Scan) hrpc.Scanner {
+	if err := c.check(s); err != nil {
+		return &scanner{err: err}
+	}
+	req := toProto(s).(*pb.ScanRequest)
+	return &scanner{
+		client: c,
+		rpc:    s,
+		scan:   req.Scan,
+		batch:  int(req.GetNumberOfRows()),
+	}
+}
+
+// Close 关闭客户端，之后的请求返回 gohbase.ErrClientClosed
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+}
+
+// check 检查客户端状态和请求的 context
+func (c *Client) check(call hrpc.Call) error {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return gohbase.ErrClientClosed
+	}
+	return call.Context().Err()
+}
+
+// put 应用一个 Put 类型的 Mutation，调用方需持有锁
+func (c *Client) put(tableName string, m *pb.MutationProto) {
+	t := c.table(tableName)
+	for _, cv := range m.ColumnValue {
+		for _, qv := range cv.QualifierValue {
+			ts := c.timestamp(qv.Timestamp)
+			t.set(string(m.Row), string(cv.Family), string(qv.Qualifier), version{ts: ts, value: bytes.Clone(qv.Value)})
+		}
+	}
+}
+
+// timestamp 返回 Mutation 指定的时间戳，未指定时按毫秒时间分配单调递增的时间戳，调用方需持有锁
+func (c *Client) timestamp(ts *uint64) uint64 {
+	if ts != nil {
+		return *ts
+	}
+	now := uint64(time.Now().UnixMilli())
+	if now <= c.lastTS {
+		now = c.lastTS + 1
+	}
+	c.lastTS = now
+	return now
+}
+
+// table 返回指定的表，不存在时创建，调用方需持有锁
+func (c *Client) table(name string) *table {
+	t, ok := c.tables[name]
+	if !ok {
+		t = &table{rows: map[string]*row{}}
+		c.tables[name] = t
+	}
+	return t
+}
+
+// set 写入一个 cell 版本，相同时间戳的版本会被覆盖
+func (t *table) set(key, family, qualifier string, v version) {
+	r, ok := t.rows[key]
+	if !ok {
+		r = &row{families: map[string]map[string][]version{}}
+		t.rows[key] = r
+		idx := sort.SearchStrings(t.keys, key)
+		t.keys = append(t.keys, "")
+		copy(t.keys[idx+1:], t.keys[idx:])
+		t.keys[idx] = key
+	}
+	qualifiers, ok := r.families[family]
+	if !ok {
+		qualifiers = map[string][]version{}
+		r.families[family] = qualifiers
+	}
+	versions := qualifiers[qualifier]
+	idx := sort.Search(len(versions), func(i int) bool { return versions[i].ts <= v.ts })
+	if idx < len(versions) && versions[idx].ts == v.ts {
+		versions[idx] = v
+	} else {
+		versions = append(versions, version{})
+		copy(versions[idx+1:], versions[idx:])
+		versions[idx] = v
+	}
+	qualifiers[qualifier] = versions
+}
+
+// latest 返回 cell 的最新值，不存在时返回 nil
+func (t *table) latest(key, family, qualifier string) []byte {
+	r, ok := t.rows[key]
+	if !ok {
+		return nil
+	}
+	versions := r.families[family][qualifier]
+	if len(versions) == 0 {
+		return nil
+	}
+	return versions[0].value
+}
+
+// remove 删除整行
+func (t *table) remove(key string) {
+	if _, ok := t.rows[key]; !ok {
+		return
+	}
+	delete(t.rows, key)
+	idx := sort.SearchStrings(t.keys, key)
+	t.keys = append(t.keys[:idx], t.keys[idx+1:]...)
+}
+
+// cells 按列族、列名排序返回满足过滤条件的 cell，同一列的版本按时间戳降序
+func (r *row) cells(key []byte, columns []*pb.Column, tr *pb.TimeRange, maxVersions uint32) []*hrpc.Cell {
+	if maxVersions == 0 {
+		maxVersions = 1
+	}
+	from, to := uint64(0), uint64(1<<63-1)
+	if tr.GetFrom() != 0 {
+		from = tr.GetFrom()
+	}
+	if tr.To != nil {
+		to = tr.GetTo()
+	}
+
+	// wanted 为空表示读取所有列，列族对应的 qualifier 集合为空表示读取整个列族
+	wanted := map[string]map[string]bool{}
+	for _, col := range columns {
+		qualifiers := map[string]bool{}
+		for _, q := range col.Qualifier {
+			qualifiers[string(q)] = true
+		}
+		wanted[string(col.Family)] = qualifiers
+	}
+
+	var cells []*hrpc.Cell
+	for _, family := range sortedKeys(r.families) {
+		qualifiers := r.families[family]
+		wantQualifiers, ok := wanted[family]
+		if len(wanted) > 0 && !ok {
+			continue
+		}
+		for _, qualifier := range sortedKeys(qualifiers) {
+			if len(wantQualifiers) > 0 && !wantQualifiers[qualifier] {
+				continue
+			}
+			n := uint32(0)
+			for _, v := range qualifiers[qualifier] {
+				if v.ts < from || v.ts >= to {
+					continue
+				}
+				cells = append(cells, newCell(key, []byte(family), []byte(qualifier), v.ts, v.value))
+				if n++; n >= maxVersions {
+					break
+				}
+			}
+		}
+	}
+	return cells
+}
+
+// scanner 实现 hrpc.Scanner，每次 RPC 最多拉取 batch 行
+type scanner struct {
+	client  *Client
+	rpc     *hrpc.Scan
+	scan    *pb.Scan
+	batch   int
+	last    []byte // 上一次 RPC 返回的最后一个 rowkey，续扫时从它之后（倒序时之前）开始
+	resumed bool   // last 是否有效
+	results []*hrpc.Result
+	done    bool
+	err     error
+}
+
+func (s *scanner) Next() (*hrpc.Result, error) {
+	if s.err != nil {
+		err := s.err
+		s.err, s.done = nil, true
+		return nil, err
+	}
+	for len(s.results) == 0 {
+		if s.done {
+			return nil, io.EOF
+		}
+		if err := s.rpc.Context().Err(); err != nil {
+			s.done = true
+			return nil, err
+		}
+		s.fetch()
+	}
+	res := s.results[0]
+	s.results = s.results[1:]
+	return res, nil
+}
+
+// fetch 执行一次扫描 RPC
+func (s *scanner) fetch() {
+	c := s.client
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scanRPCs++
+
+	t, ok := c.tables[string(s.rpc.Table())]
+	if !ok {
+		s.done = true
+		return
+	}
+	start, stop := string(s.scan.StartRow), string(s.scan.StopRow)
+	var keys []string
+	if !s.scan.GetReversed() {
+		i := sort.SearchStrings(t.keys, start)
+		if s.resumed {
+			i = sort.Search(len(t.keys), func(i int) bool { return t.keys[i] > string(s.last) })
+		}
+		for ; i < len(t.keys); i++ {
+			if stop != "" && t.keys[i] >= stop {
+				break
+			}
+			keys = append(keys, t.keys[i])
+			if s.batch > 0 && len(keys) >= s.batch {
+				break
+			}
+		}
+	} else {
+		i := len(t.keys) - 1
+		if s.resumed {
+			i = sort.SearchStrings(t.keys, string(s.last)) - 1
+		} else if start != "" {
+			i = sort.Search(len(t.keys), func(i int) bool { return t.keys[i] > start }) - 1
+		}
+		for ; i >= 0; i-- {
+			if stop != "" && t.keys[i] <= stop {
+				break
+			}
+			keys = append(keys, t.keys[i])
+			if s.batch > 0 && len(keys) >= s.batch {
+				break
+			}
+		}
+	}
+	if s.batch <= 0 || len(keys) < s.batch {
+		s.done = true
+	}
+	for _, key := range keys {
+		cells := t.rows[key].cells([]byte(key), s.scan.Column, s.scan.TimeRange, s.scan.GetMaxVersions())
+		if len(cells) > 0 {
+			s.results = append(s.results, &hrpc.Result{Cells: cells})
+		}
+	}
+	if len(keys) > 0 {
+		s.last, s.resumed = []byte(keys[len(keys)-1]), true
+	}
+}
+
+func (s *scanner) Close() error {
+	s.done = true
+	s.results = nil
+	return nil
+}
+
+// toProto 为请求设置一个覆盖整张表的 region 后转换为 protobuf，以便读取请求中的全部参数
+func toProto(call interface {
+	hrpc.Call
+	SetRegion(hrpc.RegionInfo)
+}) proto.Message {
+	call.SetRegion(region.NewInfo(0, nil, call.Table(), call.Table(), nil, nil))
+	return call.ToProto()
+}
+
+func newCell(key, family, qualifier []byte, ts uint64, value []byte) *hrpc.Cell {
+	return &hrpc.Cell{
+		Row:       bytes.Clone(key),
+		Family:    bytes.Clone(family),
+		Qualifier: bytes.Clone(qualifier),
+		Timestamp: proto.Uint64(ts),
+		CellType:  pb.CellType_PUT.Enum(),
+		Value:     bytes.Clone(value),
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,165 @@
+package hbasetest
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/tsuna/gohbase/hrpc"
+)
+
+const testTable = "t"
+
+func put(t *testing.T, c *Client, key, value string) {
+	t.Helper()
+	p, err := hrpc.NewPutStr(context.Background(), testTable, key, map[string]map[string][]byte{
+		"cf": {"q": []byte(value)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Put(p); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+}
+
+func scanKeys(t *testing.T, c *Client, start, stop string, options ...func(hrpc.Call) error) []string {
+	t.Helper()
+	scan, err := hrpc.NewScanRangeStr(context.Background(), testTable, start, stop, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := c.Scan(scan)
+	var keys []string
+	for {
+		res, err := scanner.Next()
+		if err == io.EOF {
+			return keys
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		keys = append(keys, string(res.Cells[0].Row))
+	}
+}
+
+func TestClient_Scan(t *testing.T) {
+	c := NewClient()
+	for _, key := range []string{"c", "a", "e", "b", "d"} {
+		put(t, c, key, key)
+	}
+
+	tests := []struct {
+		name        string
+		start, stop string
+		options     []func(hrpc.Call) error
+		want        []string
+		wantRPCs    int
+	}{
+		{
+			name: "forward range", start: "b", stop: "d",
+			want: []string{"b", "c"}, wantRPCs: 1,
+		},
+		{
+			name: "forward unbounded", start: "", stop: "",
+			want: []string{"a", "b", "c", "d", "e"}, wantRPCs: 1,
+		},
+		{
+			name: "reversed range", start: "d", stop: "a", options: []func(hrpc.Call) error{hrpc.Reversed()},
+			want: []string{"d", "c", "b"}, wantRPCs: 1,
+		},
+		{
+			name: "reversed unbounded", start: "", stop: "", options: []func(hrpc.Call) error{hrpc.Reversed()},
+			want: []string{"e", "d", "c", "b", "a"}, wantRPCs: 1,
+		},
+		{
+			name: "forward paged by NumberOfRows", start: "", stop: "", options: []func(hrpc.Call) error{hrpc.NumberOfRows(2)},
+			want: []string{"a", "b", "c", "d", "e"}, wantRPCs: 3,
+		},
+		{
+			name: "reversed paged by NumberOfRows", start: "e", stop: "", options: []func(hrpc.Call) error{hrpc.Reversed(), hrpc.NumberOfRows(2)},
+			want: []string{"e", "d", "c", "b", "a"}, wantRPCs: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := c.ScanRPCs()
+			if got := scanKeys(t, c, tt.start, tt.stop, tt.options...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+			if got := c.ScanRPCs() - before; got != tt.wantRPCs {
+				t.Errorf("Scan() RPCs = %d, want %d", got, tt.wantRPCs)
+			}
+		})
+	}
+}
+
+func TestClient_GetDelete(t *testing.T) {
+	c := NewClient()
+	ctx := context.Background()
+	put(t, c, "row", "v1")
+	put(t, c, "row", "v2")
+
+	get, _ := hrpc.NewGetStr(ctx, testTable, "row", hrpc.MaxVersions(10))
+	res, err := c.Get(get)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(res.Cells) != 2 || string(res.Cells[0].Value) != "v2" || string(res.Cells[1].Value) != "v1" {
+		t.Errorf("Get() versions = %v, want [v2 v1]", res.Cells)
+	}
+
+	del, _ := hrpc.NewDelStr(ctx, testTable, "row", nil)
+	if _, err := c.Delete(del); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	get, _ = hrpc.NewGetStr(ctx, testTable, "row")
+	res, err = c.Get(get)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(res.Cells) != 0 {
+		t.Errorf("Get() after Delete = %v, want no cells", res.Cells)
+	}
+	if keys := c.RowKeys(testTable); len(keys) != 0 {
+		t.Errorf("RowKeys() after Delete = %q, want empty", keys)
+	}
+}
+
+func TestClient_CheckAndPutIncrement(t *testing.T) {
+	c := NewClient()
+	ctx := context.Background()
+	values := map[string]map[string][]byte{"cf": {"q": []byte("v")}}
+
+	p, _ := hrpc.NewPutStr(ctx, testTable, "row", values)
+	if ok, err := c.CheckAndPut(p, "cf", "q", nil); err != nil || !ok {
+		t.Fatalf("CheckAndPut() on absent column = %v, %v, want true", ok, err)
+	}
+	p, _ = hrpc.NewPutStr(ctx, testTable, "row", values)
+	if ok, err := c.CheckAndPut(p, "cf", "q", nil); err != nil || ok {
+		t.Fatalf("CheckAndPut() on present column = %v, %v, want false", ok, err)
+	}
+	p, _ = hrpc.NewPutStr(ctx, testTable, "row", values)
+	if ok, err := c.CheckAndPut(p, "cf", "q", []byte("v")); err != nil || !ok {
+		t.Fatalf("CheckAndPut() with matching value = %v, %v, want true", ok, err)
+	}
+
+	for _, want := range []int64{5, 10} {
+		inc, _ := hrpc.NewIncStrSingle(ctx, testTable, "counter", "cf", "n", 5)
+		got, err := c.Increment(inc)
+		if err != nil || got != want {
+			t.Fatalf("Increment() = %v, %v, want %v", got, err, want)
+		}
+	}
+}
+
+func TestClient_ContextCanceled(t *testing.T) {
+	c := NewClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	get, _ := hrpc.NewGetStr(ctx, testTable, "row")
+	if _, err := c.Get(get); err != context.Canceled {
+		t.Errorf("Get() error = %v, want %v", err, context.Canceled)
+	}
+}
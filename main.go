@@ -2,443 +2,798 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
 
+	"go-hbase-demo/apierr"
 	pb "go-hbase-demo/cloudpb" // 导入生成的 Protobuf 包
+	"go-hbase-demo/config"
+	"go-hbase-demo/dial"
+	"go-hbase-demo/fanout"
+	"go-hbase-demo/retention"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/seqrange"
+	"go-hbase-demo/store"
+	"go-hbase-demo/tenant"
+	"go-hbase-demo/validate"
+	"go-hbase-demo/watch"
 
-	"github.com/tsuna/gohbase"
-	"github.com/tsuna/gohbase/hrpc"
 	"google.golang.org/grpc"
-	"google.golang.org/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
 // 计算 file_id 的哈希值:取fileID最后一位
 func hash(fileID string) string {
-	if len(fileID) == 0 {
-		return ""
-	}
-	return string(fileID[len(fileID)-1])
+	return string(rowkey.Legacy{}.Hash([]byte(fileID)))
 }
 
-// 根据设计生成 RowKey
+// 根据设计生成 RowKey，即 rowkey.Legacy 格式
 func generateRowKey(fileID string, revision int32) string {
-	fileIDHash := hash(fileID) // 计算 file_id 的哈希值
-	// 将 int32 转换为 uint32 并生成 RowKey，使用按位取反（^revision）实现 uint32.Max - revision
-	rowKey := fmt.Sprintf("%s_%s_%010d", fileIDHash, fileID, ^uint32(revision))
-	return rowKey
+	return string(rowkey.Legacy{}.Encode(&pb.SeqKey{BizId: []byte(fileID), Seq: revision}))
 }
 
+// SeqItem 在 HBase 中的默认存储布局
+// HBase Shell中建表：create 'my_table','cf'
+var storeOptions = dial.StoreOptions(config.Default().Table, rowkey.Legacy{})
+
 // 定义 gRPC 服务器结构体
 type server struct {
-	pb.UnimplementedSeqDbServer                // 嵌入未实现的 gRPC 服务器，提供默认实现
-	client                      gohbase.Client // HBase 客户端
+	pb.UnimplementedSeqDbServer                    // 嵌入未实现的 gRPC 服务器，提供默认实现
+	store                       store.SeqStore     // 存储后端
+	codec                       rowkey.Codec       // rowkey 编码，须与 store 使用的一致
+	compat                      bool               // 兼容模式，见 decodeRow
+	counters                    sync.Map           // 已初始化的 AllocateSeq 计数器行（namespace:rowkey），见 initCounter
+	retention                   retention.Policies // 保留策略，写入时据此设置 TTL
+	hub                         *watch.Hub         // 将写入成功的 SeqItem 分发给 Watch，为 nil 时不分发
 }
 
-// 创建新的 gRPC 服务器实例，并连接到 HBase
-func NewServer() *server {
-	client := gohbase.NewClient("ld-7xv325q01b2720rk9-proxy-lindorm-pub.lindorm.rds.aliyuncs.com:9190")
-	return &server{client: client}
+// 创建新的 gRPC 服务器实例，并根据 cfg.Server.Backend 连接存储后端，见 dial.OpenStore
+func NewServer(cfg *config.Config) (*server, error) {
+	codec, err := rowkey.New(cfg.Table.RowKey, cfg.Table.SaltBuckets, cfg.Table.SaltHash)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := dial.OpenStore(cfg, codec)
+	if err != nil {
+		return nil, err
+	}
+	return &server{
+		store:     tenant.NewStore(backend.Store, cfg.Tenant.Table, backend.Open, backend.Provisioner),
+		codec:     codec,
+		compat:    cfg.Table.Compat,
+		retention: newPolicies(cfg.Retention),
+		hub:       watch.NewHub(),
+	}, nil
 }
 
+// newPolicies 将保留策略配置转换为 retention.Policies
+func newPolicies(cfg config.RetentionConfig) retention.Policies {
+	policies := retention.Policies{
+		Default: retention.Policy{TTL: cfg.TTL, MaxSeqs: cfg.MaxSeqs},
+		Biz:     make(map[string]retention.Policy, len(cfg.Biz)),
+	}
+	for bizID, policy := range cfg.Biz {
+		policies.Biz[bizID] = retention.Policy{TTL: policy.TTL, MaxSeqs: policy.MaxSeqs}
+	}
+	return policies
+}
+
+var errMissingKey = errors.New("missing key")
+
 // 实现 gRPC 服务的 Put 方法
-// 将接收到的 SeqItems 存储到 HBase 中
+// 将接收到的 SeqItems 逐个存储到 HBase 中，单个 item 的失败不影响后续 item，结果见 PutItemResp.statuses
+// atomic 为 true 时按 BizId 原子写入，见 atomicPut；ttl_seconds 与 BizId 的保留策略共同决定数据的 TTL
 func (s *server) Put(ctx context.Context, seqItems *pb.SeqItems) (*pb.PutItemResp, error) {
-	// 插入seqItem
-	for _, item := range seqItems.Items {
-		rowKey := generateRowKey(string(item.Key.BizId), item.Key.Seq) // 生成 RowKey
-		// 序列化 SeqItem
-		data, err := proto.Marshal(item)
-		if err != nil {
-			log.Printf("Failed to marshal SeqItem: %v", err)
+	ttl, err := requestTTL(seqItems.TtlSeconds)
+	if err != nil {
+		return nil, err
+	}
+	var resp *pb.PutItemResp
+	if seqItems.Atomic {
+		resp = s.atomicPut(ctx, seqItems.Items, ttl)
+	} else {
+		// 插入seqItem
+		resp = &pb.PutItemResp{}
+		for _, item := range seqItems.Items {
+			if item.GetKey() == nil {
+				addPutStatus(resp, s.putStatus(item, 0, errMissingKey))
+				continue
+			}
+			var ts int64
+			timestamps, err := s.store.Put(ctx, []*pb.SeqItem{item}, s.retention.TTL(item.Key.BizId, ttl))
+			if err != nil {
+				log.Printf("Put %v failed: %v", item.Key, err)
+			} else {
+				ts = timestamps[0]
+			}
+			addPutStatus(resp, s.putStatus(item, ts, err))
 		}
+	}
+	s.publish(ctx, seqItems.Items, resp.Statuses)
+	log.Printf("Put request finished, %d committed, %d failed", len(resp.Committed), len(resp.Failed))
+	return resp, nil
+}
 
-		// 创建 HBase Put 请求
-		// HBase Shell中建表：create 'my_table','cf'
-		putRequest, err := hrpc.NewPutStr(ctx, "my_table", rowKey, map[string]map[string][]byte{
-			"cf": { // 列族
-				"value": data, // 列名和值
-			},
-		})
+// 实现 gRPC 服务的 BatchPut 方法
+// 将多组 SeqItems 一次性写入存储后端，结果与 Put 相同，按展开后的 items 顺序返回
+// TTL 不同的 items 分开写入，每种 TTL 一次 Put
+func (s *server) BatchPut(ctx context.Context, seqItemsList *pb.SeqItemsList) (*pb.PutItemResp, error) {
+	var (
+		items  []*pb.SeqItem
+		groups = map[time.Duration][]int{} // TTL -> 该 TTL 的 item 在 items 中的下标
+		ttls   []time.Duration             // groups 的 key，按首次出现的顺序
+	)
+	for _, seqItems := range seqItemsList.ItemsList {
+		requested, err := requestTTL(seqItems.TtlSeconds)
 		if err != nil {
-			log.Printf("Put request creation failed: %v", err)
-			return nil, err // 返回错误
+			return nil, err
+		}
+		for _, item := range seqItems.Items {
+			items = append(items, item)
+			if item.GetKey() == nil {
+				continue
+			}
+			ttl := s.retention.TTL(item.Key.BizId, requested)
+			if _, ok := groups[ttl]; !ok {
+				ttls = append(ttls, ttl)
+			}
+			groups[ttl] = append(groups[ttl], len(items)-1)
+		}
+	}
+
+	timestamps := make([]int64, len(items))
+	errs := make([]error, len(items))
+	for _, ttl := range ttls {
+		group := make([]*pb.SeqItem, len(groups[ttl]))
+		for j, i := range groups[ttl] {
+			group[j] = items[i]
 		}
-		_, err = s.client.Put(putRequest) // 执行 Put 请求
+		ts, err := s.store.Put(ctx, group, ttl)
 		if err != nil {
-			log.Printf("Put request execution failed: %v", err)
-			return nil, err // 返回错误
+			log.Printf("Batch put request execution failed: %v", err)
+		}
+		var putErr *store.PutError
+		isPutErr := errors.As(err, &putErr)
+		for j, i := range groups[ttl] {
+			errs[i] = err
+			if isPutErr {
+				errs[i] = putErr.Errs[j]
+			}
+			if j < len(ts) {
+				timestamps[i] = ts[j]
+			}
 		}
 	}
-	log.Println("Put request successful")
-	return &pb.PutItemResp{}, nil // 返回空的响应
+
+	resp := &pb.PutItemResp{}
+	for i, item := range items {
+		if item.GetKey() == nil {
+			errs[i] = errMissingKey
+		}
+		addPutStatus(resp, s.putStatus(item, timestamps[i], errs[i]))
+	}
+	s.publish(ctx, items, resp.Statuses)
+	log.Printf("BatchPut request finished, %d committed, %d failed", len(resp.Committed), len(resp.Failed))
+	return resp, nil
 }
 
-// TODO：批量存储
-func (s *server) BatchPut(ctx context.Context, seqItemsList []pb.SeqItems) (*pb.PutItemResp, error) {
-	//  // 创建一个批次操作的列表
-	//  var batch []*hrpc.Mutate
-
-	//  // 遍历每个 SeqItems
-	//  for i := range seqItemsList {
-	// 	 seqItems := &seqItemsList[i]
-	// 	 // 遍历每个 SeqItem
-	// 	 for j := range seqItems.Items {
-	// 		 item := &seqItems.Items[j]
-	// 		 rowKey := generateRowKey(string(item.Key.BizId), item.Key.Seq) // 生成 RowKey
-	// 		 // 序列化 SeqItem
-	// 		 data, err := proto.Marshal(item)
-	// 		 if err != nil {
-	// 			 log.Printf("Failed to marshal SeqItem: %v", err)
-	// 			 return nil, err // 返回错误
-	// 		 }
-
-	// 		 // 创建 HBase Put 请求
-	// 		 putRequest, err := hrpc.NewPutStr(ctx, "my_table", rowKey, map[string]map[string][]byte{
-	// 			 "cf": { // 列族
-	// 				 "value": data, // 列名和值
-	// 			 },
-	// 		 })
-	// 		 if err != nil {
-	// 			 log.Printf("Put request creation failed: %v", err)
-	// 			 return nil, err // 返回错误
-	// 		 }
-
-	// 		 // 将每个 putRequest 加入到批次列表中
-	// 		 batch = append(batch, putRequest)
-	// 	 }
-	//  }
-
-	// // 执行批次操作
-	// for _, put := range batch {
-	//     _, err := s.client.Put(put)
-	//     if err != nil {
-	//         log.Printf("Batch put request execution failed: %v", err)
-	//         return nil, err // 返回错误
-	//     }
-	// }
-
-	// log.Println("BatchPut request successful")
-	return &pb.PutItemResp{}, nil // 返回空的响应
+// requestTTL 将请求中的 ttl_seconds 转换为 TTL，0 表示请求未设置 TTL
+func requestTTL(seconds int64) (time.Duration, error) {
+	if seconds < 0 || seconds > math.MaxInt64/int64(time.Second) {
+		return 0, status.Errorf(codes.InvalidArgument, "ttl_seconds out of range: %d", seconds)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// putStatus 生成单个 item 的写入结果，err 为 nil 表示 item 已以时间戳 ts 写入
+func (s *server) putStatus(item *pb.SeqItem, ts int64, err error) *pb.PutStatus {
+	st := &pb.PutStatus{Key: item.GetKey()}
+	if item.GetKey() != nil {
+		st.RowKey = s.codec.Encode(item.Key)
+	}
+	if err != nil {
+		st.Code = int32(putErrorCode(err))
+		st.Error = err.Error()
+		return st
+	}
+	st.Timestamp = ts
+	return st
+}
+
+// putErrorCode 将写入错误映射为 gRPC 状态码
+func putErrorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, errMissingKey):
+		return codes.InvalidArgument
+	case errors.Is(err, errPutInProgress):
+		return codes.Aborted
+	}
+	return apierr.Code(err)
+}
+
+// addPutStatus 将 st 追加到 resp 中，并按是否成功记入 committed 或 failed
+func addPutStatus(resp *pb.PutItemResp, st *pb.PutStatus) {
+	resp.Statuses = append(resp.Statuses, st)
+	if st.Code == int32(codes.OK) {
+		resp.Committed = append(resp.Committed, st.Key)
+		return
+	}
+	resp.Failed = append(resp.Failed, &pb.PutFailure{Key: st.Key, Error: st.Error})
 }
 
 // 实现 gRPC 服务的 Get 方法
 // 根据 SeqKey 从 HBase 中检索数据
-func (s *server) Get(ctx context.Context, seqKey *pb.SeqKey) (*pb.SeqItem, error) {
-	rowKey := generateRowKey(string(seqKey.BizId), seqKey.Seq) // 生成 RowKey
-	// 创建 HBase Get 请求,根据rowkey查找seqitem
-	getRequest, err := hrpc.NewGetStr(ctx, "my_table", rowKey)
+func (s *server) Get(ctx context.Context, req *pb.GetReq) (*pb.SeqItem, error) {
+	seqKey := &pb.SeqKey{BizId: req.BizId, Seq: req.Seq}
+	versions, versioned, err := readVersions(req.AsOfTimestamp, req.TimeRange, req.MaxVersions)
 	if err != nil {
-		log.Printf("Get request creation failed: %v", err)
-		return nil, err // 返回错误
+		return nil, err
 	}
-	getRsp, err := s.client.Get(getRequest)
-	if err != nil {
-		log.Printf("Get request execution failed: %v", err)
-		return nil, err // 返回错误
+	if versioned {
+		return s.getVersions(ctx, seqKey, versions)
 	}
-	value := getRsp.Cells[0].Value // 获取返回值
 
-	seqItem := &pb.SeqItem{}
-	// 反序列化为seqitem
-	err = proto.Unmarshal(value, seqItem)
+	items, err := s.store.Get(ctx, []*pb.SeqKey{seqKey})
 	if err != nil {
-		log.Printf("Failed to unmarshal SeqItem: %v", err)
-		return nil, err // 返回错误
+		log.Printf("Get request execution failed: %v", err)
+		return nil, apierr.Status(err, seqKey) // 返回错误
+	}
+	seqItem := items[0]
+	if seqItem == nil {
+		return nil, apierr.Status(fmt.Errorf("get %q/%d: %w", seqKey.BizId, seqKey.Seq, apierr.ErrNotFound), seqKey)
 	}
 	log.Println("Get request successful： " + seqItem.String())
 	return seqItem, nil // 返回 SeqItem
 }
 
-// TODO 测试
-// 批量获取
-// 实现 gRPC 服务的 BatchGet 方法
-// 根据多个 SeqKey 从 HBase 中检索数据
-func (s *server) BatchGet(ctx context.Context, seqKeys []*pb.SeqKey) (*pb.SeqItems, error) {
-	// 创建一个批次操作的列表
-	var batch []*hrpc.Get
-
-	// 遍历每个 SeqKey
-	for _, seqKey := range seqKeys {
-		rowKey := generateRowKey(string(seqKey.BizId), seqKey.Seq) // 生成 RowKey
-		// 创建 HBase Get 请求
-		getRequest, err := hrpc.NewGetStr(ctx, "my_table", rowKey)
-		if err != nil {
-			log.Printf("Get request creation failed: %v", err)
-			return nil, err // 返回错误
-		}
-		// 将每个 getRequest 加入到批次列表中
-		batch = append(batch, getRequest)
+// getVersions 读取 seqKey 满足 versions 的版本，返回最新的版本并附带所有版本
+func (s *server) getVersions(ctx context.Context, seqKey *pb.SeqKey, versions store.Versions) (*pb.SeqItem, error) {
+	row, err := s.store.GetRow(ctx, s.codec.Encode(seqKey), versions)
+	if err != nil {
+		log.Printf("Get request execution failed: %v", err)
+		return nil, apierr.Status(err, seqKey) // 返回错误
 	}
+	if row == nil {
+		log.Printf("No version found for key: %v", seqKey)
+		return nil, apierr.Status(fmt.Errorf("get %q/%d: no matching version: %w", seqKey.BizId, seqKey.Seq, apierr.ErrNotFound), seqKey)
+	}
+	item, err := s.decodeRow(row)
+	if err == nil {
+		err = s.decodeVersions(row, item)
+	}
+	if err != nil {
+		log.Printf("Get decode row failed: %v", err)
+		return nil, apierr.Status(err, seqKey)
+	}
+	log.Printf("Get request successful, %d versions", len(item.Versions))
+	return item, nil
+}
 
-	// 创建一个用于存储结果的切片
-	var seqItems []*pb.SeqItem
-
-	// 执行批次操作
-	for _, getRequest := range batch {
-		getRsp, err := s.client.Get(getRequest)
-		if err != nil {
-			log.Printf("Get request execution failed: %v", err)
-			return nil, err // 返回错误
-		}
-		if len(getRsp.Cells) == 0 {
-			log.Printf("No data found for row key: %s", getRequest.Key())
-			continue
-		}
-		value := getRsp.Cells[0].Value // 获取返回值
+// 实现 gRPC 服务的 BatchGet 方法
+// 根据多个 SeqKey 从存储后端检索数据，结果与请求的 key 一一对应并标记是否存在
+func (s *server) BatchGet(ctx context.Context, seqKeys *pb.SeqKeys) (*pb.BatchGetResp, error) {
+	items, err := s.store.Get(ctx, seqKeys.Keys)
+	if err != nil {
+		log.Printf("Get request execution failed: %v", err)
+		return nil, err // 返回错误
+	}
 
-		seqItem := &pb.SeqItem{}
-		// 反序列化为 seqItem
-		err = proto.Unmarshal(value, seqItem)
-		if err != nil {
-			log.Printf("Failed to unmarshal SeqItem: %v", err)
-			return nil, err // 返回错误
+	results := make([]*pb.GetResult, len(seqKeys.Keys))
+	for i, seqKey := range seqKeys.Keys {
+		results[i] = &pb.GetResult{Key: seqKey, Found: items[i] != nil, Item: items[i]}
+		if items[i] == nil {
+			log.Printf("No data found for key: %v", seqKey)
 		}
-		seqItems = append(seqItems, seqItem)
 	}
 
 	log.Println("BatchGet request successful")
-	return &pb.SeqItems{Items: seqItems}, nil // 返回 SeqItems
+	return &pb.BatchGetResp{Results: results}, nil
 }
 
-// 实现 gRPC 服务的 GetMaxKey 方法
-// 获取最大 SeqKey
-func (s *server) GetMaxKey(ctx context.Context, seqKey *pb.SeqKey) (*pb.SeqKey, error) {
-	// 构造范围扫描的开始和结束前缀
-	startPrefix := generateRowKey(string(seqKey.BizId), ^int32(0))
-	endPrefix := generateRowKey(string(seqKey.BizId), int32(0))
+// 实现 gRPC 服务的 PutIfAbsent 方法
+// 只写入当前不存在的 key，已存在的 key 在结果中返回当前值
+func (s *server) PutIfAbsent(ctx context.Context, seqItems *pb.SeqItems) (*pb.ConditionalResp, error) {
+	ttl, err := requestTTL(seqItems.TtlSeconds)
+	if err != nil {
+		return nil, err
+	}
+	results := s.conditionalPut(ctx, seqItems.Items, make([]*pb.SeqItem, len(seqItems.Items)), ttl)
+	log.Println("PutIfAbsent request successful")
+	return &pb.ConditionalResp{Results: results}, nil
+}
 
-	// 执行范围扫描查询
-	scanRequest, err := hrpc.NewScanRange(ctx, []byte("my_table"), []byte(startPrefix), []byte(endPrefix))
+// 实现 gRPC 服务的 CompareAndSwap 方法
+// 只在 key 的当前值等于 expected_value 时写入新值，key 不存在时视为不满足条件
+func (s *server) CompareAndSwap(ctx context.Context, req *pb.CasReq) (*pb.ConditionalResp, error) {
+	ttl, err := requestTTL(req.TtlSeconds)
 	if err != nil {
-		log.Printf("GetMaxKey request creation failed: %v", err)
 		return nil, err
 	}
-	scanner := s.client.Scan(scanRequest)
+	items := make([]*pb.SeqItem, len(req.Items))
+	expected := make([]*pb.SeqItem, len(req.Items))
+	for i, casItem := range req.Items {
+		items[i] = casItem.Item
+		// 存储的是序列化的 SeqItem，期望值按同样的方式构造后比较
+		expected[i] = &pb.SeqItem{Key: casItem.GetItem().GetKey(), Value: casItem.ExpectedValue}
+	}
+	results := s.conditionalPut(ctx, items, expected, ttl)
+	log.Println("CompareAndSwap request successful")
+	return &pb.ConditionalResp{Results: results}, nil
+}
 
-	var maxSeqKey *pb.SeqKey
-	// 迭代扫描结果
-	for {
-		res, err := scanner.Next()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			log.Printf("GetMaxKey scan result error: %v", err)
-			return nil, err
+// conditionalPut 并发地对每个 item 执行 CheckAndPut，expected[i] 为 nil 表示要求 key 不存在
+// 每个 item 的结果单独返回，条件不满足时附带当前值；ttl 为请求的 TTL，见 retention.Policies.TTL
+func (s *server) conditionalPut(ctx context.Context, items, expected []*pb.SeqItem, ttl time.Duration) []*pb.ConditionalResult {
+	results := make([]*pb.ConditionalResult, len(items))
+	var wg sync.WaitGroup
+	for i, item := range items {
+		results[i] = &pb.ConditionalResult{Key: item.GetKey()}
+		if item.GetKey() == nil {
+			results[i].Error = errMissingKey.Error()
+			continue
 		}
-		for _, cell := range res.Cells {
-			// 反序列化 cell.Value 成 SeqItem
-			seqItem := &pb.SeqItem{}
-			err = proto.Unmarshal(cell.Value, seqItem)
+		wg.Add(1)
+		go func(result *pb.ConditionalResult, item, expected *pb.SeqItem) {
+			defer wg.Done()
+			ts, err := s.store.CheckAndPut(ctx, item, expected, s.retention.TTL(item.Key.BizId, ttl))
 			if err != nil {
-				log.Printf("Failed to unmarshal SeqItem: %v", err)
-				continue
+				log.Printf("CheckAndPut %v failed: %v", item.Key, err)
+				result.Error = err.Error()
+				return
 			}
-
-			// 从 SeqItem 中获取 SeqKey
-			seqKey := seqItem.GetKey()
-			if seqKey == nil {
-				log.Printf("SeqItem does not contain SeqKey")
-				continue
+			result.Applied = ts != 0
+			if result.Applied {
+				s.publish(ctx, []*pb.SeqItem{item}, []*pb.PutStatus{{Key: item.Key, Timestamp: ts}})
+				return
 			}
-
-			// 比较 SeqKey 的 seq 值
-			if maxSeqKey == nil || seqKey.GetSeq() > maxSeqKey.GetSeq() {
-				maxSeqKey = seqKey
+			current, err := s.store.Get(ctx, []*pb.SeqKey{item.Key})
+			if err != nil {
+				log.Printf("Get current value of %v failed: %v", item.Key, err)
+				return
 			}
-		}
+			result.Current = current[0]
+		}(results[i], item, expected[i])
 	}
+	wg.Wait()
+	return results
+}
 
+// 实现 gRPC 服务的 GetMaxKey 方法
+// 获取最大 SeqKey
+func (s *server) GetMaxKey(ctx context.Context, seqKey *pb.SeqKey) (*pb.SeqKey, error) {
+	maxSeqKey, err := s.edgeSeqKey(ctx, seqKey.BizId, true)
+	if err != nil {
+		return nil, apierr.Status(err, seqKey)
+	}
 	if maxSeqKey == nil {
 		log.Println("GetMaxKey request found no matching cells")
-		return nil, fmt.Errorf("no matching cells found")
+		return nil, apierr.Status(fmt.Errorf("no seq found for biz %q: %w", seqKey.BizId, apierr.ErrNotFound), seqKey)
 	}
 
 	log.Println("GetMaxKey request successful: " + maxSeqKey.String())
 	return maxSeqKey, nil
 }
 
-// 辅助方法：根据RangeOption处理区间，生成边界rowkey
-func generateQueryRangeKeys(req *pb.RangeReq) (startRowKey string, endRowKey string) {
-	// 由于NewScanRangeStr方法默认为左闭右开，左开和右闭的时候需要处理
-	// 左开：withoutboth withoustart 右闭：withoutstart withboth
+// 实现 gRPC 服务的 GetMinKey 方法
+// 获取最小 SeqKey
+func (s *server) GetMinKey(ctx context.Context, seqKey *pb.SeqKey) (*pb.SeqKey, error) {
+	minSeqKey, err := s.edgeSeqKey(ctx, seqKey.BizId, false)
+	if err != nil {
+		return nil, apierr.Status(err, seqKey)
+	}
+	if minSeqKey == nil {
+		log.Println("GetMinKey request found no matching cells")
+		return nil, apierr.Status(fmt.Errorf("no seq found for biz %q: %w", seqKey.BizId, apierr.ErrNotFound), seqKey)
+	}
+
+	log.Println("GetMinKey request successful: " + minSeqKey.String())
+	return minSeqKey, nil
+}
 
-	// 顺序时startRowKey<endRowKey, 倒序时startRowKey>endRowKey
+// 实现 gRPC 服务的 GetKeyCount 方法
+// 统计 BizId 的行数，只扫描 rowkey 不读取 value
+func (s *server) GetKeyCount(ctx context.Context, seqKey *pb.SeqKey) (*pb.KeyCountResp, error) {
+	rng := seqrange.Biz(s.codec, seqKey.BizId, false)
+	rng.KeysOnly = true
+	scanner, err := s.store.Scan(ctx, rng)
+	if err != nil {
+		log.Printf("GetKeyCount scan request creation failed: %v", err)
+		return nil, apierr.Status(err, seqKey)
+	}
+	defer scanner.Close()
 
-	if req.Reverse == false { // 顺序
-		if req.Option == pb.RangeOption_WithoutStart || req.Option == pb.RangeOption_WithoutBoth {
-			// 左开区间，startRowkey+1
-			startRowKey = generateRowKey(string(req.Start.BizId), req.Start.Seq-1)
-			endRowKey = generateRowKey(string(req.End.BizId), req.End.Seq)
+	var count int64
+	for {
+		_, err := scanner.Next()
+		if err == io.EOF {
+			break
 		}
-		if req.Option == pb.RangeOption_WithoutStart || req.Option == pb.RangeOption_WithBoth {
-			// 右闭区间，endRowkey+1
-			startRowKey = generateRowKey(string(req.Start.BizId), req.Start.Seq)
-			endRowKey = generateRowKey(string(req.End.BizId), req.End.Seq-1)
+		if err != nil {
+			log.Printf("GetKeyCount scanner next failed: %v", err)
+			return nil, apierr.Status(err, seqKey)
 		}
-		if req.Option == pb.RangeOption_WithoutEnd {
-			// 左闭右开，无需处理
-			startRowKey = generateRowKey(string(req.Start.BizId), req.Start.Seq)
-			endRowKey = generateRowKey(string(req.End.BizId), req.End.Seq)
+		count++
+	}
+	log.Printf("GetKeyCount request successful: %d", count)
+	return &pb.KeyCountResp{BizId: seqKey.BizId, Count: count}, nil
+}
+
+// edgeSeqKey 返回 bizID 下 seq 最大（max 为 false 时最小）的 SeqKey，没有数据时返回 nil
+//
+// seqrange.Bounds 的结果从 Start 向 End 排列，因此以目标端点为 Start 的区间只需扫描一行。
+// seq 按非负和负数分成两个半区依次查找，使 Legacy 编码（按 uint32 排列，负数 seq 排在最大的正数之前）同样适用；
+// 最多两次单行扫描
+func (s *server) edgeSeqKey(ctx context.Context, bizID []byte, max bool) (*pb.SeqKey, error) {
+	halves := [][2]int32{{math.MaxInt32, 0}, {-1, math.MinInt32}}
+	if !max {
+		halves = [][2]int32{{math.MinInt32, -1}, {0, math.MaxInt32}}
+	}
+	for _, half := range halves {
+		rng, _, err := seqrange.Bounds(s.codec, &pb.RangeReq{
+			Start: &pb.SeqKey{BizId: bizID, Seq: half[0]},
+			End:   &pb.SeqKey{BizId: bizID, Seq: half[1]},
+		})
+		if err != nil {
+			return nil, err
 		}
-	} else if req.Reverse == true { // 倒序
-		if req.Option == pb.RangeOption_WithoutStart || req.Option == pb.RangeOption_WithoutBoth {
-			// 左开，startRowkey-1
-			endRowKey = generateRowKey(string(req.Start.BizId), req.Start.Seq)
-			startRowKey = generateRowKey(string(req.End.BizId), req.End.Seq+1)
+		rng.Limit = 1
+		row, err := seqrange.FirstRow(ctx, s.store, rng)
+		if err != nil {
+			log.Printf("edgeSeqKey scan failed: %v", err)
+			return nil, err
 		}
-		if req.Option == pb.RangeOption_WithoutStart || req.Option == pb.RangeOption_WithBoth {
-			// 右闭，endRowkey-1
-			endRowKey = generateRowKey(string(req.Start.BizId), req.Start.Seq+1)
-			startRowKey = generateRowKey(string(req.End.BizId), req.End.Seq)
+		if row == nil {
+			continue
 		}
-		if req.Option == pb.RangeOption_WithoutEnd {
-			// 左闭右开，无需处理
-			endRowKey = generateRowKey(string(req.Start.BizId), req.Start.Seq)
-			startRowKey = generateRowKey(string(req.End.BizId), req.End.Seq)
+		item, err := s.decodeRow(row)
+		if err != nil {
+			return nil, err
 		}
+		return item.Key, nil
 	}
+	return nil, nil
+}
 
-	// TODO
-	// startKey := []byte("startKey")
-	// endKey := []byte("endKey")
+// 实现 gRPC 服务的 AllocateSeq 方法
+// 为 BizId 原子地预留 count 个连续的 seq
+// 计数器存放在 BizId 的 rowkey 前缀行（rowkey.Codec.Prefix），不在任何 Seq 区间内，
+// 值为该 BizId 已分配的最大 seq，每次分配通过 Increment 预留 [新值-count+1, 新值]
+func (s *server) AllocateSeq(ctx context.Context, req *pb.AllocateSeqReq) (*pb.AllocateSeqResp, error) {
+	if req.Count <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "count must be positive, got %d", req.Count)
+	}
+	counterRow := s.codec.Prefix(req.BizId)
+	if err := s.initCounter(ctx, req.BizId, counterRow); err != nil {
+		log.Printf("AllocateSeq init counter failed: %v", err)
+		return nil, err
+	}
 
-	// startKeyWithZero := append(startKey, "\\0")
-	// endKeyWithZero := append(endKey, "\\0")
+	last, err := s.store.Increment(ctx, counterRow, int64(req.Count))
+	if err != nil {
+		log.Printf("AllocateSeq increment failed: %v", err)
+		return nil, err
+	}
+	if last > math.MaxInt32 {
+		return nil, status.Errorf(codes.ResourceExhausted, "seq space of biz %q exhausted", req.BizId)
+	}
 
-	// if req.Option == pb.RangeOption_WithoutBoth{
-	// 	startRowKey = startKeyWithZero;
-	// 	endRowKey = endKey;
-	// }
+	resp := &pb.AllocateSeqResp{BizId: req.BizId, First: int32(last) - req.Count + 1, Last: int32(last)}
+	log.Printf("AllocateSeq request successful: %v", resp)
+	return resp, nil
+}
 
-	return startRowKey, endRowKey
+// initCounter 在计数器不存在时以已有数据的最大 seq（至少为 0）初始化，
+// 多个实例同时初始化时由 CheckAndPut 保证只有一个生效；初始化过的计数器行记录在 s.counters 中，不再重复检查
+// 不同租户的计数器行位于不同的表中，记录时加上 namespace 区分
+func (s *server) initCounter(ctx context.Context, bizID, counterRow []byte) error {
+	counterKey := tenant.FromContext(ctx) + ":" + string(counterRow)
+	if _, ok := s.counters.Load(counterKey); ok {
+		return nil
+	}
+	maxSeqKey, err := s.edgeSeqKey(ctx, bizID, true)
+	if err != nil {
+		return err
+	}
+	var value int64
+	if maxSeqKey != nil && maxSeqKey.Seq > 0 {
+		value = int64(maxSeqKey.Seq)
+	}
+	if _, err := s.store.InitCounter(ctx, counterRow, value); err != nil {
+		return err
+	}
+	s.counters.Store(counterKey, struct{}{})
+	return nil
+}
+
+// queryRange 根据 RangeOption 计算 req 的扫描范围，区间为空时 ok 为 false
+func (s *server) queryRange(req *pb.RangeReq) (rng store.Range, ok bool, err error) {
+	rng, ok, err = seqrange.Bounds(s.codec, req)
+	if err != nil {
+		return rng, false, status.Error(codes.InvalidArgument, err.Error())
+	}
+	log.Printf("range startRowKey: %q, stopRowKey: %q, reverse: %v, empty: %v", rng.StartRow, rng.StopRow, rng.Reverse, !ok)
+	return rng, ok, nil
 }
 
 // 实现 gRPC 服务的 QueryRange 方法
-// 根据范围请求检索 SeqItems
+// 查询指定范围的 SeqItems，req.Limit 大于 0 时分页返回
 func (s *server) QueryRange(ctx context.Context, req *pb.RangeReq) (*pb.SeqItems, error) {
-	// 根据RangeOption生成边界rowkey
-	startRowKey, endRowKey := generateQueryRangeKeys(req)
+	items := []*pb.SeqItem{}
+	nextPageToken, err := s.scanRange(ctx, req, func(item *pb.SeqItem) error {
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err // 返回错误
+	}
 
-	log.Printf("QueryRange startRowKey: %s, endRowKey: %s", startRowKey, endRowKey)
+	log.Printf("QueryRange request successful, %d items", len(items))
+	return &pb.SeqItems{Items: items, NextPageToken: nextPageToken}, nil // 返回 SeqItems
+}
 
-	// 创建扫描请求
-	var scanRequest *hrpc.Scan
-	var err error
+// 实现 gRPC 服务的 StreamRange 方法
+// 逐条发送指定范围的 SeqItems，服务端同一时刻只持有一页扫描结果
+func (s *server) StreamRange(req *pb.RangeReq, stream pb.SeqDb_StreamRangeServer) error {
+	_, err := s.scanRange(stream.Context(), req, stream.Send)
+	if err != nil {
+		return err
+	}
+	log.Println("StreamRange request successful")
+	return nil
+}
 
-	if req.Reverse {
-		scanRequest, err = hrpc.NewScanRangeStr(ctx, "my_table", startRowKey, endRowKey, hrpc.Reversed(), hrpc.NumberOfRows(10))
-	} else {
-		scanRequest, err = hrpc.NewScanRangeStr(ctx, "my_table", startRowKey, endRowKey)
+// scanRange 扫描 req 描述的区间，按顺序对每个 SeqItem 调用 fn
+// req.Limit 大于 0 时最多处理 Limit 条，区间内还有数据时返回下一页的 page_token
+// 跨 BizId 的查询扫描每个 BizId 的区间，Limit 和 page_token 作用于按 (BizId, seq) 归并后的结果
+func (s *server) scanRange(ctx context.Context, req *pb.RangeReq, fn func(*pb.SeqItem) error) ([]byte, error) {
+	if req.Limit < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must not be negative, got %d", req.Limit)
+	}
+	versions, versioned, err := readVersions(req.AsOfTimestamp, req.TimeRange, req.MaxVersions)
+	if err != nil {
+		return nil, err
 	}
 
+	// 根据RangeOption生成边界rowkey
+	ranges, err := s.scanRanges(ctx, req)
 	if err != nil {
-		log.Printf("QueryRange scan request creation failed: %v", err)
-		return nil, err // 返回错误
+		return nil, err
+	}
+	if len(req.PageToken) > 0 && len(ranges) > 0 {
+		if ranges, err = resumeRanges(ranges, req.PageToken); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 	}
 
-	scanner := s.client.Scan(scanRequest)
-	items := []*pb.SeqItem{}
-	for {
-		res, err := scanner.Next()
+	// 每个盐值桶并行扫描，按 (BizId, seq) 归并；单个 BizId 的查询只有一个数据源
+	limit := 0
+	if req.Limit > 0 {
+		limit = int(req.Limit) + 1 // 多取一行，用于判断是否还有下一页
+	}
+	for i := range ranges {
+		ranges[i].Versions = versions
+	}
+	scanner := fanout.Scan(ctx, s.store, fanout.Group(s.codec, ranges), fanout.ByKey(s.codec, req.Reverse), fanout.Options{Limit: limit})
+	defer scanner.Close()
+
+	for n := 0; ; n++ {
+		row, err := scanner.Next()
+		if err == io.EOF {
+			return nil, nil // 扫描结束
+		}
 		if err != nil {
-			log.Printf("QueryRange scanner next failed: %v", err)
-			if err.Error() == "EOF" {
-				break // 扫描结束
-			}
-			return nil, err // 返回错误
+			log.Printf("scanRange scanner next failed: %v", err)
+			return nil, err
 		}
-		if res == nil {
-			log.Println("QueryRange scanner reached end of results")
-			break // 扫描结束
+		if req.Limit > 0 && n == int(req.Limit) {
+			return encodePageToken(row.Key), nil
+		}
+		item, err := s.decodeRow(row)
+		if err == nil && versioned {
+			err = s.decodeVersions(row, item)
+		}
+		if err != nil {
+			log.Printf("scanRange decode row failed: %v", err)
+			return nil, err
 		}
-		for _, cell := range res.Cells {
-			log.Printf("QueryRange found cell: %s", cell.Row)
-			items = append(items, &pb.SeqItem{
-				Key:   &pb.SeqKey{BizId: []byte(cell.Row), Seq: req.Start.Seq}, // 假设 seqKey 一致
-				Value: cell.Value,
-			})
+		if err := fn(item); err != nil {
+			return nil, err
 		}
 	}
+}
 
-	log.Println("QueryRange request successful")
-	// 打印每个 SeqItem
-	for _, item := range items {
-		log.Printf("SeqItem: Key=%s, Value=%s", item.Key.BizId, string(item.Value))
+// scanRanges 返回 req 的扫描范围，按结果顺序排列；跨 BizId 的查询每个 BizId 一个范围，跳过空区间
+func (s *server) scanRanges(ctx context.Context, req *pb.RangeReq) ([]store.Range, error) {
+	if !seqrange.IsMulti(req) {
+		rng, ok, err := s.queryRange(req)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return []store.Range{rng}, nil
+	}
+	bizIDs, err := seqrange.MultiBizIDs(ctx, s.store, s.codec, req)
+	if err != nil {
+		log.Printf("scanRange list biz ids failed: %v", err)
+		return nil, err
 	}
-	return &pb.SeqItems{Items: items}, nil // 返回 SeqItems
+	var ranges []store.Range
+	for _, bizID := range bizIDs {
+		rng, ok, err := seqrange.MultiBounds(s.codec, req, bizID)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if ok {
+			ranges = append(ranges, rng)
+		}
+	}
+	log.Printf("range across %d biz ids, %d non-empty", len(bizIDs), len(ranges))
+	return ranges, nil
 }
 
+const (
+	deleteBatchSize   = 1000 // DeleteRange 每次 Delete 删除的行数
+	deleteConcurrency = 4    // DeleteRange 同时进行的 Delete 请求数
+)
+
 // 实现 gRPC 服务的 DeleteRange 方法
-// 删除指定范围的 SeqItems
+// 删除指定范围的 SeqItems，只扫描 rowkey 并分批删除；dry_run 时只统计，max_rows 限制删除的行数
 func (s *server) DeleteRange(ctx context.Context, req *pb.RangeReq) (*pb.DelRangeResp, error) {
+	if req.MaxRows < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "max_rows must not be negative: %d", req.MaxRows)
+	}
+	if seqrange.IsMulti(req) {
+		return nil, status.Error(codes.InvalidArgument, "DeleteRange does not support biz_ids or biz_prefix")
+	}
 	// 根据 RangeOption 处理区间
-	startRowKey, endRowKey := generateQueryRangeKeys(req)
-	log.Printf("DeleteRange startRowKey: %s, endRowKey: %s", startRowKey, endRowKey)
-
-	// 创建扫描请求
-	var scanRequest *hrpc.Scan
-	var err error
-
-	if req.Reverse {
-		// For reverse scanning, swap start and end keys and process results in reverse
-		scanRequest, err = hrpc.NewScanRangeStr(ctx, "my_table", startRowKey, endRowKey, hrpc.Reversed())
-	} else {
-		scanRequest, err = hrpc.NewScanRangeStr(ctx, "my_table", startRowKey, endRowKey)
+	rng, ok, err := s.queryRange(req)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &pb.DelRangeResp{}, nil // 空区间
+	}
+	rng.KeysOnly = true
+	if req.MaxRows > 0 {
+		rng.Limit = int(req.MaxRows) + 1 // 多取一行判断是否还有未删除的行
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// 创建扫描请求
+	scanner, err := s.store.Scan(ctx, rng)
 	if err != nil {
 		log.Printf("DeleteRange scan request creation failed: %v", err)
 		return nil, err // 返回错误
 	}
+	defer scanner.Close()
+
+	// 扫描到的 rowkey 按 deleteBatchSize 分批，最多 deleteConcurrency 批同时删除，任一批失败时停止
+	var (
+		wg        sync.WaitGroup
+		sem       = make(chan struct{}, deleteConcurrency)
+		errOnce   sync.Once
+		deleteErr error
+		batch     [][]byte
+	)
+	flush := func() {
+		if len(batch) == 0 || req.DryRun {
+			return
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(rowKeys [][]byte) {
+			defer func() { <-sem; wg.Done() }()
+			if err := s.store.Delete(ctx, rowKeys); err != nil {
+				errOnce.Do(func() { deleteErr = err; cancel() })
+			}
+		}(batch)
+		batch = nil
+	}
 
-	scanner := s.client.Scan(scanRequest)
-	for {
-		res, err := scanner.Next()
+	resp := &pb.DelRangeResp{}
+	var scanErr error
+	for ctx.Err() == nil {
+		row, err := scanner.Next()
+		if err == io.EOF {
+			break // 扫描结束
+		}
 		if err != nil {
-			if err.Error() == "EOF" {
-				log.Println("DeleteRange scanner reached end of results")
-				break // 扫描结束
-			}
-			log.Printf("DeleteRange scanner next failed: %v", err)
-			return nil, err // 返回错误
+			scanErr = err
+			break
 		}
-		if res == nil {
-			log.Println("DeleteRange scanner reached end of results")
-			break // 扫描结束
+		if req.MaxRows > 0 && resp.Deleted == req.MaxRows {
+			resp.Truncated = true
+			break
 		}
-		for _, cell := range res.Cells {
-			log.Printf("DeleteRange found cell to delete: %s", cell.Row)
-			deleteRequest, err := hrpc.NewDelStr(ctx, "my_table", string(cell.Row), nil)
-			if err != nil {
-				log.Printf("DeleteRange delete request creation failed: %v", err)
-				return nil, err // 返回错误
-			}
-			_, err = s.client.Delete(deleteRequest)
-			if err != nil {
-				log.Printf("DeleteRange delete request execution failed: %v", err)
-				return nil, err // 返回错误
-			}
+		// 无法解码的 rowkey 只记录原始 rowkey
+		key, _ := s.decodeKey(row.Key)
+		if resp.Deleted == 0 {
+			resp.FirstKey, resp.FirstRowKey = key, row.Key
+		}
+		resp.LastKey, resp.LastRowKey = key, row.Key
+		resp.Deleted++
+		if batch = append(batch, row.Key); len(batch) == deleteBatchSize {
+			flush()
 		}
 	}
-	log.Println("DeleteRange request successful")
-	return &pb.DelRangeResp{}, nil // 返回空的响应
+	if scanErr == nil {
+		flush()
+	}
+	wg.Wait()
+
+	if deleteErr != nil {
+		log.Printf("DeleteRange delete request execution failed: %v", deleteErr)
+		return nil, deleteErr // 返回错误
+	}
+	if scanErr != nil {
+		log.Printf("DeleteRange scanner next failed: %v", scanErr)
+		return nil, scanErr // 返回错误
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	log.Printf("DeleteRange request successful, %d rows deleted, dry run: %v, truncated: %v", resp.Deleted, req.DryRun, resp.Truncated)
+	return resp, nil
 }
 
 // 主函数，启动 gRPC 服务器
 func main() {
-	lis, err := net.Listen("tcp", ":30060") // 创建一个 TCP 监听器，监听端口30020
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	lis, err := net.Listen("tcp", cfg.Server.Listen) // 创建一个 TCP 监听器
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err) // 监听失败，记录错误日志并退出
 	}
 
-	s := grpc.NewServer()                  // 创建一个新的 gRPC 服务器实例
-	pb.RegisterSeqDbServer(s, NewServer()) // 注册 SeqDb 服务到 gRPC 服务器
+	srv, err := NewServer(cfg)
+	if err != nil {
+		log.Fatalf("failed to create server: %v", err) // 连接存储后端失败，记录错误日志并退出
+	}
+	defer srv.store.Close()
+
+	// 后台清理超出 max_seqs 的数据，指标见 debug_listen 的 /debug/vars；清理默认表和本进程访问过的各租户表
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go retention.NewReaper(srv.store, srv.codec, srv.retention, cfg.Retention.ReapInterval).Run(ctx)
+	if cfg.Server.DebugListen != "" {
+		go func() {
+			if err := http.ListenAndServe(cfg.Server.DebugListen, nil); err != nil {
+				log.Printf("debug server stopped: %v", err)
+			}
+		}()
+	}
 
-	fmt.Println("Server is running at :30060") // 打印服务器启动信息
+	var opts []grpc.ServerOption
+	if tlsCfg := cfg.Server.TLS; tlsCfg.CertFile != "" && tlsCfg.KeyFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(tlsCfg.CertFile, tlsCfg.KeyFile)
+		if err != nil {
+			log.Fatalf("failed to load TLS credentials: %v", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	// 从请求 metadata 中读取租户 namespace，校验请求，并将 handler 返回的存储后端错误转换为 gRPC status
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(tenant.UnaryServerInterceptor(), validate.UnaryServerInterceptor(), apierr.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(tenant.StreamServerInterceptor(), validate.StreamServerInterceptor(), apierr.StreamServerInterceptor()),
+	)
+	s := grpc.NewServer(opts...)   // 创建一个新的 gRPC 服务器实例
+	pb.RegisterSeqDbServer(s, srv) // 注册 SeqDb 服务到 gRPC 服务器
+
+	fmt.Printf("Server is running at %s\n", cfg.Server.Listen) // 打印服务器启动信息
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err) // 服务器启动失败，记录错误日志并退出
 	}
@@ -1,14 +1,39 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"go-hbase-demo/apierr"
 	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/config"
+	"go-hbase-demo/dial"
+	"go-hbase-demo/hbasetest"
+	"go-hbase-demo/retention"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/store"
+	"go-hbase-demo/tenant"
+	"go-hbase-demo/validate"
+	"go-hbase-demo/watch"
+	"io"
+	"math"
+	"net"
 	"reflect"
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/tsuna/gohbase"
 	"github.com/tsuna/gohbase/hrpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -113,7 +138,8 @@ func (m *MockHBaseClient) Scan(scan *hrpc.Scan) hrpc.Scanner {
 // 测试 Put 方法
 func TestPut(t *testing.T) {
 	mockClient := new(MockHBaseClient) // 创建一个 MockHBaseClient 实例
-	s := &server{client: mockClient}   // 使用 MockHBaseClient 创建 server 实例
+	// 使用 MockHBaseClient 创建 server 实例
+	s := &server{store: store.NewHBaseStore(mockClient, storeOptions), codec: rowkey.Legacy{}}
 
 	// 模拟 SeqItem，包含多个项
 	seqItems := &pb.SeqItems{
@@ -176,7 +202,7 @@ func TestPut(t *testing.T) {
 
 	mockClient.On("Put", mock.Anything).Return(&hrpc.Result{}, nil).Run(func(args mock.Arguments) {
 		put := args.Get(0).(*hrpc.Mutate) // 获取传入的 hrpc.Mutate 对象
-		expectedRowKey := generateRowKey("biz2", 2)
+		expectedRowKey := generateRowKey("biz1", 2)
 		if string(put.Key()) != expectedRowKey {
 			t.Errorf("Expected row key %v, got %v", expectedRowKey, string(put.Key()))
 		}
@@ -187,6 +213,7 @@ func TestPut(t *testing.T) {
 			t.Errorf("Expected data %v, got %v", expectedData, put.Values())
 		}
 	}).Once()
+	mockClient.On("Put", mock.Anything).Return(&hrpc.Result{}, nil) // 其余 item 的写入，逐个校验见 TestPut_AllItems
 
 	// 调用被测试的 Put 方法
 	_, err = s.Put(context.Background(), seqItems)
@@ -197,6 +224,127 @@ func TestPut(t *testing.T) {
 	mockClient.AssertExpectations(t) // 确保所有预期的模拟行为都已被调用
 }
 
+// 每个 SeqItem 按顺序写入一次，携带预期的 rowkey 和数据
+func TestPut_AllItems(t *testing.T) {
+	mockClient := new(MockHBaseClient) // 创建一个 MockHBaseClient 实例
+	// 使用 MockHBaseClient 创建 server 实例
+	s := &server{store: store.NewHBaseStore(mockClient, storeOptions), codec: rowkey.Legacy{}}
+
+	seqItems := &pb.SeqItems{Items: []*pb.SeqItem{
+		{Key: &pb.SeqKey{BizId: []byte("biz1"), Seq: 1}, Value: []byte("value1")},
+		{Key: &pb.SeqKey{BizId: []byte("biz1"), Seq: 2}, Value: []byte("value2")},
+		{Key: &pb.SeqKey{BizId: []byte("biz2"), Seq: 1}, Value: []byte("value3")},
+		{Key: &pb.SeqKey{BizId: []byte("biz1"), Seq: 4}, Value: []byte("value4")},
+	}}
+
+	// 设置模拟的行为，确保每个 SeqItem 按顺序触发一次 Put 并携带预期的 rowkey 和数据
+	for _, item := range seqItems.Items {
+		data, err := proto.Marshal(item) // 序列化 SeqItem 的数据
+		if err != nil {
+			t.Fatalf("Failed to marshal SeqItem: %v", err)
+		}
+		expectedRowKey := generateRowKey(string(item.Key.BizId), item.Key.Seq)
+		mockClient.On("Put", mock.Anything).Return(&hrpc.Result{}, nil).Run(func(args mock.Arguments) {
+			put := args.Get(0).(*hrpc.Mutate) // 获取传入的 hrpc.Mutate 对象
+			if string(put.Key()) != expectedRowKey {
+				t.Errorf("Expected row key %v, got %v", expectedRowKey, string(put.Key()))
+			}
+			expectedData := map[string]map[string][]byte{
+				"cf": {"value": data},
+			}
+			if !comparePutData(put, expectedData) { // 比较实际数据与预期数据
+				t.Errorf("Expected data %v, got %v", expectedData, put.Values())
+			}
+		}).Once() // 确保此模拟行为仅执行一次
+	}
+
+	// 调用被测试的 Put 方法
+	_, err := s.Put(context.Background(), seqItems)
+	if err != nil {
+		t.Fatalf("Put method failed: %v", err)
+	}
+
+	mockClient.AssertExpectations(t) // 确保所有预期的模拟行为都已被调用
+}
+
+// 测试 BatchPut 方法
+func TestBatchPut(t *testing.T) {
+	mockClient := new(MockHBaseClient)
+	s := &server{store: store.NewHBaseStore(mockClient, storeOptions), codec: rowkey.Legacy{}}
+
+	seqItemsList := &pb.SeqItemsList{
+		ItemsList: []*pb.SeqItems{
+			{Items: []*pb.SeqItem{
+				{Key: &pb.SeqKey{BizId: []byte("biz1"), Seq: 1}, Value: []byte("value1")},
+				{Key: &pb.SeqKey{BizId: []byte("biz1"), Seq: 2}, Value: []byte("value2")},
+			}},
+			{Items: []*pb.SeqItem{
+				{Key: &pb.SeqKey{BizId: []byte("biz2"), Seq: 1}, Value: []byte("value3")},
+			}},
+		},
+	}
+
+	// Put 请求并发发出，只校验写入的 rowkey 集合
+	var mu sync.Mutex
+	gotRowKeys := map[string]bool{}
+	mockClient.On("Put", mock.Anything).Return(&hrpc.Result{}, nil).Run(func(args mock.Arguments) {
+		put := args.Get(0).(*hrpc.Mutate)
+		mu.Lock()
+		gotRowKeys[string(put.Key())] = true
+		mu.Unlock()
+	}).Times(3)
+
+	_, err := s.BatchPut(context.Background(), seqItemsList)
+	if err != nil {
+		t.Fatalf("BatchPut method failed: %v", err)
+	}
+	for _, seqItems := range seqItemsList.ItemsList {
+		for _, item := range seqItems.Items {
+			rowKey := generateRowKey(string(item.Key.BizId), item.Key.Seq)
+			if !gotRowKeys[rowKey] {
+				t.Errorf("Expected row key %v to be written", rowKey)
+			}
+		}
+	}
+	mockClient.AssertExpectations(t)
+}
+
+// 测试 BatchGet 方法：存在的 key 返回数据，不存在的 key 标记为未找到
+func TestBatchGet(t *testing.T) {
+	mockClient := new(MockHBaseClient)
+	s := &server{store: store.NewHBaseStore(mockClient, storeOptions), codec: rowkey.Legacy{}}
+
+	found := &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte("biz1"), Seq: 1}, Value: []byte("value1")}
+	data, err := proto.Marshal(found)
+	if err != nil {
+		t.Fatalf("Failed to marshal SeqItem: %v", err)
+	}
+	missing := &pb.SeqKey{BizId: []byte("biz1"), Seq: 2}
+
+	getByRowKey := func(rowKey string) interface{} {
+		return mock.MatchedBy(func(get *hrpc.Get) bool { return string(get.Key()) == rowKey })
+	}
+	mockClient.On("Get", getByRowKey(generateRowKey("biz1", 1))).
+		Return(&hrpc.Result{Cells: []*hrpc.Cell{{Value: data}}}, nil).Once()
+	mockClient.On("Get", getByRowKey(generateRowKey("biz1", 2))).
+		Return(&hrpc.Result{}, nil).Once()
+
+	got, err := s.BatchGet(context.Background(), &pb.SeqKeys{Keys: []*pb.SeqKey{found.Key, missing}})
+	if err != nil {
+		t.Fatalf("BatchGet method failed: %v", err)
+	}
+	if len(got.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(got.Results))
+	}
+	if !got.Results[0].Found || !proto.Equal(got.Results[0].Item, found) {
+		t.Errorf("Expected first key found with %v, got %v", found, got.Results[0])
+	}
+	if got.Results[1].Found || !proto.Equal(got.Results[1].Key, missing) {
+		t.Errorf("Expected second key missing, got %v", got.Results[1])
+	}
+	mockClient.AssertExpectations(t)
+}
+
 // comparePutData 比较 HBase Put 请求的数据
 func comparePutData(put *hrpc.Mutate, expectedData map[string]map[string][]byte) bool {
 	for family, cols := range expectedData { // 遍历每个列族
@@ -206,83 +354,1331 @@ func comparePutData(put *hrpc.Mutate, expectedData map[string]map[string][]byte)
 			}
 		}
 	}
-	return true
+	return true
+}
+
+// newTestItem 构造测试用的 SeqItem
+func newTestItem(bizID string, seq int32) *pb.SeqItem {
+	return &pb.SeqItem{
+		Key:   &pb.SeqKey{BizId: []byte(bizID), Seq: seq},
+		Value: []byte(fmt.Sprintf("%s-value%d", bizID, seq)),
+	}
+}
+
+// newFakeClient 创建写入了 items 的内存 HBase
+func newFakeClient(t *testing.T, items ...*pb.SeqItem) *hbasetest.Client {
+	t.Helper()
+	client := hbasetest.NewClient()
+	if len(items) > 0 {
+		if _, err := store.NewHBaseStore(client, storeOptions).Put(context.Background(), items, 0); err != nil {
+			t.Fatalf("Failed to seed items: %v", err)
+		}
+	}
+	return client
+}
+
+// newRawFakeClient 创建只含一行的 hbasetest.Client，value 原样写入，用于模拟其他程序写入的数据
+func newRawFakeClient(t *testing.T, rowKey string, value []byte) *hbasetest.Client {
+	t.Helper()
+	client := hbasetest.NewClient()
+	put, err := hrpc.NewPutStr(context.Background(), storeOptions.Table, rowKey, map[string]map[string][]byte{
+		storeOptions.Family: {storeOptions.Qualifier: value},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Put(put); err != nil {
+		t.Fatalf("Failed to seed row: %v", err)
+	}
+	return client
+}
+
+// mustMarshal 序列化 SeqItem，用于构造存储中的原始值
+func mustMarshal(t *testing.T, item *pb.SeqItem) []byte {
+	t.Helper()
+	data, err := proto.Marshal(item)
+	if err != nil {
+		t.Fatalf("Failed to marshal SeqItem: %v", err)
+	}
+	return data
+}
+
+func Test_server_Get(t *testing.T) {
+	type fields struct {
+		UnimplementedSeqDbServer pb.UnimplementedSeqDbServer
+		client                   gohbase.Client
+	}
+	type args struct {
+		ctx context.Context
+		req *pb.GetReq
+	}
+	tests := []struct {
+		name     string
+		fields   fields
+		args     args
+		want     *pb.SeqItem
+		wantCode codes.Code
+	}{
+		{
+			name:   "existing key",
+			fields: fields{client: newFakeClient(t, newTestItem("biz1", 1), newTestItem("biz1", 2))},
+			args:   args{ctx: context.Background(), req: &pb.GetReq{BizId: []byte("biz1"), Seq: 2}},
+			want:   newTestItem("biz1", 2),
+		},
+		{
+			name:     "missing key",
+			fields:   fields{client: newFakeClient(t, newTestItem("biz1", 1))},
+			args:     args{ctx: context.Background(), req: &pb.GetReq{BizId: []byte("biz1"), Seq: 2}},
+			wantCode: codes.NotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{
+				UnimplementedSeqDbServer: tt.fields.UnimplementedSeqDbServer,
+				store:                    store.NewHBaseStore(tt.fields.client, storeOptions),
+				codec:                    rowkey.Legacy{},
+			}
+			got, err := s.Get(tt.args.ctx, tt.args.req)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("server.Get() error = %v, want code %v", err, tt.wantCode)
+				return
+			}
+			if err != nil && !proto.Equal(apierr.KeyOf(err), &pb.SeqKey{BizId: tt.args.req.BizId, Seq: tt.args.req.Seq}) {
+				t.Errorf("server.Get() error details key = %v, want the requested key", apierr.KeyOf(err))
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("server.Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// 旧客户端以 SeqKey 调用 Get，GetReq 须能解析同样的字节
+func TestGetReqCompatibleWithSeqKey(t *testing.T) {
+	data, err := proto.Marshal(&pb.SeqKey{BizId: []byte("biz1"), Seq: -3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &pb.GetReq{}
+	if err := proto.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (&pb.GetReq{BizId: []byte("biz1"), Seq: -3}); !proto.Equal(got, want) {
+		t.Errorf("SeqKey decoded as %v, want %v", got, want)
+	}
+}
+
+// putVersion 以指定的时间戳写入 item 的一个版本
+func putVersion(t *testing.T, client *hbasetest.Client, item *pb.SeqItem, ts uint64) {
+	t.Helper()
+	put, err := hrpc.NewPutStr(context.Background(), storeOptions.Table, generateRowKey(string(item.Key.BizId), item.Key.Seq), map[string]map[string][]byte{
+		storeOptions.Family: {storeOptions.Qualifier: mustMarshal(t, item)},
+	}, hrpc.TimestampUint64(ts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Put(put); err != nil {
+		t.Fatalf("Failed to put version: %v", err)
+	}
+}
+
+// newVersionedClient 写入 biz1/1 在时间戳 100、200、300 的三个版本和 biz1/2 在时间戳 400 的一个版本
+func newVersionedClient(t *testing.T) *hbasetest.Client {
+	client := hbasetest.NewClient()
+	key := &pb.SeqKey{BizId: []byte("biz1"), Seq: 1}
+	for _, ts := range []uint64{100, 200, 300} {
+		putVersion(t, client, &pb.SeqItem{Key: key, Value: []byte(fmt.Sprintf("v%d", ts))}, ts)
+	}
+	putVersion(t, client, newTestItem("biz1", 2), 400)
+	return client
+}
+
+// versionsOf 生成 biz1/1 在给定时间戳的版本
+func versionsOf(timestamps ...int64) []*pb.SeqItemVersion {
+	versions := make([]*pb.SeqItemVersion, len(timestamps))
+	for i, ts := range timestamps {
+		versions[i] = &pb.SeqItemVersion{Timestamp: ts, Value: []byte(fmt.Sprintf("v%d", ts))}
+	}
+	return versions
+}
+
+func Test_server_GetVersions(t *testing.T) {
+	s := &server{store: store.NewHBaseStore(newVersionedClient(t), storeOptions), codec: rowkey.Legacy{}}
+	key := &pb.SeqKey{BizId: []byte("biz1"), Seq: 1}
+	item := func(timestamps ...int64) *pb.SeqItem {
+		return &pb.SeqItem{Key: key, Value: []byte(fmt.Sprintf("v%d", timestamps[0])), Timestamp: timestamps[0], Versions: versionsOf(timestamps...)}
+	}
+	tests := []struct {
+		name     string
+		req      *pb.GetReq
+		want     *pb.SeqItem
+		wantCode codes.Code
+	}{
+		{name: "all versions", req: &pb.GetReq{MaxVersions: 5}, want: item(300, 200, 100)},
+		{name: "latest with timestamp", req: &pb.GetReq{MaxVersions: 1}, want: item(300)},
+		{name: "as of", req: &pb.GetReq{AsOfTimestamp: 250}, want: item(200)},
+		{name: "as of exact timestamp", req: &pb.GetReq{AsOfTimestamp: 200, MaxVersions: 5}, want: item(200, 100)},
+		{name: "time range", req: &pb.GetReq{TimeRange: &pb.TimeRange{Min: 100, Max: 300}, MaxVersions: 5}, want: item(200, 100)},
+		{name: "open time range", req: &pb.GetReq{TimeRange: &pb.TimeRange{Min: 150}, MaxVersions: 5}, want: item(300, 200)},
+		{name: "as of within time range", req: &pb.GetReq{AsOfTimestamp: 150, TimeRange: &pb.TimeRange{Min: 50, Max: 300}, MaxVersions: 5}, want: item(100)},
+		{name: "before first version", req: &pb.GetReq{AsOfTimestamp: 50}, wantCode: codes.NotFound},
+		{name: "negative max_versions", req: &pb.GetReq{MaxVersions: -1}, wantCode: codes.InvalidArgument},
+		{name: "empty time range", req: &pb.GetReq{TimeRange: &pb.TimeRange{Min: 200, Max: 200}}, wantCode: codes.InvalidArgument},
+		{name: "as of before time range", req: &pb.GetReq{AsOfTimestamp: 100, TimeRange: &pb.TimeRange{Min: 200}}, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.BizId, tt.req.Seq = key.BizId, key.Seq
+			got, err := s.Get(context.Background(), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("server.Get() error = %v, want code %v", err, tt.wantCode)
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("server.Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_server_QueryRangeVersions(t *testing.T) {
+	s := &server{store: store.NewHBaseStore(newVersionedClient(t), storeOptions), codec: rowkey.Legacy{}}
+	req := &pb.RangeReq{
+		Start:         &pb.SeqKey{BizId: []byte("biz1"), Seq: 1},
+		End:           &pb.SeqKey{BizId: []byte("biz1"), Seq: 2},
+		AsOfTimestamp: 250,
+		MaxVersions:   2,
+	}
+	got, err := s.QueryRange(context.Background(), req)
+	if err != nil {
+		t.Fatalf("server.QueryRange() error = %v", err)
+	}
+	// biz1/2 只有时间戳 400 的版本，不满足 as_of_timestamp
+	want := &pb.SeqItems{Items: []*pb.SeqItem{
+		{Key: req.Start, Value: []byte("v200"), Timestamp: 200, Versions: versionsOf(200, 100)},
+	}}
+	if !proto.Equal(got, want) {
+		t.Errorf("server.QueryRange() = %v, want %v", got, want)
+	}
+
+	// 不指定版本条件时与普通读取相同
+	got, err = s.QueryRange(context.Background(), &pb.RangeReq{Start: req.Start, End: req.End})
+	if err != nil {
+		t.Fatalf("server.QueryRange() error = %v", err)
+	}
+	want = &pb.SeqItems{Items: []*pb.SeqItem{{Key: req.Start, Value: []byte("v300")}, newTestItem("biz1", 2)}}
+	if !proto.Equal(got, want) {
+		t.Errorf("server.QueryRange() without versions = %v, want %v", got, want)
+	}
+}
+
+func Test_server_QueryRange(t *testing.T) {
+	items := []*pb.SeqItem{
+		newTestItem("biz1", 1), newTestItem("biz1", 2), newTestItem("biz1", 3), newTestItem("biz1", 4), newTestItem("biz1", 5),
+	}
+	// 覆盖 seq 3 的区间，用于构造原始行的用例
+	rangeReq := &pb.RangeReq{
+		Start:  &pb.SeqKey{BizId: []byte("biz1"), Seq: 4},
+		End:    &pb.SeqKey{BizId: []byte("biz1"), Seq: 2},
+		Option: pb.RangeOption_WithoutEnd,
+	}
+	type fields struct {
+		UnimplementedSeqDbServer pb.UnimplementedSeqDbServer
+		client                   gohbase.Client
+		compat                   bool
+	}
+	type args struct {
+		ctx context.Context
+		req *pb.RangeReq
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *pb.SeqItems
+		wantErr bool
+	}{
+		{
+			name:   "empty table",
+			fields: fields{client: newFakeClient(t)},
+			args: args{ctx: context.Background(), req: &pb.RangeReq{
+				Start:  &pb.SeqKey{BizId: []byte("biz1"), Seq: 4},
+				End:    &pb.SeqKey{BizId: []byte("biz1"), Seq: 2},
+				Option: pb.RangeOption_WithoutEnd,
+			}},
+			want: &pb.SeqItems{},
+		},
+		{
+			name:   "forward without end",
+			fields: fields{client: newFakeClient(t, items...)},
+			args: args{ctx: context.Background(), req: &pb.RangeReq{
+				Start:  &pb.SeqKey{BizId: []byte("biz1"), Seq: 4},
+				End:    &pb.SeqKey{BizId: []byte("biz1"), Seq: 2},
+				Option: pb.RangeOption_WithoutEnd,
+			}},
+			want: &pb.SeqItems{Items: []*pb.SeqItem{
+				items[3], items[2],
+			}},
+		},
+		{
+			name:   "reverse without end",
+			fields: fields{client: newFakeClient(t, items...)},
+			args: args{ctx: context.Background(), req: &pb.RangeReq{
+				Start:   &pb.SeqKey{BizId: []byte("biz1"), Seq: 4},
+				End:     &pb.SeqKey{BizId: []byte("biz1"), Seq: 2},
+				Reverse: true,
+				Option:  pb.RangeOption_WithoutEnd,
+			}},
+			want: &pb.SeqItems{Items: []*pb.SeqItem{
+				items[2], items[3],
+			}},
+		},
+		{
+			name:    "raw value rejected in strict mode",
+			fields:  fields{client: newRawFakeClient(t, generateRowKey("biz1", 3), []byte("value3"))},
+			args:    args{ctx: context.Background(), req: rangeReq},
+			wantErr: true,
+		},
+		{
+			name:   "raw value in compat mode",
+			fields: fields{client: newRawFakeClient(t, generateRowKey("biz1", 3), []byte("value3")), compat: true},
+			args:   args{ctx: context.Background(), req: rangeReq},
+			want: &pb.SeqItems{Items: []*pb.SeqItem{
+				{Key: &pb.SeqKey{BizId: []byte("biz1"), Seq: 3}, Value: []byte("value3")},
+			}},
+		},
+		{
+			name:    "mismatched row key rejected in strict mode",
+			fields:  fields{client: newRawFakeClient(t, generateRowKey("biz1", 3), mustMarshal(t, items[0]))},
+			args:    args{ctx: context.Background(), req: rangeReq},
+			wantErr: true,
+		},
+		{
+			name:   "mismatched row key in compat mode",
+			fields: fields{client: newRawFakeClient(t, generateRowKey("biz1", 3), mustMarshal(t, items[0])), compat: true},
+			args:   args{ctx: context.Background(), req: rangeReq},
+			want: &pb.SeqItems{Items: []*pb.SeqItem{
+				{Key: &pb.SeqKey{BizId: []byte("biz1"), Seq: 3}, Value: mustMarshal(t, items[0])},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{
+				UnimplementedSeqDbServer: tt.fields.UnimplementedSeqDbServer,
+				store:                    store.NewHBaseStore(tt.fields.client, storeOptions),
+				codec:                    rowkey.Legacy{},
+				compat:                   tt.fields.compat,
+			}
+			got, err := s.QueryRange(tt.args.ctx, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("server.QueryRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("server.QueryRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_server_QueryRangePaging(t *testing.T) {
+	var items []*pb.SeqItem
+	for seq := int32(1); seq <= 5; seq++ {
+		items = append(items, newTestItem("biz1", seq))
+	}
+	s := &server{store: store.NewHBaseStore(newFakeClient(t, items...), storeOptions), codec: rowkey.Legacy{}}
+
+	tests := []struct {
+		name      string
+		req       *pb.RangeReq
+		wantPages [][]*pb.SeqItem
+	}{
+		{
+			name: "forward",
+			req: &pb.RangeReq{
+				Start:  &pb.SeqKey{BizId: []byte("biz1"), Seq: 5},
+				End:    &pb.SeqKey{BizId: []byte("biz1"), Seq: 0},
+				Option: pb.RangeOption_WithoutEnd,
+				Limit:  2,
+			},
+			wantPages: [][]*pb.SeqItem{{items[4], items[3]}, {items[2], items[1]}, {items[0]}},
+		},
+		{
+			name: "reverse",
+			req: &pb.RangeReq{
+				Start:   &pb.SeqKey{BizId: []byte("biz1"), Seq: 5},
+				End:     &pb.SeqKey{BizId: []byte("biz1"), Seq: 0},
+				Option:  pb.RangeOption_WithoutEnd,
+				Reverse: true,
+				Limit:   3,
+			},
+			wantPages: [][]*pb.SeqItem{{items[0], items[1], items[2]}, {items[3], items[4]}},
+		},
+		{
+			name: "limit equals range size",
+			req: &pb.RangeReq{
+				Start:   &pb.SeqKey{BizId: []byte("biz1"), Seq: 5},
+				End:     &pb.SeqKey{BizId: []byte("biz1"), Seq: 0},
+				Option:  pb.RangeOption_WithoutEnd,
+				Reverse: true,
+				Limit:   5,
+			},
+			wantPages: [][]*pb.SeqItem{{items[0], items[1], items[2], items[3], items[4]}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := proto.Clone(tt.req).(*pb.RangeReq)
+			for i, want := range tt.wantPages {
+				got, err := s.QueryRange(context.Background(), req)
+				if err != nil {
+					t.Fatalf("page %d: QueryRange() error = %v", i, err)
+				}
+				if !proto.Equal(got, &pb.SeqItems{Items: want, NextPageToken: got.NextPageToken}) {
+					t.Errorf("page %d: QueryRange() = %v, want %v", i, got.Items, want)
+				}
+				if last := i == len(tt.wantPages)-1; last != (len(got.NextPageToken) == 0) {
+					t.Fatalf("page %d: next_page_token = %q, want empty only on last page", i, got.NextPageToken)
+				}
+				req.PageToken = got.NextPageToken
+			}
+		})
+	}
+}
+
+func Test_server_QueryRangeMulti(t *testing.T) {
+	salted, err := rowkey.NewSalted(4, rowkey.XXHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, codec := range []rowkey.Codec{rowkey.Binary{}, salted} {
+		t.Run(fmt.Sprintf("%T", codec), func(t *testing.T) {
+			testQueryRangeMulti(t, codec)
+		})
+	}
+}
+
+func testQueryRangeMulti(t *testing.T, codec rowkey.Codec) {
+	opts := storeOptions
+	opts.RowKey = codec.Encode
+	mem := store.NewMemoryStore(opts)
+	var items []*pb.SeqItem
+	for _, biz := range []string{"order1", "order2", "order10", "user1"} {
+		for seq := int32(1); seq <= 3; seq++ {
+			items = append(items, newTestItem(biz, seq))
+		}
+	}
+	if _, err := mem.Put(context.Background(), items, 0); err != nil {
+		t.Fatal(err)
+	}
+	s := &server{store: mem, codec: codec}
+	keys := func(items []*pb.SeqItem) []string {
+		var got []string
+		for _, item := range items {
+			got = append(got, fmt.Sprintf("%s/%d", item.Key.BizId, item.Key.Seq))
+		}
+		return got
+	}
+
+	tests := []struct {
+		name string
+		req  *pb.RangeReq
+		want []string
+	}{
+		{
+			name: "biz ids",
+			req:  &pb.RangeReq{BizIds: [][]byte{[]byte("user1"), []byte("order2"), []byte("missing")}, Start: &pb.SeqKey{Seq: 2}},
+			want: []string{"order2/2", "order2/3", "user1/2", "user1/3"},
+		},
+		{
+			name: "biz prefix",
+			req:  &pb.RangeReq{BizPrefix: []byte("order"), Start: &pb.SeqKey{Seq: 3}, End: &pb.SeqKey{Seq: 1}, Option: pb.RangeOption_WithoutEnd},
+			want: []string{"order1/2", "order1/3", "order10/2", "order10/3", "order2/2", "order2/3"},
+		},
+		{
+			name: "reverse pages",
+			req:  &pb.RangeReq{BizPrefix: []byte("order"), End: &pb.SeqKey{Seq: 2}, Reverse: true, Limit: 2},
+			want: []string{"order2/2", "order2/1", "order10/2", "order10/1", "order1/2", "order1/1"},
+		},
+		{
+			name: "page ends at biz boundary",
+			req:  &pb.RangeReq{BizIds: [][]byte{[]byte("order1"), []byte("order2")}, Limit: 3},
+			want: []string{"order1/1", "order1/2", "order1/3", "order2/1", "order2/2", "order2/3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := proto.Clone(tt.req).(*pb.RangeReq)
+			var got []string
+			for page := 0; ; page++ {
+				resp, err := s.QueryRange(context.Background(), req)
+				if err != nil {
+					t.Fatalf("page %d: QueryRange() error = %v", page, err)
+				}
+				if req.Limit > 0 && len(resp.Items) > int(req.Limit) {
+					t.Fatalf("page %d: got %d items, limit %d", page, len(resp.Items), req.Limit)
+				}
+				got = append(got, keys(resp.Items)...)
+				if len(resp.NextPageToken) == 0 {
+					break
+				}
+				req.PageToken = resp.NextPageToken
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	_, err := s.DeleteRange(context.Background(), &pb.RangeReq{BizPrefix: []byte("order")})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("DeleteRange(biz_prefix) error = %v, want InvalidArgument", err)
+	}
+}
+
+func Test_server_QueryRangeInvalid(t *testing.T) {
+	s := &server{store: store.NewHBaseStore(newFakeClient(t, newTestItem("biz1", 1)), storeOptions), codec: rowkey.Legacy{}}
+	req := func(limit int32, token []byte) *pb.RangeReq {
+		return &pb.RangeReq{
+			Start:     &pb.SeqKey{BizId: []byte("biz1"), Seq: 4},
+			End:       &pb.SeqKey{BizId: []byte("biz1"), Seq: 2},
+			Option:    pb.RangeOption_WithoutEnd,
+			Limit:     limit,
+			PageToken: token,
+		}
+	}
+	tests := []struct {
+		name string
+		req  *pb.RangeReq
+	}{
+		{"negative limit", req(-1, nil)},
+		{"malformed token", req(1, []byte("x"))},
+		{"token outside range", req(1, encodePageToken([]byte(generateRowKey("biz1", 1))))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.QueryRange(context.Background(), tt.req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("QueryRange() error = %v, want InvalidArgument", err)
+			}
+		})
+	}
+}
+
+func TestStreamRange(t *testing.T) {
+	var items []*pb.SeqItem
+	for seq := int32(1); seq <= 5; seq++ {
+		items = append(items, newTestItem("biz1", seq))
+	}
+	client := newBufconnClient(t, store.NewHBaseStore(newFakeClient(t, items...), storeOptions))
+
+	stream, err := client.StreamRange(context.Background(), &pb.RangeReq{
+		Start:  &pb.SeqKey{BizId: []byte("biz1"), Seq: 5},
+		End:    &pb.SeqKey{BizId: []byte("biz1"), Seq: 0},
+		Option: pb.RangeOption_WithoutEnd,
+		Limit:  3,
+	})
+	if err != nil {
+		t.Fatalf("StreamRange failed: %v", err)
+	}
+	var got []*pb.SeqItem
+	for {
+		item, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		got = append(got, item)
+	}
+	want := []*pb.SeqItem{items[4], items[3], items[2]}
+	if !proto.Equal(&pb.SeqItems{Items: got}, &pb.SeqItems{Items: want}) {
+		t.Errorf("StreamRange() = %v, want %v", got, want)
+	}
+}
+
+func Test_server_PutIfAbsent(t *testing.T) {
+	existing := newTestItem("biz1", 1)
+	s := &server{store: store.NewHBaseStore(newFakeClient(t, existing), storeOptions), codec: rowkey.Legacy{}}
+	ctx := context.Background()
+
+	overwrite := &pb.SeqItem{Key: existing.Key, Value: []byte("overwrite")}
+	fresh := newTestItem("biz1", 2)
+	got, err := s.PutIfAbsent(ctx, &pb.SeqItems{Items: []*pb.SeqItem{overwrite, fresh, {Value: []byte("no key")}}})
+	if err != nil {
+		t.Fatalf("PutIfAbsent() error = %v", err)
+	}
+	want := &pb.ConditionalResp{Results: []*pb.ConditionalResult{
+		{Key: existing.Key, Current: existing},
+		{Key: fresh.Key, Applied: true},
+		{Error: "missing key"},
+	}}
+	if !proto.Equal(got, want) {
+		t.Errorf("PutIfAbsent() = %v, want %v", got, want)
+	}
+	stored, _ := s.store.Get(ctx, []*pb.SeqKey{existing.Key, fresh.Key})
+	if !proto.Equal(stored[0], existing) || !proto.Equal(stored[1], fresh) {
+		t.Errorf("stored items = %v, want [%v %v]", stored, existing, fresh)
+	}
+}
+
+// 多个生产者并发写同一个 key，只有一个成功
+func Test_server_PutIfAbsentConcurrent(t *testing.T) {
+	s := &server{store: store.NewHBaseStore(newFakeClient(t), storeOptions), codec: rowkey.Legacy{}}
+	const producers = 10
+	var (
+		wg      sync.WaitGroup
+		applied = make([]bool, producers)
+	)
+	for i := 0; i < producers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			item := &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte("biz1"), Seq: 1}, Value: []byte(fmt.Sprintf("producer%d", i))}
+			resp, err := s.PutIfAbsent(context.Background(), &pb.SeqItems{Items: []*pb.SeqItem{item}})
+			if err != nil {
+				t.Errorf("PutIfAbsent() error = %v", err)
+				return
+			}
+			applied[i] = resp.Results[0].Applied
+		}(i)
+	}
+	wg.Wait()
+	var n int
+	for _, ok := range applied {
+		if ok {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("%d producers applied, want exactly 1", n)
+	}
+}
+
+func Test_server_CompareAndSwap(t *testing.T) {
+	existing := newTestItem("biz1", 1)
+	s := &server{store: store.NewHBaseStore(newFakeClient(t, existing), storeOptions), codec: rowkey.Legacy{}}
+	ctx := context.Background()
+	missingKey := &pb.SeqKey{BizId: []byte("biz1"), Seq: 2}
+
+	got, err := s.CompareAndSwap(ctx, &pb.CasReq{Items: []*pb.CasItem{
+		{Item: &pb.SeqItem{Key: existing.Key, Value: []byte("wrong")}, ExpectedValue: []byte("stale")},
+		{Item: &pb.SeqItem{Key: missingKey, Value: []byte("v")}, ExpectedValue: []byte("anything")},
+	}})
+	if err != nil {
+		t.Fatalf("CompareAndSwap() error = %v", err)
+	}
+	want := &pb.ConditionalResp{Results: []*pb.ConditionalResult{
+		{Key: existing.Key, Current: existing},
+		{Key: missingKey},
+	}}
+	if !proto.Equal(got, want) {
+		t.Errorf("CompareAndSwap() with stale values = %v, want %v", got, want)
+	}
+
+	swapped := &pb.SeqItem{Key: existing.Key, Value: []byte("swapped")}
+	got, err = s.CompareAndSwap(ctx, &pb.CasReq{Items: []*pb.CasItem{{Item: swapped, ExpectedValue: existing.Value}}})
+	if err != nil || !got.Results[0].Applied {
+		t.Fatalf("CompareAndSwap() with current value = %v, %v, want applied", got, err)
+	}
+	stored, _ := s.store.Get(ctx, []*pb.SeqKey{existing.Key, missingKey})
+	if !proto.Equal(stored[0], swapped) || stored[1] != nil {
+		t.Errorf("stored items = %v, want [%v <nil>]", stored, swapped)
+	}
+}
+
+// failingStore 在 Put 时跳过 Seq 为 failSeq 的 item 并返回错误，其余 item 照常写入，模拟部分写入
+type failingStore struct {
+	store.SeqStore
+	failSeq int32
+}
+
+func (s *failingStore) Put(ctx context.Context, items []*pb.SeqItem, ttl time.Duration) ([]int64, error) {
+	var (
+		written []*pb.SeqItem
+		index   []int // written 中的 item 在 items 中的下标
+	)
+	errs := make([]error, len(items))
+	for i, item := range items {
+		if item.Key.Seq == s.failSeq {
+			errs[i] = fmt.Errorf("org.apache.hadoop.hbase.NotServingRegionException: region unavailable")
+			continue
+		}
+		written = append(written, item)
+		index = append(index, i)
+	}
+	ts, err := s.SeqStore.Put(ctx, written, ttl)
+	if err != nil {
+		return nil, err
+	}
+	timestamps := make([]int64, len(items))
+	for j, i := range index {
+		timestamps[i] = ts[j]
+	}
+	if len(written) < len(items) {
+		return timestamps, &store.PutError{Errs: errs}
+	}
+	return timestamps, nil
+}
+
+func Test_server_PutStatus(t *testing.T) {
+	items := []*pb.SeqItem{newTestItem("biz1", 1), {Value: []byte("no key")}, newTestItem("biz1", 3)}
+	wantCodes := []codes.Code{codes.OK, codes.InvalidArgument, codes.Unavailable}
+	put := map[string]func(*server) (*pb.PutItemResp, error){
+		"Put": func(s *server) (*pb.PutItemResp, error) {
+			return s.Put(context.Background(), &pb.SeqItems{Items: items})
+		},
+		"BatchPut": func(s *server) (*pb.PutItemResp, error) {
+			return s.BatchPut(context.Background(), &pb.SeqItemsList{ItemsList: []*pb.SeqItems{
+				{Items: items[:1]}, {Items: items[1:]},
+			}})
+		},
+	}
+	for name, fn := range put {
+		t.Run(name, func(t *testing.T) {
+			st := &failingStore{SeqStore: store.NewHBaseStore(newFakeClient(t), storeOptions), failSeq: 3}
+			s := &server{store: st, codec: rowkey.Legacy{}}
+			before := time.Now().UnixMilli()
+			got, err := fn(s)
+			if err != nil {
+				t.Fatalf("%s() error = %v", name, err)
+			}
+			if len(got.Statuses) != len(items) {
+				t.Fatalf("%s() statuses = %v, want %d", name, got.Statuses, len(items))
+			}
+			for i, status := range got.Statuses {
+				if codes.Code(status.Code) != wantCodes[i] {
+					t.Errorf("status %d code = %v, want %v", i, codes.Code(status.Code), wantCodes[i])
+				}
+				if !proto.Equal(status.Key, items[i].Key) {
+					t.Errorf("status %d key = %v, want %v", i, status.Key, items[i].Key)
+				}
+				if wantCodes[i] != codes.OK {
+					if status.Error == "" || status.Timestamp != 0 {
+						t.Errorf("failed status %d = %v, want error and no timestamp", i, status)
+					}
+					continue
+				}
+				if string(status.RowKey) != generateRowKey("biz1", items[i].Key.Seq) || status.Timestamp < before {
+					t.Errorf("status %d = %v, want row key %s and timestamp >= %d", i, status, generateRowKey("biz1", items[i].Key.Seq), before)
+				}
+			}
+			if len(got.Committed) != 1 || len(got.Failed) != 2 {
+				t.Errorf("%s() committed = %v, failed = %v", name, got.Committed, got.Failed)
+			}
+		})
+	}
+}
+
+// 同一 key 连续写入的时间戳由 HBase 分配，每次写入都是一个新版本，返回的时间戳即读到的版本时间戳
+func Test_server_PutTimestamp(t *testing.T) {
+	s := &server{store: store.NewHBaseStore(newFakeClient(t), storeOptions), codec: rowkey.Legacy{}}
+	key := &pb.SeqKey{BizId: []byte("biz1"), Seq: 1}
+	var timestamps []int64 // 按时间戳从新到旧
+	for i := 0; i < 3; i++ {
+		resp, err := s.Put(context.Background(), &pb.SeqItems{Items: []*pb.SeqItem{{Key: key, Value: []byte(fmt.Sprintf("v%d", i))}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ts := resp.Statuses[0].Timestamp
+		if len(timestamps) > 0 && ts <= timestamps[0] {
+			t.Fatalf("put %d timestamp = %d, want after %d", i, ts, timestamps[0])
+		}
+		timestamps = append([]int64{ts}, timestamps...)
+	}
+	got, err := s.Get(context.Background(), &pb.GetReq{BizId: key.BizId, Seq: key.Seq, MaxVersions: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotTimestamps []int64
+	for _, version := range got.Versions {
+		gotTimestamps = append(gotTimestamps, version.Timestamp)
+	}
+	if !reflect.DeepEqual(gotTimestamps, timestamps) {
+		t.Errorf("server.Get() version timestamps = %v, want %v", gotTimestamps, timestamps)
+	}
+}
+
+// countingStore 记录 Delete 的调用次数、每批的行数和最大并发数
+type countingStore struct {
+	store.SeqStore
+	mu                sync.Mutex
+	batches           []int
+	active, maxActive int
+	err               error // 非空时 Delete 返回该错误
+}
+
+func (s *countingStore) Delete(ctx context.Context, rowKeys [][]byte) error {
+	s.mu.Lock()
+	s.batches = append(s.batches, len(rowKeys))
+	s.active++
+	s.maxActive = max(s.maxActive, s.active)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.active--
+		s.mu.Unlock()
+	}()
+	time.Sleep(time.Millisecond) // 让并发的批次有机会重叠
+	if s.err != nil {
+		return s.err
+	}
+	return s.SeqStore.Delete(ctx, rowKeys)
+}
+
+func Test_server_DeleteRangeBulk(t *testing.T) {
+	const n = 2500
+	ctx := context.Background()
+	mem := store.NewMemoryStore(storeOptions)
+	items := []*pb.SeqItem{newTestItem("biz2", 1)}
+	for seq := int32(1); seq <= n; seq++ {
+		items = append(items, newTestItem("biz1", seq))
+	}
+	if _, err := mem.Put(ctx, items, 0); err != nil {
+		t.Fatal(err)
+	}
+	st := &countingStore{SeqStore: mem}
+	s := &server{store: st, codec: rowkey.Legacy{}}
+	req := func(dryRun bool, maxRows int64) *pb.RangeReq {
+		return &pb.RangeReq{
+			Start:  &pb.SeqKey{BizId: []byte("biz1"), Seq: 1},
+			End:    &pb.SeqKey{BizId: []byte("biz1"), Seq: n},
+			DryRun: dryRun, MaxRows: maxRows,
+		}
+	}
+	count := func() int64 {
+		resp, err := s.GetKeyCount(ctx, &pb.SeqKey{BizId: []byte("biz1")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Count
+	}
+
+	got, err := s.DeleteRange(ctx, req(true, 0))
+	if err != nil {
+		t.Fatalf("DeleteRange() dry run error = %v", err)
+	}
+	if got.Deleted != n || got.FirstKey.GetSeq() != 1 || got.LastKey.GetSeq() != n || got.Truncated {
+		t.Errorf("DeleteRange() dry run = %v, want %d rows from 1 to %d", got, n, n)
+	}
+	if len(st.batches) != 0 || count() != n {
+		t.Errorf("dry run deleted rows: %d batches, %d rows left", len(st.batches), count())
+	}
+
+	got, err = s.DeleteRange(ctx, req(false, 1200))
+	if err != nil {
+		t.Fatalf("DeleteRange() with max_rows error = %v", err)
+	}
+	if got.Deleted != 1200 || got.LastKey.GetSeq() != 1200 || !got.Truncated {
+		t.Errorf("DeleteRange() with max_rows = %v, want 1200 rows, truncated", got)
+	}
+	sort.Ints(st.batches) // 批次并发删除，完成顺序不确定
+	if !reflect.DeepEqual(st.batches, []int{200, 1000}) || count() != n-1200 {
+		t.Errorf("DeleteRange() with max_rows batches = %v, %d rows left", st.batches, count())
+	}
+
+	st.batches = nil
+	got, err = s.DeleteRange(ctx, req(false, 0))
+	if err != nil {
+		t.Fatalf("DeleteRange() error = %v", err)
+	}
+	if got.Deleted != n-1200 || got.FirstKey.GetSeq() != 1201 || got.Truncated {
+		t.Errorf("DeleteRange() = %v, want %d rows from 1201", got, n-1200)
+	}
+	if len(st.batches) != 2 || count() != 0 || st.maxActive > deleteConcurrency {
+		t.Errorf("DeleteRange() batches = %v, max concurrency %d, %d rows left", st.batches, st.maxActive, count())
+	}
+	if left, _ := s.GetKeyCount(ctx, &pb.SeqKey{BizId: []byte("biz2")}); left.GetCount() != 1 {
+		t.Errorf("rows of other biz_id deleted")
+	}
+
+	if _, err := s.DeleteRange(ctx, req(false, -1)); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("DeleteRange() with negative max_rows error = %v, want InvalidArgument", err)
+	}
+	if _, err := mem.Put(ctx, items, 0); err != nil {
+		t.Fatal(err)
+	}
+	st.err = fmt.Errorf("region unavailable")
+	if _, err := s.DeleteRange(ctx, req(false, 0)); err == nil {
+		t.Errorf("DeleteRange() with failing delete error = nil")
+	}
+}
+
+func Test_server_AtomicPut(t *testing.T) {
+	existing := newTestItem("biz1", 1)
+	st := &failingStore{SeqStore: store.NewHBaseStore(newFakeClient(t, existing), storeOptions), failSeq: 3}
+	s := &server{store: st, codec: rowkey.Legacy{}}
+	ctx := context.Background()
+
+	items := []*pb.SeqItem{
+		{Key: existing.Key, Value: []byte("overwrite")},
+		newTestItem("biz1", 2),
+		newTestItem("biz1", 3),
+		newTestItem("biz2", 1),
+		{Value: []byte("no key")},
+	}
+	got, err := s.Put(ctx, &pb.SeqItems{Items: items, Atomic: true})
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	for i, status := range got.Statuses {
+		want := codes.Aborted
+		switch i {
+		case 3:
+			want = codes.OK
+		case 4:
+			want = codes.InvalidArgument
+		}
+		if codes.Code(status.Code) != want {
+			t.Errorf("status %d = %v, want code %v", i, status, want)
+		}
+	}
+	if len(got.Committed) != 1 || !proto.Equal(got.Committed[0], items[3].Key) {
+		t.Errorf("Put() committed = %v, want [%v]", got.Committed, items[3].Key)
+	}
+	var failed []*pb.SeqKey
+	for _, f := range got.Failed {
+		if f.Error == "" {
+			t.Errorf("failure of %v has no error", f.Key)
+		}
+		failed = append(failed, f.Key)
+	}
+	if want := []*pb.SeqKey{nil, items[0].Key, items[1].Key, items[2].Key}; len(failed) != len(want) {
+		t.Errorf("Put() failed keys = %v, want %v", failed, want)
+	}
+
+	// biz1 回滚到写入前的状态，biz2 正常写入，日志都已删除
+	stored, _ := s.store.Get(ctx, []*pb.SeqKey{items[0].Key, items[1].Key, items[2].Key, items[3].Key})
+	want := []*pb.SeqItem{existing, nil, nil, items[3]}
+	for i := range want {
+		if !proto.Equal(stored[i], want[i]) {
+			t.Errorf("stored %v = %v, want %v", items[i].Key, stored[i], want[i])
+		}
+	}
+	for _, biz := range []string{"biz1", "biz2"} {
+		if wal, _ := s.store.GetWAL(ctx, s.codec.Prefix([]byte(biz))); wal != nil {
+			t.Errorf("put log of %s not deleted", biz)
+		}
+	}
+}
+
+func Test_server_AtomicPutLog(t *testing.T) {
+	existing := newTestItem("biz1", 1)
+	s := &server{store: store.NewHBaseStore(newFakeClient(t, existing), storeOptions), codec: rowkey.Legacy{}}
+	ctx := context.Background()
+	logRow := s.codec.Prefix([]byte("biz1"))
+	item := newTestItem("biz1", 2)
+
+	// 租约内的日志表示有其他原子 Put 正在进行
+	inProgress, _ := proto.Marshal(&pb.PutLog{StartedAt: time.Now().UnixMilli()})
+	if ok, err := s.store.PutWAL(ctx, logRow, nil, inProgress); err != nil || !ok {
+		t.Fatalf("PutWAL() = %v, %v", ok, err)
+	}
+	got, err := s.Put(ctx, &pb.SeqItems{Items: []*pb.SeqItem{item}, Atomic: true})
+	if err != nil || len(got.Failed) != 1 || got.Failed[0].Error != errPutInProgress.Error() {
+		t.Fatalf("Put() with put in progress = %v, %v, want failure %q", got, err, errPutInProgress)
+	}
+
+	// 崩溃的写入方只写入了部分 item，租约过期后被回滚
+	partial := &pb.SeqItem{Key: existing.Key, Value: []byte("partial")}
+	crashed, _ := proto.Marshal(&pb.PutLog{
+		StartedAt: time.Now().Add(-2 * putLogLease).UnixMilli(),
+		Prepared:  true,
+		Entries: []*pb.PutLogEntry{
+			{Key: existing.Key, Before: existing, After: partial},
+			{Key: &pb.SeqKey{BizId: []byte("biz1"), Seq: 3}, After: newTestItem("biz1", 3)},
+		},
+	})
+	if ok, err := s.store.PutWAL(ctx, logRow, inProgress, crashed); err != nil || !ok {
+		t.Fatalf("PutWAL() = %v, %v", ok, err)
+	}
+	if _, err := s.store.Put(ctx, []*pb.SeqItem{partial}, 0); err != nil {
+		t.Fatal(err)
+	}
+	got, err = s.Put(ctx, &pb.SeqItems{Items: []*pb.SeqItem{item}, Atomic: true})
+	if err != nil || len(got.Committed) != 1 || len(got.Failed) != 0 {
+		t.Fatalf("Put() after stale log = %v, %v, want committed", got, err)
+	}
+	stored, _ := s.store.Get(ctx, []*pb.SeqKey{existing.Key, item.Key})
+	if !proto.Equal(stored[0], existing) || !proto.Equal(stored[1], item) {
+		t.Errorf("stored items = %v, want [%v %v]", stored, existing, item)
+	}
+	if wal, _ := s.store.GetWAL(ctx, logRow); wal != nil {
+		t.Errorf("put log not deleted")
+	}
+}
+
+// takeoverStore 在写入 item 前将日志替换为 takeover，模拟租约过期后被其他写入方接管
+type takeoverStore struct {
+	store.SeqStore
+	logRow   []byte
+	takeover []byte
+}
+
+func (s *takeoverStore) Put(ctx context.Context, items []*pb.SeqItem, ttl time.Duration) ([]int64, error) {
+	if s.takeover != nil {
+		current, err := s.SeqStore.GetWAL(ctx, s.logRow)
+		if err != nil {
+			return nil, err
+		}
+		if ok, err := s.SeqStore.PutWAL(ctx, s.logRow, current, s.takeover); err != nil || !ok {
+			return nil, fmt.Errorf("take over put log: %v, %v", ok, err)
+		}
+		s.takeover = nil
+	}
+	return s.SeqStore.Put(ctx, items, ttl)
+}
+
+func Test_server_AtomicPutTakenOver(t *testing.T) {
+	codec := rowkey.Legacy{}
+	logRow := codec.Prefix([]byte("biz1"))
+	other, _ := proto.Marshal(&pb.PutLog{StartedAt: time.Now().UnixMilli()})
+	st := &takeoverStore{SeqStore: store.NewHBaseStore(newFakeClient(t), storeOptions), logRow: logRow, takeover: other}
+	s := &server{store: st, codec: codec}
+	ctx := context.Background()
+	items := &pb.SeqItems{Items: []*pb.SeqItem{newTestItem("biz1", 1)}, Atomic: true}
+
+	// 接管方的日志不能被删除，本次写入可能已被接管方回滚，按未写入返回
+	got, err := s.Put(ctx, items)
+	if err != nil || len(got.Failed) != 1 || got.Failed[0].Error != errPutInProgress.Error() {
+		t.Fatalf("Put() after takeover = %v, %v, want failure %q", got, err, errPutInProgress)
+	}
+	if wal, _ := s.store.GetWAL(ctx, logRow); !bytes.Equal(wal, other) {
+		t.Fatalf("put log = %x, want the log of the other writer", wal)
+	}
+
+	// 接管方删除日志后可以再次加锁
+	if ok, err := s.store.DeleteWAL(ctx, logRow, other); err != nil || !ok {
+		t.Fatalf("DeleteWAL() = %v, %v", ok, err)
+	}
+	if got, err := s.Put(ctx, items); err != nil || len(got.Committed) != 1 {
+		t.Fatalf("Put() after log deleted = %v, %v, want committed", got, err)
+	}
+	if wal, _ := s.store.GetWAL(ctx, logRow); wal != nil {
+		t.Errorf("put log = %x, want deleted", wal)
+	}
 }
 
-func Test_server_Get(t *testing.T) {
-	type fields struct {
-		UnimplementedSeqDbServer pb.UnimplementedSeqDbServer
-		client                   gohbase.Client
+// slowPutStore 写入 item 耗时 delay，并记录写入开始和结束时日志的 StartedAt
+type slowPutStore struct {
+	store.SeqStore
+	logRow     []byte
+	delay      time.Duration
+	startedAts []int64
+}
+
+func (s *slowPutStore) startedAt(ctx context.Context) int64 {
+	data, _ := s.SeqStore.GetWAL(ctx, s.logRow)
+	record := &pb.PutLog{}
+	proto.Unmarshal(data, record)
+	return record.StartedAt
+}
+
+func (s *slowPutStore) Put(ctx context.Context, items []*pb.SeqItem, ttl time.Duration) ([]int64, error) {
+	s.startedAts = append(s.startedAts, s.startedAt(ctx))
+	time.Sleep(s.delay)
+	s.startedAts = append(s.startedAts, s.startedAt(ctx))
+	return s.SeqStore.Put(ctx, items, ttl)
+}
+
+func Test_server_AtomicPutRenew(t *testing.T) {
+	renewal := putLogRenewal
+	putLogRenewal = 10 * time.Millisecond
+	defer func() { putLogRenewal = renewal }()
+
+	codec := rowkey.Legacy{}
+	logRow := codec.Prefix([]byte("biz1"))
+	st := &slowPutStore{SeqStore: store.NewHBaseStore(newFakeClient(t), storeOptions), logRow: logRow, delay: 50 * time.Millisecond}
+	s := &server{store: st, codec: codec}
+	ctx := context.Background()
+
+	// 写入 item 期间日志被续约，写入完成后删除的是续约后的日志
+	got, err := s.Put(ctx, &pb.SeqItems{Items: []*pb.SeqItem{newTestItem("biz1", 1)}, Atomic: true})
+	if err != nil || len(got.Committed) != 1 || len(got.Failed) != 0 {
+		t.Fatalf("Put() = %v, %v, want committed", got, err)
 	}
-	type args struct {
-		ctx    context.Context
-		seqKey *pb.SeqKey
+	if len(st.startedAts) != 2 || st.startedAts[0] == 0 || st.startedAts[1] <= st.startedAts[0] {
+		t.Errorf("StartedAt before and after writing items = %v, want renewed", st.startedAts)
+	}
+	if wal, _ := s.store.GetWAL(ctx, logRow); wal != nil {
+		t.Errorf("put log = %x, want deleted", wal)
+	}
+}
+
+// ttlStore 记录每个 item 写入时的 TTL
+type ttlStore struct {
+	store.SeqStore
+	mu   sync.Mutex
+	ttls map[int32]time.Duration // Seq -> TTL
+}
+
+func (s *ttlStore) record(items []*pb.SeqItem, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range items {
+		s.ttls[item.Key.Seq] = ttl
+	}
+}
+
+func (s *ttlStore) Put(ctx context.Context, items []*pb.SeqItem, ttl time.Duration) ([]int64, error) {
+	s.record(items, ttl)
+	return s.SeqStore.Put(ctx, items, ttl)
+}
+
+func (s *ttlStore) CheckAndPut(ctx context.Context, item, expected *pb.SeqItem, ttl time.Duration) (int64, error) {
+	s.record([]*pb.SeqItem{item}, ttl)
+	return s.SeqStore.CheckAndPut(ctx, item, expected, ttl)
+}
+
+func Test_server_AtomicPutRollbackTTL(t *testing.T) {
+	policies := retention.Policies{Default: retention.Policy{TTL: time.Hour}}
+	st := &ttlStore{SeqStore: &failingStore{SeqStore: store.NewMemoryStore(storeOptions), failSeq: 3}, ttls: map[int32]time.Duration{}}
+	s := &server{store: st, codec: rowkey.Legacy{}, retention: policies}
+	ctx := context.Background()
+	existing := newTestItem("biz1", 1)
+	if _, err := s.Put(ctx, &pb.SeqItems{Items: []*pb.SeqItem{existing}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 恢复的值按原过期时间的剩余 TTL 写入，而不是重新获得完整的 TTL
+	items := []*pb.SeqItem{{Key: existing.Key, Value: []byte("overwrite")}, newTestItem("biz1", 3)}
+	if _, err := s.Put(ctx, &pb.SeqItems{Items: items, Atomic: true, TtlSeconds: 60}); err != nil {
+		t.Fatal(err)
 	}
+	if ttl := st.ttls[1]; ttl <= time.Hour-time.Minute || ttl > time.Hour {
+		t.Errorf("restored ttl = %v, want remaining ttl of the original value", ttl)
+	}
+	stored, _ := s.store.Get(ctx, []*pb.SeqKey{existing.Key})
+	if !proto.Equal(stored[0], existing) {
+		t.Errorf("stored item = %v, want %v", stored[0], existing)
+	}
+
+	// 已过原过期时间的值不再恢复
+	expired := &pb.PutLog{Entries: []*pb.PutLogEntry{
+		{Key: existing.Key, Before: existing, After: items[0], BeforeExpiresAt: time.Now().Add(-time.Minute).UnixMilli()},
+	}}
+	if err := s.rollbackPutLog(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := s.store.Get(ctx, []*pb.SeqKey{existing.Key}); stored[0] != nil {
+		t.Errorf("stored item = %v, want expired value removed", stored[0])
+	}
+}
+
+func Test_server_PutTTL(t *testing.T) {
+	policies := retention.Policies{
+		Default: retention.Policy{TTL: time.Hour},
+		Biz:     map[string]retention.Policy{"forever": {}},
+	}
+	items := func(bizID string, seqs ...int32) []*pb.SeqItem {
+		var items []*pb.SeqItem
+		for _, seq := range seqs {
+			items = append(items, newTestItem(bizID, seq))
+		}
+		return items
+	}
+	ctx := context.Background()
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    *pb.SeqItem
-		wantErr bool
+		name string
+		call func(s *server) error
+		want map[int32]time.Duration
 	}{
-		// TODO: Add test cases.
+		{"put default", func(s *server) error {
+			_, err := s.Put(ctx, &pb.SeqItems{Items: items("biz1", 1)})
+			return err
+		}, map[int32]time.Duration{1: time.Hour}},
+		{"put shorter", func(s *server) error {
+			_, err := s.Put(ctx, &pb.SeqItems{Items: items("biz1", 1), TtlSeconds: 60})
+			return err
+		}, map[int32]time.Duration{1: time.Minute}},
+		{"put longer", func(s *server) error {
+			_, err := s.Put(ctx, &pb.SeqItems{Items: items("biz1", 1), TtlSeconds: 7200})
+			return err
+		}, map[int32]time.Duration{1: time.Hour}},
+		{"atomic put", func(s *server) error {
+			_, err := s.Put(ctx, &pb.SeqItems{Items: items("forever", 1, 2), Atomic: true, TtlSeconds: 60})
+			return err
+		}, map[int32]time.Duration{1: time.Minute, 2: time.Minute}},
+		{"batch put", func(s *server) error {
+			_, err := s.BatchPut(ctx, &pb.SeqItemsList{ItemsList: []*pb.SeqItems{
+				{Items: append(items("biz1", 1), items("forever", 2)...)},
+				{Items: items("forever", 3), TtlSeconds: 60},
+			}})
+			return err
+		}, map[int32]time.Duration{1: time.Hour, 2: 0, 3: time.Minute}},
+		{"put if absent", func(s *server) error {
+			_, err := s.PutIfAbsent(ctx, &pb.SeqItems{Items: items("forever", 1), TtlSeconds: 60})
+			return err
+		}, map[int32]time.Duration{1: time.Minute}},
+		{"compare and swap", func(s *server) error {
+			_, err := s.CompareAndSwap(ctx, &pb.CasReq{Items: []*pb.CasItem{{Item: newTestItem("biz1", 1)}}})
+			return err
+		}, map[int32]time.Duration{1: time.Hour}},
+	}
+	for _, tt := range tests {
+		st := &ttlStore{SeqStore: store.NewMemoryStore(storeOptions), ttls: map[int32]time.Duration{}}
+		s := &server{store: st, codec: rowkey.Legacy{}, retention: policies}
+		if err := tt.call(s); err != nil {
+			t.Fatalf("%s: error = %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(st.ttls, tt.want) {
+			t.Errorf("%s: ttls = %v, want %v", tt.name, st.ttls, tt.want)
+		}
+	}
+
+	s := &server{store: store.NewMemoryStore(storeOptions), codec: rowkey.Legacy{}, retention: policies}
+	_, err := s.Put(ctx, &pb.SeqItems{Items: items("biz1", 1), TtlSeconds: -1})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Put(ttl_seconds -1) error = %v, want InvalidArgument", err)
+	}
+}
+
+func Test_server_AllocateSeq(t *testing.T) {
+	client := newFakeClient(t, newTestItem("biz1", 3), newTestItem("biz1", 5), newTestItem("big", math.MaxInt32-1))
+	s := &server{store: store.NewHBaseStore(client, storeOptions), codec: rowkey.Legacy{}}
+	ctx := context.Background()
 
+	tests := []struct {
+		name     string
+		req      *pb.AllocateSeqReq
+		want     *pb.AllocateSeqResp
+		wantCode codes.Code
+	}{
+		{
+			name: "continues after existing max seq",
+			req:  &pb.AllocateSeqReq{BizId: []byte("biz1"), Count: 3},
+			want: &pb.AllocateSeqResp{BizId: []byte("biz1"), First: 6, Last: 8},
+		},
+		{
+			name: "next block",
+			req:  &pb.AllocateSeqReq{BizId: []byte("biz1"), Count: 1},
+			want: &pb.AllocateSeqResp{BizId: []byte("biz1"), First: 9, Last: 9},
+		},
+		{
+			name: "new biz starts at 1",
+			req:  &pb.AllocateSeqReq{BizId: []byte("biz2"), Count: 2},
+			want: &pb.AllocateSeqResp{BizId: []byte("biz2"), First: 1, Last: 2},
+		},
+		{name: "zero count", req: &pb.AllocateSeqReq{BizId: []byte("biz1")}, wantCode: codes.InvalidArgument},
+		{name: "seq space exhausted", req: &pb.AllocateSeqReq{BizId: []byte("big"), Count: 2}, wantCode: codes.ResourceExhausted},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := &server{
-				UnimplementedSeqDbServer: tt.fields.UnimplementedSeqDbServer,
-				client:                   tt.fields.client,
+			got, err := s.AllocateSeq(ctx, tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("server.AllocateSeq() error = %v, want code %v", err, tt.wantCode)
 			}
-			got, err := s.Get(tt.args.ctx, tt.args.seqKey)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("server.Get() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("server.Get() = %v, want %v", got, tt.want)
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("server.AllocateSeq() = %v, want %v", got, tt.want)
 			}
 		})
 	}
+
+	// 计数器行不在 Seq 区间内，不影响范围查询
+	items, err := s.QueryRange(ctx, &pb.RangeReq{
+		Start: &pb.SeqKey{BizId: []byte("biz1"), Seq: math.MaxInt32},
+		End:   &pb.SeqKey{BizId: []byte("biz1"), Seq: 0},
+	})
+	if err != nil || len(items.Items) != 2 {
+		t.Errorf("QueryRange() after AllocateSeq = %v, %v, want 2 items", items, err)
+	}
 }
 
-func Test_server_QueryRange(t *testing.T) {
-	type fields struct {
-		UnimplementedSeqDbServer pb.UnimplementedSeqDbServer
-		client                   gohbase.Client
+// 多个服务实例并发为同一 BizId 分配 seq，分配结果互不重叠且连续
+func Test_server_AllocateSeqConcurrent(t *testing.T) {
+	client := newFakeClient(t)
+	servers := []*server{
+		{store: store.NewHBaseStore(client, storeOptions), codec: rowkey.Legacy{}},
+		{store: store.NewHBaseStore(client, storeOptions), codec: rowkey.Legacy{}},
 	}
-	type args struct {
-		ctx context.Context
-		req *pb.RangeReq
+	const workers, count = 20, 5
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		seen = map[int32]bool{}
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(s *server) {
+			defer wg.Done()
+			resp, err := s.AllocateSeq(context.Background(), &pb.AllocateSeqReq{BizId: []byte("biz1"), Count: count})
+			if err != nil {
+				t.Errorf("AllocateSeq() error = %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for seq := resp.First; seq <= resp.Last; seq++ {
+				if seen[seq] {
+					t.Errorf("seq %d allocated twice", seq)
+				}
+				seen[seq] = true
+			}
+		}(servers[i%len(servers)])
+	}
+	wg.Wait()
+	for seq := int32(1); seq <= workers*count; seq++ {
+		if !seen[seq] {
+			t.Errorf("seq %d not allocated, want contiguous blocks", seq)
+		}
 	}
+}
+
+func Test_server_decodeRow(t *testing.T) {
+	item := newTestItem("biz1", 3)
+	legacyRow := &store.Row{Key: []byte(generateRowKey("biz1", 3)), Value: mustMarshal(t, item)}
 	tests := []struct {
 		name    string
-		fields  fields
-		args    args
-		want    *pb.SeqItems
+		compat  bool
+		row     *store.Row
+		want    *pb.SeqItem
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "binary row",
+			row:  &store.Row{Key: rowkey.Binary{}.Encode(item.Key), Value: mustMarshal(t, item)},
+			want: item,
+		},
+		{name: "legacy row in strict mode", row: legacyRow, wantErr: true},
+		{name: "legacy row in compat mode", compat: true, row: legacyRow, want: item},
+		{
+			name:   "legacy row with raw value in compat mode",
+			compat: true,
+			row:    &store.Row{Key: legacyRow.Key, Value: []byte("value3")},
+			want:   &pb.SeqItem{Key: item.Key, Value: []byte("value3")},
+		},
+		{
+			name:   "unknown row key with SeqItem value in compat mode",
+			compat: true,
+			row:    &store.Row{Key: []byte("other"), Value: mustMarshal(t, item)},
+			want:   item,
+		},
+		{
+			name:    "unknown row key with raw value in compat mode",
+			compat:  true,
+			row:     &store.Row{Key: []byte("other"), Value: []byte("value3")},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := &server{
-				UnimplementedSeqDbServer: tt.fields.UnimplementedSeqDbServer,
-				client:                   tt.fields.client,
-			}
-			got, err := s.QueryRange(tt.args.ctx, tt.args.req)
+			s := &server{codec: rowkey.Binary{}, compat: tt.compat}
+			got, err := s.decodeRow(tt.row)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("server.QueryRange() error = %v, wantErr %v", err, tt.wantErr)
-				return
+				t.Fatalf("server.decodeRow() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("server.QueryRange() = %v, want %v", got, tt.want)
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("server.decodeRow() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
 func Test_server_DeleteRange(t *testing.T) {
+	items := []*pb.SeqItem{
+		newTestItem("biz1", 1), newTestItem("biz1", 2), newTestItem("biz1", 3), newTestItem("biz1", 4), newTestItem("biz1", 5),
+	}
 	type fields struct {
 		UnimplementedSeqDbServer pb.UnimplementedSeqDbServer
 		client                   gohbase.Client
@@ -292,33 +1688,65 @@ func Test_server_DeleteRange(t *testing.T) {
 		req *pb.RangeReq
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    *pb.DelRangeResp
-		wantErr bool
+		name          string
+		fields        fields
+		args          args
+		want          *pb.DelRangeResp
+		wantErr       bool
+		wantRemaining []string // 删除后剩余的 rowkey
 	}{
-		// TODO: Add test cases.
+		{
+			name:   "reverse without end",
+			fields: fields{client: newFakeClient(t, items...)},
+			args: args{ctx: context.Background(), req: &pb.RangeReq{
+				Start:   &pb.SeqKey{BizId: []byte("biz1"), Seq: 4},
+				End:     &pb.SeqKey{BizId: []byte("biz1"), Seq: 2},
+				Reverse: true,
+				Option:  pb.RangeOption_WithoutEnd,
+			}},
+			// 倒序时按 End 到 Start 的顺序删除
+			want: &pb.DelRangeResp{
+				Deleted:     2,
+				FirstKey:    &pb.SeqKey{BizId: []byte("biz1"), Seq: 3},
+				LastKey:     &pb.SeqKey{BizId: []byte("biz1"), Seq: 4},
+				FirstRowKey: []byte(generateRowKey("biz1", 3)),
+				LastRowKey:  []byte(generateRowKey("biz1", 4)),
+			},
+			wantRemaining: []string{
+				generateRowKey("biz1", 5), generateRowKey("biz1", 2), generateRowKey("biz1", 1),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &server{
 				UnimplementedSeqDbServer: tt.fields.UnimplementedSeqDbServer,
-				client:                   tt.fields.client,
+				store:                    store.NewHBaseStore(tt.fields.client, storeOptions),
+				codec:                    rowkey.Legacy{},
 			}
 			got, err := s.DeleteRange(tt.args.ctx, tt.args.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("server.DeleteRange() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
+			if !proto.Equal(got, tt.want) {
 				t.Errorf("server.DeleteRange() = %v, want %v", got, tt.want)
 			}
+			var remaining []string
+			for _, rowKey := range tt.fields.client.(*hbasetest.Client).RowKeys(storeOptions.Table) {
+				remaining = append(remaining, string(rowKey))
+			}
+			if !reflect.DeepEqual(remaining, tt.wantRemaining) {
+				t.Errorf("server.DeleteRange() remaining = %v, want %v", remaining, tt.wantRemaining)
+			}
 		})
 	}
 }
 
 func Test_server_GetMaxKey(t *testing.T) {
+	items := []*pb.SeqItem{
+		newTestItem("biz1", 1), newTestItem("biz1", 2), newTestItem("biz1", 3), newTestItem("biz1", 4), newTestItem("biz1", 5),
+	}
 	type fields struct {
 		UnimplementedSeqDbServer pb.UnimplementedSeqDbServer
 		client                   gohbase.Client
@@ -334,22 +1762,440 @@ func Test_server_GetMaxKey(t *testing.T) {
 		want    *pb.SeqKey
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name:   "max seq of biz",
+			fields: fields{client: newFakeClient(t, append(items, newTestItem("biz2", 9))...)},
+			args:   args{ctx: context.Background(), seqKey: &pb.SeqKey{BizId: []byte("biz1")}},
+			want:   &pb.SeqKey{BizId: []byte("biz1"), Seq: 5},
+		},
+		{
+			name:    "no items",
+			fields:  fields{client: newFakeClient(t, newTestItem("biz2", 9))},
+			args:    args{ctx: context.Background(), seqKey: &pb.SeqKey{BizId: []byte("biz1")}},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &server{
 				UnimplementedSeqDbServer: tt.fields.UnimplementedSeqDbServer,
-				client:                   tt.fields.client,
+				store:                    store.NewHBaseStore(tt.fields.client, storeOptions),
+				codec:                    rowkey.Legacy{},
 			}
 			got, err := s.GetMaxKey(tt.args.ctx, tt.args.seqKey)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("server.GetMaxKey() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
+			if !proto.Equal(got, tt.want) {
 				t.Errorf("server.GetMaxKey() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func Test_server_GetMinMaxKeyCount(t *testing.T) {
+	ctx := context.Background()
+	for _, codec := range []rowkey.Codec{rowkey.Legacy{}, rowkey.Binary{}} {
+		opts := dial.StoreOptions(config.Default().Table, codec)
+		seed := func(t *testing.T, seqs ...int32) (*hbasetest.Client, *server) {
+			client := hbasetest.NewClient()
+			s := &server{store: store.NewHBaseStore(client, opts), codec: codec}
+			for _, bizID := range []string{"biz1", "biz2"} {
+				for _, seq := range seqs {
+					if _, err := s.store.Put(ctx, []*pb.SeqItem{newTestItem(bizID, seq)}, 0); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			return client, s
+		}
+
+		tests := []struct {
+			name     string
+			seqs     []int32
+			min, max int32
+			maxScans int
+		}{
+			{name: "positive", seqs: []int32{3, 1, 7, 0}, min: 0, max: 7, maxScans: 1},
+			{name: "mixed", seqs: []int32{-5, 2, -1, math.MaxInt32}, min: -5, max: math.MaxInt32, maxScans: 1},
+			{name: "negative", seqs: []int32{-5, -1, math.MinInt32}, min: math.MinInt32, max: -1, maxScans: 2},
+		}
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%T/%s", codec, tt.name), func(t *testing.T) {
+				client, s := seed(t, tt.seqs...)
+				before := client.ScanRPCs()
+				got, err := s.GetMaxKey(ctx, &pb.SeqKey{BizId: []byte("biz1")})
+				if err != nil || got.Seq != tt.max || string(got.BizId) != "biz1" {
+					t.Errorf("GetMaxKey() = %v, %v, want seq %d", got, err, tt.max)
+				}
+				if scans := client.ScanRPCs() - before; scans > tt.maxScans {
+					t.Errorf("GetMaxKey() issued %d scan RPCs, want at most %d", scans, tt.maxScans)
+				}
+				if got, err := s.GetMinKey(ctx, &pb.SeqKey{BizId: []byte("biz1")}); err != nil || got.Seq != tt.min {
+					t.Errorf("GetMinKey() = %v, %v, want seq %d", got, err, tt.min)
+				}
+				if got, err := s.GetKeyCount(ctx, &pb.SeqKey{BizId: []byte("biz1")}); err != nil || got.Count != int64(len(tt.seqs)) {
+					t.Errorf("GetKeyCount() = %v, %v, want %d", got, err, len(tt.seqs))
+				}
+			})
+		}
+
+		t.Run(fmt.Sprintf("%T/not found", codec), func(t *testing.T) {
+			_, s := seed(t, 1)
+			missing := &pb.SeqKey{BizId: []byte("biz3")}
+			if _, err := s.GetMaxKey(ctx, missing); status.Code(err) != codes.NotFound {
+				t.Errorf("GetMaxKey() error = %v, want NotFound", err)
+			}
+			if _, err := s.GetMinKey(ctx, missing); status.Code(err) != codes.NotFound {
+				t.Errorf("GetMinKey() error = %v, want NotFound", err)
+			}
+			if got, err := s.GetKeyCount(ctx, missing); err != nil || got.Count != 0 {
+				t.Errorf("GetKeyCount() = %v, %v, want 0", got, err)
+			}
+		})
+	}
+}
+
+// newBufconnClient 在 bufconn 上启动使用 st 作为存储后端的 gRPC 服务，返回连接到它的客户端
+func newBufconnClient(t *testing.T, st store.SeqStore) pb.SeqDbClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(tenant.UnaryServerInterceptor(), validate.UnaryServerInterceptor(), apierr.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(tenant.StreamServerInterceptor(), validate.StreamServerInterceptor(), apierr.StreamServerInterceptor()),
+	)
+	pb.RegisterSeqDbServer(s, &server{store: st, codec: rowkey.Legacy{}, hub: watch.NewHub()})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial bufnet: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewSeqDbClient(conn)
+}
+
+// 通过 bufconn 对整个 gRPC 服务做端到端测试，存储后端为内存 HBase
+func TestSeqDbEndToEnd(t *testing.T) {
+	fake := hbasetest.NewClient()
+	client := newBufconnClient(t, store.NewHBaseStore(fake, storeOptions))
+	ctx := context.Background()
+
+	var items []*pb.SeqItem
+	for seq := int32(1); seq <= 5; seq++ {
+		items = append(items, newTestItem("biz1", seq))
+	}
+	if _, err := client.BatchPut(ctx, &pb.SeqItemsList{ItemsList: []*pb.SeqItems{
+		{Items: items[:3]},
+		{Items: items[3:]},
+	}}); err != nil {
+		t.Fatalf("BatchPut failed: %v", err)
+	}
+	if _, err := client.Put(ctx, &pb.SeqItems{Items: []*pb.SeqItem{newTestItem("biz2", 1)}}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	got, err := client.Get(ctx, &pb.GetReq{BizId: []byte("biz1"), Seq: 3})
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !proto.Equal(got, items[2]) {
+		t.Errorf("Get = %v, want %v", got, items[2])
+	}
+
+	batchGetResp, err := client.BatchGet(ctx, &pb.SeqKeys{Keys: []*pb.SeqKey{
+		{BizId: []byte("biz2"), Seq: 1},
+		{BizId: []byte("biz2"), Seq: 2},
+	}})
+	if err != nil {
+		t.Fatalf("BatchGet failed: %v", err)
+	}
+	if !batchGetResp.Results[0].Found || batchGetResp.Results[1].Found {
+		t.Errorf("BatchGet found = [%v %v], want [true false]",
+			batchGetResp.Results[0].Found, batchGetResp.Results[1].Found)
+	}
+
+	maxKey, err := client.GetMaxKey(ctx, &pb.SeqKey{BizId: []byte("biz1")})
+	if err != nil {
+		t.Fatalf("GetMaxKey failed: %v", err)
+	}
+	if maxKey.Seq != 5 {
+		t.Errorf("GetMaxKey seq = %d, want 5", maxKey.Seq)
+	}
+
+	rangeReq := &pb.RangeReq{
+		Start:   &pb.SeqKey{BizId: []byte("biz1"), Seq: 4},
+		End:     &pb.SeqKey{BizId: []byte("biz1"), Seq: 2},
+		Reverse: true,
+		Option:  pb.RangeOption_WithoutEnd,
+	}
+	rangeResp, err := client.QueryRange(ctx, rangeReq)
+	if err != nil {
+		t.Fatalf("QueryRange failed: %v", err)
+	}
+	if len(rangeResp.Items) != 2 {
+		t.Errorf("QueryRange returned %d items, want 2", len(rangeResp.Items))
+	}
+
+	if _, err := client.DeleteRange(ctx, rangeReq); err != nil {
+		t.Fatalf("DeleteRange failed: %v", err)
+	}
+	rangeResp, err = client.QueryRange(ctx, rangeReq)
+	if err != nil {
+		t.Fatalf("QueryRange after delete failed: %v", err)
+	}
+	if len(rangeResp.Items) != 0 {
+		t.Errorf("QueryRange after delete returned %d items, want 0", len(rangeResp.Items))
+	}
+	if rows := fake.RowKeys(storeOptions.Table); len(rows) != 4 {
+		t.Errorf("%d rows left after DeleteRange, want 4", len(rows))
+	}
+}
+
+// 不同 namespace 的请求路由到各自的表，数据和 AllocateSeq 计数器互相隔离
+func TestSeqDbNamespaces(t *testing.T) {
+	open := func(table string) store.SeqStore {
+		opts := storeOptions
+		opts.Table = table
+		return store.NewMemoryStore(opts)
+	}
+	client := newBufconnClient(t, tenant.NewStore(open(storeOptions.Table), "seqdb", open, nil))
+	withNamespace := func(ns string) context.Context {
+		if ns == "" {
+			return context.Background()
+		}
+		return metadata.AppendToOutgoingContext(context.Background(), tenant.MetadataKey, ns)
+	}
+
+	for i, ns := range []string{"", "t1", "t2"} {
+		ctx := withNamespace(ns)
+		items := make([]*pb.SeqItem, i+1)
+		for j := range items {
+			items[j] = newTestItem("biz1", int32(j+1))
+		}
+		if _, err := client.Put(ctx, &pb.SeqItems{Items: items}); err != nil {
+			t.Fatalf("Put(%q) error = %v", ns, err)
+		}
+	}
+	for i, ns := range []string{"", "t1", "t2"} {
+		ctx := withNamespace(ns)
+		count, err := client.GetKeyCount(ctx, &pb.SeqKey{BizId: []byte("biz1")})
+		if err != nil {
+			t.Fatalf("GetKeyCount(%q) error = %v", ns, err)
+		}
+		if count.Count != int64(i+1) {
+			t.Errorf("GetKeyCount(%q) = %d, want %d", ns, count.Count, i+1)
+		}
+		resp, err := client.AllocateSeq(ctx, &pb.AllocateSeqReq{BizId: []byte("biz1"), Count: 1})
+		if err != nil {
+			t.Fatalf("AllocateSeq(%q) error = %v", ns, err)
+		}
+		if resp.First != int32(i+2) {
+			t.Errorf("AllocateSeq(%q).First = %d, want %d", ns, resp.First, i+2)
+		}
+	}
+
+	_, err := client.GetKeyCount(withNamespace("bad:ns"), &pb.SeqKey{BizId: []byte("biz1")})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetKeyCount(invalid namespace) error = %v, want InvalidArgument", err)
+	}
+}
+
+// 不合法的请求在到达 handler 之前被拒绝，不会 panic 或扫描其他 BizId 的数据
+func TestSeqDbValidation(t *testing.T) {
+	fake := hbasetest.NewClient()
+	client := newBufconnClient(t, store.NewHBaseStore(fake, storeOptions))
+	ctx := context.Background()
+
+	_, err := client.Put(ctx, &pb.SeqItems{Items: []*pb.SeqItem{newTestItem("biz1", 1), {Value: []byte("no key")}}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Put(missing key) error = %v, want InvalidArgument", err)
+	}
+	if rows := fake.RowKeys(storeOptions.Table); len(rows) != 0 {
+		t.Errorf("%d rows written by a rejected Put", len(rows))
+	}
+	var fields []string
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range badRequest.FieldViolations {
+				fields = append(fields, v.Field)
+			}
+		}
+	}
+	if want := []string{"items[1].key"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("field violations = %v, want %v", fields, want)
+	}
+
+	stream, err := client.StreamRange(ctx, &pb.RangeReq{Start: &pb.SeqKey{BizId: []byte("biz1")}, End: &pb.SeqKey{BizId: []byte("biz2"), Seq: 5}})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("StreamRange(cross biz) error = %v, want InvalidArgument", err)
+	}
+}
+
+// Watch 先回放 from_seq 之后的已有数据，再推送各种写入方式新写入的数据，断开后按 seq 续传
+func TestSeqDbWatch(t *testing.T) {
+	client := newBufconnClient(t, store.NewMemoryStore(storeOptions))
+	ctx := context.Background()
+	put := func(items ...*pb.SeqItem) {
+		t.Helper()
+		if _, err := client.Put(ctx, &pb.SeqItems{Items: items}); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	recv := func(stream pb.SeqDb_WatchClient, want ...int32) {
+		t.Helper()
+		for _, seq := range want {
+			item, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("Recv() error = %v, want seq %d", err, seq)
+			}
+			if !proto.Equal(item, newTestItem("biz1", seq)) {
+				t.Fatalf("Recv() = %v, want seq %d", item, seq)
+			}
+		}
+	}
+	put(newTestItem("biz1", 1), newTestItem("biz1", 2), newTestItem("biz1", 3))
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	stream, err := client.Watch(watchCtx, &pb.WatchReq{BizId: []byte("biz1"), FromSeq: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recv(stream, 2, 3)
+	// 其他 BizId 和 from_seq 之前的写入不推送
+	put(newTestItem("biz2", 4), newTestItem("biz1", 1), newTestItem("biz1", 4))
+	recv(stream, 4)
+	if _, err := client.PutIfAbsent(ctx, &pb.SeqItems{Items: []*pb.SeqItem{newTestItem("biz1", 4), newTestItem("biz1", 5)}}); err != nil {
+		t.Fatal(err)
+	}
+	recv(stream, 5)
+	if _, err := client.BatchPut(ctx, &pb.SeqItemsList{ItemsList: []*pb.SeqItems{{Items: []*pb.SeqItem{newTestItem("biz1", 6)}}}}); err != nil {
+		t.Fatal(err)
+	}
+	recv(stream, 6)
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("Recv() after cancel error = %v, want Canceled", err)
+	}
+
+	// 断开期间的写入在续传时回放
+	put(newTestItem("biz1", 7))
+	stream, err = client.Watch(ctx, &pb.WatchReq{BizId: []byte("biz1"), FromSeq: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recv(stream, 6, 7)
+	put(newTestItem("biz1", 8))
+	recv(stream, 8)
+
+	stream, err = client.Watch(ctx, &pb.WatchReq{FromSeq: 1})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Watch(missing biz_id) error = %v, want InvalidArgument", err)
+	}
+}
+
+// scanHookStore 在第一次 Scan 之前和之后分别调用 before 和 after，模拟回放期间的写入
+type scanHookStore struct {
+	store.SeqStore
+	before, after func()
+}
+
+func (s *scanHookStore) Scan(ctx context.Context, rng store.Range) (store.Scanner, error) {
+	if s.before != nil {
+		s.before()
+		s.before = nil
+	}
+	scanner, err := s.SeqStore.Scan(ctx, rng)
+	if s.after != nil {
+		s.after()
+		s.after = nil
+	}
+	return scanner, err
+}
+
+func TestSeqDbWatch_ReplayOverlap(t *testing.T) {
+	writers := map[string]func(client pb.SeqDbClient, item, old *pb.SeqItem) error{
+		"put": func(client pb.SeqDbClient, item, old *pb.SeqItem) error {
+			_, err := client.Put(context.Background(), &pb.SeqItems{Items: []*pb.SeqItem{item}})
+			return err
+		},
+		"compare and swap": func(client pb.SeqDbClient, item, old *pb.SeqItem) error {
+			// 请求中的时间戳不写入也不推送，推送的是服务端指定的时间戳
+			item = &pb.SeqItem{Key: item.Key, Value: item.Value, Timestamp: 1}
+			resp, err := client.CompareAndSwap(context.Background(), &pb.CasReq{Items: []*pb.CasItem{{Item: item, ExpectedValue: old.GetValue()}}})
+			if err == nil && !resp.Results[0].Applied {
+				err = fmt.Errorf("not applied: %v", resp.Results[0])
+			}
+			return err
+		},
+	}
+	for name, write := range writers {
+		st := &scanHookStore{SeqStore: store.NewMemoryStore(storeOptions)}
+		client := newBufconnClient(t, st)
+		ctx := context.Background()
+		put := func(item *pb.SeqItem) {
+			if _, err := client.Put(ctx, &pb.SeqItems{Items: []*pb.SeqItem{item}}); err != nil {
+				t.Errorf("Put() error = %v", err)
+			}
+		}
+		for seq := int32(1); seq <= 3; seq++ {
+			put(newTestItem("biz1", seq))
+		}
+		rewritten := &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte("biz1"), Seq: 2}, Value: []byte("rewritten")}
+		overwritten := &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte("biz1"), Seq: 1}, Value: []byte("overwritten")}
+		// 扫描前的写入已包含在回放中，不重复推送；扫描后覆盖的 seq 在回放的位置之前，仍要推送
+		st.before = func() {
+			if err := write(client, rewritten, newTestItem("biz1", 2)); err != nil {
+				t.Errorf("%s: write %v error = %v", name, rewritten.Key, err)
+			}
+		}
+		st.after = func() {
+			if err := write(client, overwritten, newTestItem("biz1", 1)); err != nil {
+				t.Errorf("%s: write %v error = %v", name, overwritten.Key, err)
+			}
+		}
+
+		watchCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		stream, err := client.Watch(watchCtx, &pb.WatchReq{BizId: []byte("biz1"), FromSeq: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []*pb.SeqItem{newTestItem("biz1", 1), rewritten, newTestItem("biz1", 3), overwritten, newTestItem("biz1", 4)}
+		for i, w := range want {
+			if i == len(want)-1 {
+				put(w)
+			}
+			item, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("%s: Recv() error = %v, want %v", name, err, w)
+			}
+			if !proto.Equal(item, w) {
+				t.Fatalf("%s: Recv() = %v, want %v", name, item, w)
+			}
+		}
+		cancel()
+	}
+}
+
+func Test_watchAborted(t *testing.T) {
+	err := watchAborted([]byte("biz1"), 8)
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("watchAborted() = %v, want Aborted", err)
+	}
+	if key := apierr.KeyOf(err); !proto.Equal(key, &pb.SeqKey{BizId: []byte("biz1"), Seq: 8}) {
+		t.Errorf("KeyOf() = %v, want the resume position", key)
+	}
+	if key := apierr.KeyOf(watchAborted([]byte("biz1"), math.MaxInt32+1)); key != nil {
+		t.Errorf("KeyOf() past the last seq = %v, want nil", key)
+	}
+}
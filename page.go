@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+
+	"go-hbase-demo/store"
+)
+
+// pageTokenVersion 是 page_token 的格式版本
+// page_token 对调用方不透明，内容为 {版本}{下一页第一行的 rowkey}
+const pageTokenVersion = 1
+
+var errInvalidPageToken = errors.New("invalid page_token")
+
+// encodePageToken 生成从 rowKey（含）继续扫描的 page_token
+func encodePageToken(rowKey []byte) []byte {
+	return append([]byte{pageTokenVersion}, rowKey...)
+}
+
+// decodePageToken 解析 page_token，返回下一页第一行的 rowkey
+func decodePageToken(token []byte) ([]byte, error) {
+	if len(token) < 2 || token[0] != pageTokenVersion {
+		return nil, errInvalidPageToken
+	}
+	return token[1:], nil
+}
+
+// resumeRange 将扫描区间的起点移到 page_token 指向的行，token 指向区间外时返回错误
+func resumeRange(rng store.Range, token []byte) (store.Range, error) {
+	rowKey, err := decodePageToken(token)
+	if err != nil {
+		return rng, err
+	}
+	var inRange bool
+	if !rng.Reverse {
+		inRange = bytes.Compare(rowKey, rng.StartRow) >= 0 && (len(rng.StopRow) == 0 || bytes.Compare(rowKey, rng.StopRow) < 0)
+	} else {
+		inRange = (len(rng.StartRow) == 0 || bytes.Compare(rowKey, rng.StartRow) <= 0) && bytes.Compare(rowKey, rng.StopRow) > 0
+	}
+	if !inRange {
+		return rng, errInvalidPageToken
+	}
+	rng.StartRow = rowKey
+	return rng, nil
+}
+
+// resumeRanges 从 page_token 指向的行继续扫描 ranges（按结果顺序排列且互不重叠），
+// 返回从包含该行的范围开始的剩余范围
+func resumeRanges(ranges []store.Range, token []byte) ([]store.Range, error) {
+	for i, rng := range ranges {
+		if resumed, err := resumeRange(rng, token); err == nil {
+			ranges = append([]store.Range{resumed}, ranges[i+1:]...)
+			return ranges, nil
+		}
+	}
+	return nil, errInvalidPageToken
+}
@@ -0,0 +1,235 @@
+// Package retention 实现 SeqDb 的数据保留策略
+//
+// 保留策略包括两部分：写入时的 TTL 由 HBase 的 cell TTL 实现，过期的数据由 HBase 自动清理；
+// MaxSeqs 由 Reaper 在后台定期扫描，删除每个 BizId 中超出最新 MaxSeqs 个 seq 的行
+package retention
+
+import (
+	"context"
+	"expvar"
+	"io"
+	"log"
+	"time"
+
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/seqrange"
+	"go-hbase-demo/store"
+)
+
+// Policy 是一个 BizId 的保留策略
+type Policy struct {
+	TTL     time.Duration // 写入的数据在 TTL 后过期，0 表示不过期
+	MaxSeqs int           // 只保留最新的 MaxSeqs 个 seq，0 表示不限制
+}
+
+// Policies 是所有 BizId 的保留策略
+type Policies struct {
+	Default Policy
+	Biz     map[string]Policy // 指定 BizId 的策略，完全替代 Default
+}
+
+// For 返回 bizID 的保留策略
+func (p Policies) For(bizID []byte) Policy {
+	if policy, ok := p.Biz[string(bizID)]; ok {
+		return policy
+	}
+	return p.Default
+}
+
+// TTL 返回写入 bizID 时使用的 TTL：requested 与策略的 TTL 中较短的一个，0 表示不限制
+func (p Policies) TTL(bizID []byte, requested time.Duration) time.Duration {
+	ttl := p.For(bizID).TTL
+	if ttl == 0 || requested > 0 && requested < ttl {
+		return requested
+	}
+	return ttl
+}
+
+// trimming 判断是否有 BizId 设置了 MaxSeqs
+func (p Policies) trimming() bool {
+	if p.Default.MaxSeqs > 0 {
+		return true
+	}
+	for _, policy := range p.Biz {
+		if policy.MaxSeqs > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// reapBatchSize 是 Reaper 每次 Delete 删除的行数
+const reapBatchSize = 1000
+
+// metrics 是 Reaper 的 expvar 指标，在 /debug/vars 的 seqdb_reaper 下
+var (
+	metrics        = expvar.NewMap("seqdb_reaper")
+	metricRuns     = new(expvar.Int) // 清理次数
+	metricErrors   = new(expvar.Int) // 失败的清理次数
+	metricScanned  = new(expvar.Int) // 累计扫描的行数
+	metricDeleted  = new(expvar.Int) // 累计删除的行数
+	metricLastRun  = new(expvar.Int) // 最近一次清理的完成时间，Unix 秒
+	metricLastReap = new(expvar.Int) // 最近一次清理删除的行数
+)
+
+func init() {
+	metrics.Set("runs", metricRuns)
+	metrics.Set("errors", metricErrors)
+	metrics.Set("rows_scanned", metricScanned)
+	metrics.Set("rows_deleted", metricDeleted)
+	metrics.Set("last_run", metricLastRun)
+	metrics.Set("last_rows_deleted", metricLastReap)
+}
+
+// Stats 是一次清理的结果
+type Stats struct {
+	Scanned int64 // 扫描的行数
+	Deleted int64 // 删除的行数
+	BizIDs  int   // 删除了数据的 BizId 数
+}
+
+// Reaper 定期删除每个 BizId 中超出 MaxSeqs 的行
+// rowkey 按 seq 从新到旧排列（见 rowkey.Codec），因此每个 BizId 的前 MaxSeqs 行之后的行都可以删除
+type Reaper struct {
+	store    store.SeqStore
+	codec    rowkey.Codec
+	policies Policies
+	interval time.Duration
+}
+
+// Tables 是由多张表组成的存储后端，Reaper 逐表清理
+type Tables interface {
+	// Tables 返回当前所有表的存储后端
+	Tables() []store.SeqStore
+}
+
+// NewReaper 创建 Reaper，codec 须与 st 使用的一致；st 实现 Tables 时（如 tenant.Store）每次清理其中的所有表
+func NewReaper(st store.SeqStore, codec rowkey.Codec, policies Policies, interval time.Duration) *Reaper {
+	return &Reaper{store: st, codec: codec, policies: policies, interval: interval}
+}
+
+// Run 每隔 interval 清理一次，直到 ctx 结束；interval 为 0 或没有 BizId 设置 MaxSeqs 时直接返回
+func (r *Reaper) Run(ctx context.Context) {
+	if r.interval <= 0 || !r.policies.trimming() {
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		stats, err := r.ReapOnce(ctx)
+		if err != nil {
+			log.Printf("Reap failed after deleting %d rows: %v", stats.Deleted, err)
+			continue
+		}
+		log.Printf("Reap finished, %d rows scanned, %d rows of %d biz_ids deleted", stats.Scanned, stats.Deleted, stats.BizIDs)
+	}
+}
+
+// ReapOnce 执行一次清理
+// 默认策略设置了 MaxSeqs 时扫描整张表，否则只扫描设置了 MaxSeqs 的 BizId；出错时返回已完成部分的统计
+func (r *Reaper) ReapOnce(ctx context.Context) (Stats, error) {
+	var stats Stats
+	err := r.reap(ctx, &stats)
+	metricRuns.Add(1)
+	metricScanned.Add(stats.Scanned)
+	metricDeleted.Add(stats.Deleted)
+	metricLastReap.Set(stats.Deleted)
+	metricLastRun.Set(time.Now().Unix())
+	if err != nil {
+		metricErrors.Add(1)
+	}
+	return stats, err
+}
+
+func (r *Reaper) reap(ctx context.Context, stats *Stats) error {
+	tables := []store.SeqStore{r.store}
+	if t, ok := r.store.(Tables); ok {
+		tables = t.Tables()
+	}
+	for _, st := range tables {
+		if err := r.reapTable(ctx, st, stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reapTable 清理一张表
+func (r *Reaper) reapTable(ctx context.Context, st store.SeqStore, stats *Stats) error {
+	if r.policies.Default.MaxSeqs > 0 {
+		return r.reapRange(ctx, st, store.Range{}, stats)
+	}
+	for bizID, policy := range r.policies.Biz {
+		if policy.MaxSeqs == 0 {
+			continue
+		}
+		if err := r.reapRange(ctx, st, seqrange.Biz(r.codec, []byte(bizID), false), stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reapRange 按 rowkey 顺序扫描 st 中的 rng，删除每个 BizId 超出 MaxSeqs 的行
+// 同一 BizId 的行是连续的，BizId 变化时重新计数；无法解码的行（如其他编码写入的行）被跳过
+func (r *Reaper) reapRange(ctx context.Context, st store.SeqStore, rng store.Range, stats *Stats) error {
+	rng.KeysOnly = true
+	scanner, err := st.Scan(ctx, rng)
+	if err != nil {
+		return err
+	}
+	defer scanner.Close()
+
+	var (
+		bizID   string
+		policy  Policy
+		count   int
+		trimmed bool
+		batch   [][]byte
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := st.Delete(ctx, batch); err != nil {
+			return err
+		}
+		stats.Deleted += int64(len(batch))
+		batch = nil
+		return nil
+	}
+	for {
+		row, err := scanner.Next()
+		if err == io.EOF {
+			return flush()
+		}
+		if err != nil {
+			return err
+		}
+		stats.Scanned++
+		key, err := r.codec.Decode(row.Key)
+		if err != nil {
+			continue
+		}
+		if string(key.BizId) != bizID || count == 0 {
+			bizID, policy, count, trimmed = string(key.BizId), r.policies.For(key.BizId), 0, false
+		}
+		if count++; policy.MaxSeqs == 0 || count <= policy.MaxSeqs {
+			continue
+		}
+		if !trimmed {
+			trimmed = true
+			stats.BizIDs++
+		}
+		if batch = append(batch, row.Key); len(batch) == reapBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package retention
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/seqrange"
+	"go-hbase-demo/store"
+)
+
+func TestPolicies_TTL(t *testing.T) {
+	policies := Policies{
+		Default: Policy{TTL: time.Hour},
+		Biz:     map[string]Policy{"forever": {}, "short": {TTL: time.Minute}},
+	}
+	tests := []struct {
+		biz       string
+		requested time.Duration
+		want      time.Duration
+	}{
+		{"other", 0, time.Hour},
+		{"other", time.Minute, time.Minute},
+		{"other", 2 * time.Hour, time.Hour},
+		{"forever", 0, 0},
+		{"forever", time.Second, time.Second},
+		{"short", time.Hour, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := policies.TTL([]byte(tt.biz), tt.requested); got != tt.want {
+			t.Errorf("TTL(%q, %v) = %v, want %v", tt.biz, tt.requested, got, tt.want)
+		}
+	}
+}
+
+// newStore 为每个 BizId 写入 seq 1..n
+func newStore(t *testing.T, codec rowkey.Codec, seqs map[string]int) store.SeqStore {
+	t.Helper()
+	s := store.NewMemoryStore(store.Options{RowKey: codec.Encode})
+	var items []*pb.SeqItem
+	for biz, n := range seqs {
+		for seq := 1; seq <= n; seq++ {
+			items = append(items, &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte(biz), Seq: int32(seq)}})
+		}
+	}
+	if _, err := s.Put(context.Background(), items, 0); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// remaining 返回 BizId 剩余的 seq，按 rowkey 顺序排列
+func remaining(t *testing.T, s store.SeqStore, codec rowkey.Codec, biz string) []int32 {
+	t.Helper()
+	scanner, err := s.Scan(context.Background(), seqrange.Biz(codec, []byte(biz), false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer scanner.Close()
+	var seqs []int32
+	for row, err := scanner.Next(); err == nil; row, err = scanner.Next() {
+		key, err := codec.Decode(row.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seqs = append(seqs, key.Seq)
+	}
+	return seqs
+}
+
+func TestReaper_ReapOnce(t *testing.T) {
+	salted, err := rowkey.NewSalted(4, rowkey.XXHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seqs := map[string]int{"a": 5, "b": 2, "c": 4, "keep": 6}
+	tests := []struct {
+		name     string
+		policies Policies
+		want     map[string][]int32
+		stats    Stats
+	}{
+		{
+			name:     "default",
+			policies: Policies{Default: Policy{MaxSeqs: 3}, Biz: map[string]Policy{"keep": {}}},
+			want:     map[string][]int32{"a": {5, 4, 3}, "b": {2, 1}, "c": {4, 3, 2}, "keep": {6, 5, 4, 3, 2, 1}},
+			stats:    Stats{Scanned: 17, Deleted: 3, BizIDs: 2},
+		},
+		{
+			name:     "biz only",
+			policies: Policies{Biz: map[string]Policy{"a": {MaxSeqs: 1}, "b": {MaxSeqs: 5}}},
+			want:     map[string][]int32{"a": {5}, "b": {2, 1}, "c": {4, 3, 2, 1}, "keep": {6, 5, 4, 3, 2, 1}},
+			stats:    Stats{Scanned: 7, Deleted: 4, BizIDs: 1},
+		},
+	}
+	for _, codec := range []rowkey.Codec{rowkey.Binary{}, salted} {
+		for _, tt := range tests {
+			s := newStore(t, codec, seqs)
+			stats, err := NewReaper(s, codec, tt.policies, time.Hour).ReapOnce(context.Background())
+			if err != nil {
+				t.Fatalf("%s: ReapOnce() error = %v", tt.name, err)
+			}
+			if stats != tt.stats {
+				t.Errorf("%s: ReapOnce() = %+v, want %+v", tt.name, stats, tt.stats)
+			}
+			for biz, want := range tt.want {
+				if got := remaining(t, s, codec, biz); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: biz %q remaining %v, want %v", tt.name, biz, got, want)
+				}
+			}
+		}
+	}
+}
+
+// tables 由多张表组成，其他方法访问第一张表
+type tables struct {
+	store.SeqStore
+	all []store.SeqStore
+}
+
+func (t tables) Tables() []store.SeqStore { return t.all }
+
+func TestReaper_Tables(t *testing.T) {
+	codec := rowkey.Binary{}
+	t1 := newStore(t, codec, map[string]int{"a": 3})
+	t2 := newStore(t, codec, map[string]int{"a": 4, "b": 1})
+	s := tables{SeqStore: t1, all: []store.SeqStore{t1, t2}}
+	stats, err := NewReaper(s, codec, Policies{Default: Policy{MaxSeqs: 1}}, time.Hour).ReapOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Stats{Scanned: 8, Deleted: 5, BizIDs: 2}); stats != want {
+		t.Errorf("ReapOnce() = %+v, want %+v", stats, want)
+	}
+	for i, st := range s.all {
+		if got := remaining(t, st, codec, "a"); !reflect.DeepEqual(got, []int32{int32(3 + i)}) {
+			t.Errorf("table %d: biz a remaining %v, want [%d]", i, got, 3+i)
+		}
+	}
+}
+
+func TestReaper_RunDisabled(t *testing.T) {
+	s := newStore(t, rowkey.Binary{}, map[string]int{"a": 3})
+	done := make(chan struct{})
+	go func() {
+		// 没有 BizId 设置 MaxSeqs 时 Run 立即返回
+		NewReaper(s, rowkey.Binary{}, Policies{Default: Policy{TTL: time.Hour}}, time.Millisecond).Run(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not return")
+	}
+}
@@ -0,0 +1,54 @@
+package rowkey
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	pb "go-hbase-demo/cloudpb"
+)
+
+// Binary 是长度前缀的二进制格式：{uvarint(len(BizId))}{BizId}{Seq}
+//
+// BizId 可以包含任意字节，不同 BizId 的 rowkey 不会互为前缀
+// Seq 固定 4 字节，同一 BizId 内按 Seq 降序排列
+type Binary struct{}
+
+// AppendPrefix 向 b 追加 BizId 对应的 rowkey 前缀，同一 BizId 的所有行都以该前缀开头
+func (Binary) AppendPrefix(b []byte, bizID []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(bizID)))
+	return append(b, bizID...)
+}
+
+// Prefix 返回 {uvarint(len(BizId))}{BizId}
+func (c Binary) Prefix(bizID []byte) []byte {
+	return c.AppendPrefix(nil, bizID)
+}
+
+// Ordinal 返回 seq 按 int32 降序的序号，即 rowkey 末尾 4 字节的值
+func (Binary) Ordinal(seq int32) uint32 { return descOrdinal(seq) }
+
+// SeqAt 是 Ordinal 的逆映射
+func (Binary) SeqAt(ordinal uint32) int32 { return descSeqAt(ordinal) }
+
+// Encode 将 SeqKey 编码为 Binary 格式的 rowkey
+func (c Binary) Encode(key *pb.SeqKey) []byte {
+	return c.appendKey(nil, key)
+}
+
+func (c Binary) appendKey(b []byte, key *pb.SeqKey) []byte {
+	b = c.AppendPrefix(b, key.GetBizId())
+	return appendSeq(b, key.GetSeq())
+}
+
+// Decode 解析 Binary 格式的 rowkey
+func (c Binary) Decode(row []byte) (*pb.SeqKey, error) {
+	n, size := binary.Uvarint(row)
+	if size <= 0 {
+		return nil, invalid(row, "malformed biz id length")
+	}
+	rest := row[size:]
+	if uint64(len(rest)) != n+seqLen {
+		return nil, invalid(row, "length mismatch")
+	}
+	return &pb.SeqKey{BizId: bytes.Clone(rest[:n]), Seq: decodeSeq(rest[n:])}, nil
+}
@@ -0,0 +1,66 @@
+package rowkey
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	pb "go-hbase-demo/cloudpb"
+)
+
+// Legacy 是最初的 rowkey 格式：{BizId 最后一个字节}_{BizId}_{^uint32(Seq) 的 10 位十进制}
+//
+// 由于盐值只取 BizId 的最后一个字节，连续的 BizId 会集中在少数 region；
+// BizId 中含有 '_' 时，不同 BizId 的 rowkey 可能共享前缀，按前缀扫描会混入其他 BizId 的行
+// 仅为兼容已有数据保留，新表应使用 Binary 或 Salted
+type Legacy struct{}
+
+// legacySeqLen 是 "_%010d" 的长度
+const legacySeqLen = 11
+
+// Hash 返回 Legacy 格式使用的盐值，即 BizId 的最后一个字节
+func (Legacy) Hash(bizID []byte) []byte {
+	if len(bizID) == 0 {
+		return nil
+	}
+	return bizID[len(bizID)-1:]
+}
+
+// Encode 将 SeqKey 编码为 Legacy 格式的 rowkey
+func (c Legacy) Encode(key *pb.SeqKey) []byte {
+	// 使用按位取反（^seq）实现 uint32.Max - seq，使 Seq 大的行排在前面
+	return []byte(fmt.Sprintf("%s_%s_%010d", c.Hash(key.GetBizId()), key.GetBizId(), ^uint32(key.GetSeq())))
+}
+
+// Prefix 返回 {盐值}_{BizId}_
+func (c Legacy) Prefix(bizID []byte) []byte {
+	return []byte(fmt.Sprintf("%s_%s_", c.Hash(bizID), bizID))
+}
+
+// Ordinal 返回 ^uint32(seq)，即按 uint32 解释的 seq 降序，负数 seq 排在最前
+func (Legacy) Ordinal(seq int32) uint32 { return ^uint32(seq) }
+
+// SeqAt 是 Ordinal 的逆映射
+func (Legacy) SeqAt(ordinal uint32) int32 { return int32(^ordinal) }
+
+// Decode 解析 Legacy 格式的 rowkey，盐值固定为 1 个字节（BizId 为空时没有盐值）
+func (c Legacy) Decode(row []byte) (*pb.SeqKey, error) {
+	if len(row) < legacySeqLen+1 || row[len(row)-legacySeqLen] != '_' {
+		return nil, invalid(row, "missing seq suffix")
+	}
+	rev, err := strconv.ParseUint(string(row[len(row)-legacySeqLen+1:]), 10, 32)
+	if err != nil {
+		return nil, invalid(row, "malformed seq")
+	}
+
+	var bizID []byte
+	switch prefix := row[:len(row)-legacySeqLen]; {
+	case len(prefix) == 1 && prefix[0] == '_':
+		// 空 BizId：盐值为空，前缀只有分隔符
+	case len(prefix) >= 3 && prefix[1] == '_' && prefix[0] == prefix[len(prefix)-1]:
+		bizID = bytes.Clone(prefix[2:])
+	default:
+		return nil, invalid(row, "malformed biz id")
+	}
+	return &pb.SeqKey{BizId: bizID, Seq: int32(^uint32(rev))}, nil
+}
@@ -0,0 +1,81 @@
+// Package rowkey 定义 SeqKey 与 HBase rowkey 之间的编码方式
+//
+// 所有编码都保证同一 BizId 的行在表中连续存放，并按 Seq 从大到小排列，
+// 这样最新的 Seq 总是该 BizId 的第一行
+package rowkey
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	pb "go-hbase-demo/cloudpb"
+)
+
+// ErrInvalidRowKey 表示 rowkey 不是由当前编码生成的
+var ErrInvalidRowKey = errors.New("rowkey: invalid row key")
+
+// Codec 是 SeqKey 与 rowkey 之间的编解码器
+type Codec interface {
+	// Encode 将 SeqKey 编码为 rowkey
+	Encode(key *pb.SeqKey) []byte
+	// Decode 从 rowkey 还原 SeqKey，rowkey 格式不符时返回 ErrInvalidRowKey
+	Decode(row []byte) (*pb.SeqKey, error)
+	// Prefix 返回 BizId 的 rowkey 前缀，该 BizId 的所有 rowkey 都以它开头且比它大
+	Prefix(bizID []byte) []byte
+	// Ordinal 返回 seq 在同一 BizId 内的排列序号，序号越小 rowkey 越小，与 seq 一一对应
+	Ordinal(seq int32) uint32
+	// SeqAt 是 Ordinal 的逆映射
+	SeqAt(ordinal uint32) int32
+}
+
+// 编码名称，用于配置文件中的 table.row_key
+const (
+	NameLegacy = "legacy"
+	NameBinary = "binary"
+	NameSalted = "salted"
+)
+
+// New 根据名称创建编码器，buckets 和 hash 只对 salted 编码有效
+func New(name string, buckets int, hash string) (Codec, error) {
+	switch name {
+	case NameLegacy:
+		return Legacy{}, nil
+	case NameBinary:
+		return Binary{}, nil
+	case NameSalted:
+		fn, err := HashByName(hash)
+		if err != nil {
+			return nil, err
+		}
+		return NewSalted(buckets, fn)
+	}
+	return nil, fmt.Errorf("rowkey: unknown codec %q", name)
+}
+
+// seqLen 是二进制编码中 Seq 占用的字节数
+const seqLen = 4
+
+// descOrdinal 翻转符号位使 uint32 大小与 int32 一致，再按位取反得到降序
+func descOrdinal(seq int32) uint32 {
+	return ^(uint32(seq) ^ 1<<31)
+}
+
+// descSeqAt 是 descOrdinal 的逆映射
+func descSeqAt(ordinal uint32) int32 {
+	return int32(^ordinal ^ 1<<31)
+}
+
+// appendSeq 以 4 字节大端追加 Seq 的序号
+func appendSeq(b []byte, seq int32) []byte {
+	return binary.BigEndian.AppendUint32(b, descOrdinal(seq))
+}
+
+// decodeSeq 是 appendSeq 的逆过程
+func decodeSeq(b []byte) int32 {
+	return descSeqAt(binary.BigEndian.Uint32(b))
+}
+
+func invalid(row []byte, reason string) error {
+	return fmt.Errorf("%w %q: %s", ErrInvalidRowKey, row, reason)
+}
@@ -0,0 +1,189 @@
+package rowkey
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"sort"
+	"testing"
+
+	pb "go-hbase-demo/cloudpb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func mustSalted(t *testing.T, buckets int, hash HashFunc) *Salted {
+	t.Helper()
+	c, err := NewSalted(buckets, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestLegacy_Encode(t *testing.T) {
+	tests := []struct {
+		key  *pb.SeqKey
+		want string
+	}{
+		{&pb.SeqKey{BizId: []byte("biz1"), Seq: 1}, "1_biz1_4294967294"},
+		{&pb.SeqKey{BizId: []byte("a_b"), Seq: 0}, "b_a_b_4294967295"},
+		{&pb.SeqKey{Seq: -1}, "__0000000000"},
+	}
+	for _, tt := range tests {
+		if got := string(Legacy{}.Encode(tt.key)); got != tt.want {
+			t.Errorf("Encode(%v) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	codecs := map[string]Codec{
+		"legacy":         Legacy{},
+		"binary":         Binary{},
+		"salted/murmur3": mustSalted(t, 16, Murmur3),
+		"salted/xxhash":  mustSalted(t, 256, XXHash),
+	}
+	keys := []*pb.SeqKey{
+		{BizId: []byte("biz1"), Seq: 1},
+		{BizId: []byte("a_b_c"), Seq: 42},
+		{BizId: []byte("x"), Seq: math.MaxInt32},
+		{BizId: []byte("x"), Seq: math.MinInt32},
+		{BizId: []byte("x"), Seq: -7},
+		{BizId: nil, Seq: 3},
+	}
+	for name, codec := range codecs {
+		for _, key := range keys {
+			row := codec.Encode(key)
+			got, err := codec.Decode(row)
+			if err != nil {
+				t.Errorf("%s: Decode(%q) error = %v", name, row, err)
+				continue
+			}
+			if !proto.Equal(got, key) {
+				t.Errorf("%s: Decode(Encode(%v)) = %v", name, key, got)
+			}
+			if prefix := codec.Prefix(key.BizId); !bytes.HasPrefix(row, prefix) || len(row) <= len(prefix) {
+				t.Errorf("%s: Encode(%v) = %q, want longer row with prefix %q", name, key, row, prefix)
+			}
+			if seq := codec.SeqAt(codec.Ordinal(key.Seq)); seq != key.Seq {
+				t.Errorf("%s: SeqAt(Ordinal(%d)) = %d", name, key.Seq, seq)
+			}
+		}
+	}
+}
+
+// 新编码在同一 BizId 内按 Seq 降序排列，且不同 BizId 的行不交错
+func TestCodec_Order(t *testing.T) {
+	seqs := []int32{math.MinInt32, -100, -1, 0, 1, 2, 255, 256, 1 << 20, math.MaxInt32}
+	for name, codec := range map[string]Codec{"binary": Binary{}, "salted": mustSalted(t, 1, Murmur3)} {
+		var rows [][]byte
+		for _, bizID := range []string{"a", "a_", "ab", "b"} {
+			for _, seq := range seqs {
+				rows = append(rows, codec.Encode(&pb.SeqKey{BizId: []byte(bizID), Seq: seq}))
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool { return bytes.Compare(rows[i], rows[j]) < 0 })
+		for i := 1; i < len(seqs); i++ {
+			if codec.Ordinal(seqs[i]) >= codec.Ordinal(seqs[i-1]) {
+				t.Errorf("%s: Ordinal(%d) >= Ordinal(%d), want descending seq order", name, seqs[i], seqs[i-1])
+			}
+		}
+
+		var prev *pb.SeqKey
+		seen := map[string]bool{}
+		for _, row := range rows {
+			key, err := codec.Decode(row)
+			if err != nil {
+				t.Fatalf("%s: Decode(%q) error = %v", name, row, err)
+			}
+			if prev != nil && bytes.Equal(prev.BizId, key.BizId) {
+				if key.Seq >= prev.Seq {
+					t.Errorf("%s: seq %d sorted after %d", name, key.Seq, prev.Seq)
+				}
+			} else if seen[string(key.BizId)] {
+				t.Errorf("%s: rows of biz %q are not contiguous", name, key.BizId)
+			}
+			seen[string(key.BizId)] = true
+			prev = key
+		}
+	}
+}
+
+func TestSalted_Buckets(t *testing.T) {
+	c := mustSalted(t, 8, Murmur3)
+	counts := make([]int, c.Buckets())
+	for i := 0; i < 800; i++ {
+		row := c.Encode(&pb.SeqKey{BizId: []byte{byte(i >> 8), byte(i)}, Seq: 1})
+		counts[row[0]]++
+	}
+	for bucket, n := range counts {
+		if n == 0 {
+			t.Errorf("bucket %d is empty, want sequential biz ids spread over all buckets", bucket)
+		}
+	}
+
+	for _, buckets := range []int{0, MaxBuckets + 1} {
+		if _, err := NewSalted(buckets, Murmur3); err == nil {
+			t.Errorf("NewSalted(%d) error = nil, want error", buckets)
+		}
+	}
+}
+
+// 哈希值决定已写入数据所在的桶，更换实现时必须保持不变
+func TestHash(t *testing.T) {
+	tests := []struct {
+		bizID string
+		want  uint64
+	}{
+		{"", 0},
+		{"biz1", 3728040889},
+		{"hello", 613153351},
+		{"order-20240101", 4000291902},
+		{"a-much-longer-business-id-value", 517913852},
+	}
+	for _, tt := range tests {
+		if got := Murmur3([]byte(tt.bizID)); got != tt.want {
+			t.Errorf("Murmur3(%q) = %d, want %d", tt.bizID, got, tt.want)
+		}
+	}
+}
+
+func TestCodec_DecodeInvalid(t *testing.T) {
+	salted := mustSalted(t, 16, Murmur3)
+	good := salted.Encode(&pb.SeqKey{BizId: []byte("biz1"), Seq: 1})
+	wrongBucket := append([]byte{good[0] + 1}, good[1:]...)
+
+	tests := []struct {
+		name  string
+		codec Codec
+		row   []byte
+	}{
+		{"legacy too short", Legacy{}, []byte("1_")},
+		{"legacy bad seq", Legacy{}, []byte("1_biz1_42949672xx")},
+		{"legacy salt mismatch", Legacy{}, []byte("2_biz1_4294967294")},
+		{"binary length mismatch", Binary{}, []byte{5, 'a', 0, 0, 0, 0}},
+		{"binary empty", Binary{}, nil},
+		{"salted wrong bucket", salted, wrongBucket},
+		{"salted legacy row", salted, []byte("1_biz1_4294967294")},
+	}
+	for _, tt := range tests {
+		if _, err := tt.codec.Decode(tt.row); !errors.Is(err, ErrInvalidRowKey) {
+			t.Errorf("%s: Decode(%q) error = %v, want ErrInvalidRowKey", tt.name, tt.row, err)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{NameLegacy, NameBinary, NameSalted} {
+		if _, err := New(name, 16, "xxhash"); err != nil {
+			t.Errorf("New(%q) error = %v", name, err)
+		}
+	}
+	if _, err := New("unknown", 16, "xxhash"); err == nil {
+		t.Error("New(unknown) error = nil, want error")
+	}
+	if _, err := New(NameSalted, 16, "md5"); err == nil {
+		t.Error("New(salted, md5) error = nil, want error")
+	}
+}
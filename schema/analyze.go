@@ -0,0 +1,146 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/store"
+
+	"demo/gen-go/hbase" // Thrift 生成的 HBase 客户端代码
+)
+
+// Region 是表的一个 region 及 Analyze 统计的落在其中的数据量
+type Region struct {
+	StartKey []byte // 为空表示表的起点
+	EndKey   []byte // 为空表示表的终点
+	Server   string
+	Rows     int64
+	BizIDs   int64 // 第一行落在该 region 的 BizId 数
+}
+
+// RegionsFromLocations 将 GetAllRegionLocations 的结果转换为按 StartKey 排列的 Region，只保留主副本
+func RegionsFromLocations(locations []*hbase.THRegionLocation) []Region {
+	var regions []Region
+	for _, location := range locations {
+		info := location.GetRegionInfo()
+		if info == nil || info.GetReplicaId() != 0 {
+			continue
+		}
+		region := Region{StartKey: info.StartKey, EndKey: info.EndKey}
+		if server := location.GetServerName(); server != nil {
+			region.Server = server.HostName
+			if server.Port != nil {
+				region.Server = fmt.Sprintf("%s:%d", server.HostName, *server.Port)
+			}
+		}
+		regions = append(regions, region)
+	}
+	sort.Slice(regions, func(i, j int) bool { return bytes.Compare(regions[i].StartKey, regions[j].StartKey) < 0 })
+	return regions
+}
+
+// Analysis 是 Analyze 的结果
+type Analysis struct {
+	Regions   []Region
+	Rows      int64
+	Undecoded int64    // 无法按 codec 解码的行，如计数器所在的 BizId 前缀行或其他编码写入的行
+	Skew      float64  // 行数最多的 region 与平均每个 region 行数之比，1 表示完全均匀
+	Suggested [][]byte // 按已有数据将表均分为 suggest 段的 split key
+}
+
+// maxSamples 是 Analyze 为计算建议的 split key 保留的最多 rowkey 数
+const maxSamples = 4096
+
+// sampler 从按顺序到达的 rowkey 中等间隔采样，采样数超过上限时间隔加倍
+type sampler struct {
+	stride int64
+	n      int64
+	keys   [][]byte
+}
+
+func (s *sampler) add(key []byte) {
+	if s.n%s.stride == 0 {
+		s.keys = append(s.keys, bytes.Clone(key))
+		if len(s.keys) > maxSamples {
+			n := (len(s.keys) + 1) / 2
+			for i := 0; i < n; i++ {
+				s.keys[i] = s.keys[2*i]
+			}
+			s.keys = s.keys[:n]
+			s.stride *= 2
+		}
+	}
+	s.n++
+}
+
+// Analyze 统计 scanner 中的行在 regions 上的分布，regions 须按 StartKey 排列且首尾相接
+// scanner 返回整张表（或其一部分）的行，同一 BizId 的行须连续，行之间不必按 rowkey 排列，
+// 如 fanout.Buckets 按去掉盐值后的顺序归并的结果
+// suggest 大于 1 时按采样的分位点给出 suggest-1 个 split key；split key 取该处 BizId 的 rowkey 前缀，
+// 使同一 BizId 的行不会被分到两个 region
+func Analyze(regions []Region, scanner store.Scanner, codec rowkey.Codec, suggest int) (Analysis, error) {
+	if len(regions) == 0 {
+		regions = []Region{{}}
+	}
+	analysis := Analysis{Regions: regions}
+	samples := &sampler{stride: 1}
+	var (
+		lastBiz []byte
+		first   = true
+	)
+	for {
+		row, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return analysis, err
+		}
+		// 按 StartKey 查找行所在的 region，行不必按 rowkey 顺序到达
+		i := sort.Search(len(regions), func(i int) bool { return bytes.Compare(regions[i].StartKey, row.Key) > 0 }) - 1
+		if i < 0 {
+			i = 0
+		}
+		analysis.Rows++
+		regions[i].Rows++
+		key, err := codec.Decode(row.Key)
+		if err != nil {
+			analysis.Undecoded++
+			continue
+		}
+		if first || !bytes.Equal(key.BizId, lastBiz) {
+			first, lastBiz = false, key.BizId
+			regions[i].BizIDs++
+		}
+		samples.add(codec.Prefix(key.BizId))
+	}
+
+	var maxRows int64
+	for _, region := range regions {
+		maxRows = max(maxRows, region.Rows)
+	}
+	if analysis.Rows > 0 {
+		analysis.Skew = float64(maxRows) / (float64(analysis.Rows) / float64(len(regions)))
+	}
+	sort.Slice(samples.keys, func(i, j int) bool { return bytes.Compare(samples.keys[i], samples.keys[j]) < 0 })
+	for j := 1; j < suggest && len(samples.keys) > 0; j++ {
+		// 分位点落在表中第一个 BizId 或上一个 split key 上时顺延到下一个 BizId，
+		// 以第一个 BizId 为边界的 split key 不起作用
+		last := samples.keys[0]
+		if n := len(analysis.Suggested); n > 0 {
+			last = analysis.Suggested[n-1]
+		}
+		idx := j * len(samples.keys) / suggest
+		for idx < len(samples.keys) && bytes.Compare(samples.keys[idx], last) <= 0 {
+			idx++
+		}
+		if idx == len(samples.keys) {
+			break
+		}
+		analysis.Suggested = append(analysis.Suggested, samples.keys[idx])
+	}
+	return analysis, nil
+}
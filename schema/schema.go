@@ -0,0 +1,274 @@
+// Package schema 管理 SeqDb 表结构，通过 HBase Thrift2 管理接口创建表并将已有表迁移到期望的结构
+//
+// 期望的结构由 Table 描述，Migrate 是幂等的：表不存在时按预分区创建，已存在时对比列族属性，
+// 只修改与期望不同的属性；预分区只在创建表时生效，已有表的 region 不做调整
+package schema
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go-hbase-demo/store"
+
+	"demo/gen-go/hbase" // Thrift 生成的 HBase 客户端代码
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+// Admin 是 Migrate 使用的 Thrift 管理接口，hbase.THBaseServiceClient 实现了该接口
+type Admin interface {
+	TableExists(ctx context.Context, tableName *hbase.TTableName) (bool, error)
+	GetTableDescriptor(ctx context.Context, table *hbase.TTableName) (*hbase.TTableDescriptor, error)
+	CreateTable(ctx context.Context, desc *hbase.TTableDescriptor, splitKeys [][]byte) error
+	AddColumnFamily(ctx context.Context, tableName *hbase.TTableName, column *hbase.TColumnFamilyDescriptor) error
+	ModifyColumnFamily(ctx context.Context, tableName *hbase.TTableName, column *hbase.TColumnFamilyDescriptor) error
+	GetNamespaceDescriptor(ctx context.Context, name string) (*hbase.TNamespaceDescriptor, error)
+	CreateNamespace(ctx context.Context, namespaceDesc *hbase.TNamespaceDescriptor) error
+}
+
+// Family 是列族的期望属性，Compression、BloomFilter 为空或 MaxVersions 为 0 时不管理对应属性
+type Family struct {
+	Name        string
+	Compression string        // NONE、GZ、SNAPPY、LZ4、LZO、BZIP2 或 ZSTD
+	BloomFilter string        // NONE、ROW、ROWCOL 或 ROWPREFIX_FIXED_LENGTH
+	TTL         time.Duration // 0 表示不过期（FOREVER），精度为秒
+	MaxVersions int
+}
+
+// Table 是表的期望结构
+type Table struct {
+	Name      string // 表名，可以是 ns:table 形式
+	Families  []Family
+	SplitKeys [][]byte // 创建表时的预分区边界，为空时只有一个 region
+}
+
+// forever 是 HBase 表示不过期的 TTL
+const forever = math.MaxInt32
+
+// Validate 检查属性取值
+func (f Family) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("schema: empty family name")
+	}
+	if f.Compression != "" {
+		if _, err := hbase.TCompressionAlgorithmFromString(f.Compression); err != nil {
+			return fmt.Errorf("schema: family %s: unknown compression %q", f.Name, f.Compression)
+		}
+	}
+	if f.BloomFilter != "" {
+		if _, err := hbase.TBloomFilterTypeFromString(f.BloomFilter); err != nil {
+			return fmt.Errorf("schema: family %s: unknown bloom filter %q", f.Name, f.BloomFilter)
+		}
+	}
+	if f.TTL < 0 || f.TTL.Seconds() >= forever {
+		return fmt.Errorf("schema: family %s: ttl out of range: %v", f.Name, f.TTL)
+	}
+	if f.MaxVersions < 0 || f.MaxVersions > math.MaxInt32 {
+		return fmt.Errorf("schema: family %s: max_versions out of range: %d", f.Name, f.MaxVersions)
+	}
+	return nil
+}
+
+// ttlSeconds 返回 HBase 的列族 TTL
+func (f Family) ttlSeconds() int32 {
+	if f.TTL == 0 {
+		return forever
+	}
+	return int32(f.TTL / time.Second)
+}
+
+// descriptor 生成只包含受管理属性的列族描述
+func (f Family) descriptor() *hbase.TColumnFamilyDescriptor {
+	desc := &hbase.TColumnFamilyDescriptor{Name: []byte(f.Name), TimeToLive: thrift.Int32Ptr(f.ttlSeconds())}
+	if f.Compression != "" {
+		compression, _ := hbase.TCompressionAlgorithmFromString(f.Compression)
+		desc.CompressionType = &compression
+	}
+	if f.BloomFilter != "" {
+		bloom, _ := hbase.TBloomFilterTypeFromString(f.BloomFilter)
+		desc.BloomnFilterType = &bloom
+	}
+	if f.MaxVersions > 0 {
+		desc.MaxVersions = thrift.Int32Ptr(int32(f.MaxVersions))
+	}
+	return desc
+}
+
+// TableName 将 ns:table 形式的表名转换为 Thrift 表名
+func TableName(table string) *hbase.TTableName {
+	ns, name := store.SplitTableName(table)
+	tableName := &hbase.TTableName{Qualifier: []byte(name)}
+	if ns != "" {
+		tableName.Ns = []byte(ns)
+	}
+	return tableName
+}
+
+// Change 是已有表与期望结构的一处差异
+type Change struct {
+	Family  string
+	Field   string // family 表示列族不存在，其余为属性名
+	Current string
+	Desired string
+}
+
+func (c Change) String() string {
+	if c.Field == "family" {
+		return fmt.Sprintf("add family %s", c.Family)
+	}
+	return fmt.Sprintf("family %s: %s %s -> %s", c.Family, c.Field, c.Current, c.Desired)
+}
+
+// Diff 对比已有表的描述与期望结构，返回需要修改的属性，未管理的属性和多出的列族不参与对比
+func Diff(desired Table, current *hbase.TTableDescriptor) []Change {
+	existing := map[string]*hbase.TColumnFamilyDescriptor{}
+	for _, column := range current.GetColumns() {
+		existing[string(column.Name)] = column
+	}
+	var changes []Change
+	for _, f := range desired.Families {
+		column, ok := existing[f.Name]
+		if !ok {
+			changes = append(changes, Change{Family: f.Name, Field: "family"})
+			continue
+		}
+		add := func(field, current, desired string) {
+			if current != desired {
+				changes = append(changes, Change{Family: f.Name, Field: field, Current: current, Desired: desired})
+			}
+		}
+		if f.Compression != "" {
+			add("compression", enumString(column.CompressionType), f.Compression)
+		}
+		if f.BloomFilter != "" {
+			add("bloom_filter", enumString(column.BloomnFilterType), f.BloomFilter)
+		}
+		currentTTL := int32(forever)
+		if column.TimeToLive != nil {
+			currentTTL = *column.TimeToLive
+		}
+		add("ttl", ttlString(currentTTL), ttlString(f.ttlSeconds()))
+		if f.MaxVersions > 0 {
+			add("max_versions", fmt.Sprint(column.GetMaxVersions()), fmt.Sprint(f.MaxVersions))
+		}
+	}
+	return changes
+}
+
+// enumString 返回 Thrift 枚举的名称，未设置时为 NONE
+func enumString[T fmt.Stringer](v *T) string {
+	if v == nil {
+		return "NONE"
+	}
+	return (*v).String()
+}
+
+func ttlString(seconds int32) string {
+	if seconds == forever {
+		return "FOREVER"
+	}
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+// Result 是 Migrate 的结果
+type Result struct {
+	Created bool     // 表不存在，已创建（或 dryRun 时将创建）
+	Changes []Change // 已有表与期望结构的差异
+}
+
+// Migrate 使 desired.Name 表与期望结构一致，dryRun 为 true 时只返回差异不做修改
+// 表不存在时先创建 namespace（如需要），再按 SplitKeys 预分区创建表；已存在时添加缺少的列族并修改不同的属性
+func Migrate(ctx context.Context, admin Admin, desired Table, dryRun bool) (Result, error) {
+	for _, f := range desired.Families {
+		if err := f.Validate(); err != nil {
+			return Result{}, err
+		}
+	}
+	tableName := TableName(desired.Name)
+	exists, err := admin.TableExists(ctx, tableName)
+	if err != nil {
+		return Result{}, fmt.Errorf("check table %s: %v", desired.Name, err)
+	}
+	if !exists {
+		if dryRun {
+			return Result{Created: true}, nil
+		}
+		return Result{Created: true}, create(ctx, admin, desired, tableName)
+	}
+
+	current, err := admin.GetTableDescriptor(ctx, tableName)
+	if err != nil {
+		return Result{}, fmt.Errorf("get table descriptor %s: %v", desired.Name, err)
+	}
+	result := Result{Changes: Diff(desired, current)}
+	if dryRun {
+		return result, nil
+	}
+	changed := map[string]bool{}
+	for _, change := range result.Changes {
+		changed[change.Family] = true
+	}
+	for _, f := range desired.Families {
+		if !changed[f.Name] {
+			continue
+		}
+		// 已有列族的描述中带有未管理的属性，修改时以已有描述为基础，只覆盖受管理的属性
+		if column := findFamily(current, f.Name); column != nil {
+			err = admin.ModifyColumnFamily(ctx, tableName, merge(column, f.descriptor()))
+		} else {
+			err = admin.AddColumnFamily(ctx, tableName, f.descriptor())
+		}
+		if err != nil {
+			return result, fmt.Errorf("migrate family %s: %v", f.Name, err)
+		}
+	}
+	return result, nil
+}
+
+// create 创建表，并发创建时已存在的错误被忽略
+func create(ctx context.Context, admin Admin, desired Table, tableName *hbase.TTableName) error {
+	if ns := string(tableName.Ns); ns != "" {
+		if _, err := admin.GetNamespaceDescriptor(ctx, ns); err != nil {
+			if err := admin.CreateNamespace(ctx, &hbase.TNamespaceDescriptor{Name: ns}); err != nil && !store.IsExists(err) {
+				return fmt.Errorf("create namespace %s: %v", ns, err)
+			}
+		}
+	}
+	desc := &hbase.TTableDescriptor{TableName: tableName}
+	for _, f := range desired.Families {
+		desc.Columns = append(desc.Columns, f.descriptor())
+	}
+	if err := admin.CreateTable(ctx, desc, desired.SplitKeys); err != nil && !store.IsExists(err) {
+		return fmt.Errorf("create table %s: %v", desired.Name, err)
+	}
+	return nil
+}
+
+func findFamily(desc *hbase.TTableDescriptor, name string) *hbase.TColumnFamilyDescriptor {
+	for _, column := range desc.GetColumns() {
+		if string(column.Name) == name {
+			return column
+		}
+	}
+	return nil
+}
+
+// merge 返回 base 的副本，其中 desired 设置了的属性被覆盖
+func merge(base, desired *hbase.TColumnFamilyDescriptor) *hbase.TColumnFamilyDescriptor {
+	merged := *base
+	if desired.CompressionType != nil {
+		merged.CompressionType = desired.CompressionType
+	}
+	if desired.BloomnFilterType != nil {
+		merged.BloomnFilterType = desired.BloomnFilterType
+	}
+	if desired.TimeToLive != nil {
+		merged.TimeToLive = desired.TimeToLive
+	}
+	if desired.MaxVersions != nil {
+		merged.MaxVersions = desired.MaxVersions
+	}
+	return &merged
+}
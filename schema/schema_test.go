@@ -0,0 +1,349 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/fanout"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/store"
+
+	"demo/gen-go/hbase"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+// fakeAdmin 在内存中保存表和 namespace 的描述
+type fakeAdmin struct {
+	namespaces map[string]bool
+	tables     map[string]*hbase.TTableDescriptor
+	splits     map[string][][]byte
+	calls      []string // 修改表结构的调用
+}
+
+func newFakeAdmin() *fakeAdmin {
+	return &fakeAdmin{namespaces: map[string]bool{"default": true}, tables: map[string]*hbase.TTableDescriptor{}, splits: map[string][][]byte{}}
+}
+
+func key(name *hbase.TTableName) string {
+	return string(name.Ns) + ":" + string(name.Qualifier)
+}
+
+func (a *fakeAdmin) TableExists(ctx context.Context, name *hbase.TTableName) (bool, error) {
+	_, ok := a.tables[key(name)]
+	return ok, nil
+}
+
+func (a *fakeAdmin) GetTableDescriptor(ctx context.Context, name *hbase.TTableName) (*hbase.TTableDescriptor, error) {
+	desc, ok := a.tables[key(name)]
+	if !ok {
+		return nil, errors.New("TableNotFoundException")
+	}
+	return desc, nil
+}
+
+func (a *fakeAdmin) CreateTable(ctx context.Context, desc *hbase.TTableDescriptor, splitKeys [][]byte) error {
+	if len(desc.TableName.Ns) > 0 && !a.namespaces[string(desc.TableName.Ns)] {
+		return errors.New("NamespaceNotFoundException")
+	}
+	a.calls = append(a.calls, "create "+key(desc.TableName))
+	a.tables[key(desc.TableName)] = desc
+	a.splits[key(desc.TableName)] = splitKeys
+	return nil
+}
+
+func (a *fakeAdmin) AddColumnFamily(ctx context.Context, name *hbase.TTableName, column *hbase.TColumnFamilyDescriptor) error {
+	a.calls = append(a.calls, "add "+string(column.Name))
+	desc := a.tables[key(name)]
+	desc.Columns = append(desc.Columns, column)
+	return nil
+}
+
+func (a *fakeAdmin) ModifyColumnFamily(ctx context.Context, name *hbase.TTableName, column *hbase.TColumnFamilyDescriptor) error {
+	a.calls = append(a.calls, "modify "+string(column.Name))
+	desc := a.tables[key(name)]
+	for i, c := range desc.Columns {
+		if string(c.Name) == string(column.Name) {
+			desc.Columns[i] = column
+		}
+	}
+	return nil
+}
+
+func (a *fakeAdmin) GetNamespaceDescriptor(ctx context.Context, name string) (*hbase.TNamespaceDescriptor, error) {
+	if !a.namespaces[name] {
+		return nil, errors.New("NamespaceNotFoundException")
+	}
+	return &hbase.TNamespaceDescriptor{Name: name}, nil
+}
+
+func (a *fakeAdmin) CreateNamespace(ctx context.Context, desc *hbase.TNamespaceDescriptor) error {
+	a.calls = append(a.calls, "create namespace "+desc.Name)
+	a.namespaces[desc.Name] = true
+	return nil
+}
+
+func testTable() Table {
+	return Table{
+		Name:      "ns1:seqdb",
+		Families:  []Family{{Name: "cf", Compression: "SNAPPY", BloomFilter: "ROW", TTL: 24 * time.Hour, MaxVersions: 3}},
+		SplitKeys: [][]byte{{1}, {2}, {3}},
+	}
+}
+
+func TestMigrate_CreateIdempotent(t *testing.T) {
+	admin := newFakeAdmin()
+	ctx := context.Background()
+	desired := testTable()
+
+	result, err := Migrate(ctx, admin, desired, true)
+	if err != nil || !result.Created || len(admin.calls) != 0 {
+		t.Fatalf("Migrate(dry run) = %+v, %v, calls %v, want created without calls", result, err, admin.calls)
+	}
+	result, err = Migrate(ctx, admin, desired, false)
+	if err != nil || !result.Created {
+		t.Fatalf("Migrate() = %+v, %v, want created", result, err)
+	}
+	if want := []string{"create namespace ns1", "create ns1:seqdb"}; !reflect.DeepEqual(admin.calls, want) {
+		t.Errorf("calls = %v, want %v", admin.calls, want)
+	}
+	if got := admin.splits["ns1:seqdb"]; !reflect.DeepEqual(got, desired.SplitKeys) {
+		t.Errorf("split keys = %v, want %v", got, desired.SplitKeys)
+	}
+
+	// 再次迁移时没有差异，不修改表
+	admin.calls = nil
+	result, err = Migrate(ctx, admin, desired, false)
+	if err != nil || result.Created || len(result.Changes) != 0 || len(admin.calls) != 0 {
+		t.Errorf("Migrate() again = %+v, %v, calls %v, want up to date", result, err, admin.calls)
+	}
+}
+
+func TestMigrate_Modify(t *testing.T) {
+	admin := newFakeAdmin()
+	ctx := context.Background()
+	none := hbase.TCompressionAlgorithm_NONE
+	admin.tables[":my_table"] = &hbase.TTableDescriptor{
+		TableName: &hbase.TTableName{Qualifier: []byte("my_table")},
+		Columns: []*hbase.TColumnFamilyDescriptor{{
+			Name:            []byte("cf"),
+			CompressionType: &none,
+			BlockSize:       thrift.Int32Ptr(65536), // 未管理的属性在修改后保留
+		}},
+	}
+	desired := testTable()
+	desired.Name = "my_table"
+	desired.Families = append(desired.Families, Family{Name: "meta"})
+
+	result, err := Migrate(ctx, admin, desired, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{
+		{Family: "cf", Field: "compression", Current: "NONE", Desired: "SNAPPY"},
+		{Family: "cf", Field: "bloom_filter", Current: "NONE", Desired: "ROW"},
+		{Family: "cf", Field: "ttl", Current: "FOREVER", Desired: "24h0m0s"},
+		{Family: "cf", Field: "max_versions", Current: "0", Desired: "3"},
+		{Family: "meta", Field: "family"},
+	}
+	if !reflect.DeepEqual(result.Changes, want) {
+		t.Errorf("Migrate(dry run).Changes = %v, want %v", result.Changes, want)
+	}
+	if len(admin.calls) != 0 {
+		t.Errorf("dry run calls = %v, want none", admin.calls)
+	}
+
+	if _, err := Migrate(ctx, admin, desired, false); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"modify cf", "add meta"}; !reflect.DeepEqual(admin.calls, want) {
+		t.Errorf("calls = %v, want %v", admin.calls, want)
+	}
+	if cf := admin.tables[":my_table"].Columns[0]; cf.GetBlockSize() != 65536 {
+		t.Errorf("block size = %d after modify, want unchanged", cf.GetBlockSize())
+	}
+	if changes := Diff(desired, admin.tables[":my_table"]); len(changes) != 0 {
+		t.Errorf("Diff() after migrate = %v, want none", changes)
+	}
+}
+
+func TestFamily_Validate(t *testing.T) {
+	invalid := []Family{
+		{},
+		{Name: "cf", Compression: "snappy"},
+		{Name: "cf", BloomFilter: "ROWS"},
+		{Name: "cf", TTL: -time.Second},
+		{Name: "cf", MaxVersions: -1},
+	}
+	for _, f := range invalid {
+		if err := f.Validate(); err == nil {
+			t.Errorf("Validate(%+v) error = nil", f)
+		}
+	}
+	if _, err := Migrate(context.Background(), newFakeAdmin(), Table{Name: "t", Families: invalid[1:2]}, false); err == nil {
+		t.Error("Migrate() with invalid family error = nil")
+	}
+}
+
+func TestSplitKeys(t *testing.T) {
+	salted, err := rowkey.NewSalted(4, rowkey.Murmur3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		codec   rowkey.Codec
+		regions int
+		want    [][]byte
+	}{
+		{"salted", salted, 16, [][]byte{{1}, {2}, {3}}},
+		{"legacy", rowkey.Legacy{}, 4, [][]byte{[]byte("F"), []byte("V"), []byte("k")}},
+		{"legacy single region", rowkey.Legacy{}, 1, nil},
+		{"legacy capped", rowkey.Legacy{}, 1000, nil}, // 见下方对长度的检查
+		{"binary", rowkey.Binary{}, 16, nil},
+	}
+	for _, tt := range tests {
+		got := SplitKeys(tt.codec, tt.regions)
+		if tt.name == "legacy capped" {
+			if len(got) != len(legacyAlphabet)-1 {
+				t.Errorf("%s: %d split keys, want %d", tt.name, len(got), len(legacyAlphabet)-1)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SplitKeys() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRegionsFromLocations(t *testing.T) {
+	location := func(start, end string, replica int32) *hbase.THRegionLocation {
+		return &hbase.THRegionLocation{
+			ServerName: &hbase.TServerName{HostName: "rs1", Port: thrift.Int32Ptr(16020)},
+			RegionInfo: &hbase.THRegionInfo{StartKey: []byte(start), EndKey: []byte(end), ReplicaId: &replica},
+		}
+	}
+	regions := RegionsFromLocations([]*hbase.THRegionLocation{location("m", "", 0), location("", "m", 0), location("", "m", 1)})
+	want := []Region{
+		{StartKey: []byte(""), EndKey: []byte("m"), Server: "rs1:16020"},
+		{StartKey: []byte("m"), EndKey: []byte(""), Server: "rs1:16020"},
+	}
+	if !reflect.DeepEqual(regions, want) {
+		t.Errorf("RegionsFromLocations() = %+v, want %+v", regions, want)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	codec := rowkey.Binary{}
+	s := store.NewMemoryStore(store.Options{RowKey: codec.Encode})
+	// biz_a 90 行、biz_b 5 行、biz_c 5 行，分布严重倾斜
+	counts := map[string]int{"biz_a": 90, "biz_b": 5, "biz_c": 5}
+	var items []*pb.SeqItem
+	for biz, n := range counts {
+		for seq := 0; seq < n; seq++ {
+			items = append(items, &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte(biz), Seq: int32(seq)}})
+		}
+	}
+	if _, err := s.Put(context.Background(), items, 0); err != nil {
+		t.Fatal(err)
+	}
+	scanner, err := s.Scan(context.Background(), store.Range{KeysOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	boundary := codec.Prefix([]byte("biz_b"))
+	regions := []Region{{EndKey: boundary}, {StartKey: boundary}}
+	analysis, err := Analyze(regions, scanner, codec, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if analysis.Rows != 100 || analysis.Regions[0].Rows != 90 || analysis.Regions[1].Rows != 10 {
+		t.Errorf("Analyze() rows = %d, regions %+v, want 90 and 10", analysis.Rows, analysis.Regions)
+	}
+	if analysis.Regions[0].BizIDs != 1 || analysis.Regions[1].BizIDs != 2 {
+		t.Errorf("Analyze() biz_ids = %d, %d, want 1, 2", analysis.Regions[0].BizIDs, analysis.Regions[1].BizIDs)
+	}
+	if analysis.Skew != 1.8 {
+		t.Errorf("Analyze() skew = %v, want 1.8", analysis.Skew)
+	}
+	// 中位数落在 biz_a 上，顺延到下一个 BizId
+	if want := [][]byte{boundary}; !reflect.DeepEqual(analysis.Suggested, want) {
+		t.Errorf("Analyze() suggested = %q, want %q", analysis.Suggested, want)
+	}
+}
+
+func TestAnalyze_Buckets(t *testing.T) {
+	codec, err := rowkey.NewSalted(4, rowkey.XXHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := store.NewMemoryStore(store.Options{RowKey: codec.Encode})
+	var items []*pb.SeqItem
+	bucketRows := make([]int64, codec.Buckets())
+	for i := 0; i < 40; i++ {
+		biz := []byte(fmt.Sprintf("biz%d", i))
+		for seq := 0; seq < 3; seq++ {
+			items = append(items, &pb.SeqItem{Key: &pb.SeqKey{BizId: biz, Seq: int32(seq)}})
+		}
+		bucketRows[codec.Bucket(biz)] += 3
+	}
+	if _, err := s.Put(context.Background(), items, 0); err != nil {
+		t.Fatal(err)
+	}
+	// 每个盐值桶一个 region，各桶的行交替到达
+	var regions []Region
+	for b := 0; b < codec.Buckets(); b++ {
+		region := Region{StartKey: []byte{byte(b)}, EndKey: []byte{byte(b + 1)}}
+		if b == 0 {
+			region.StartKey = nil
+		}
+		if b == codec.Buckets()-1 {
+			region.EndKey = nil
+		}
+		regions = append(regions, region)
+	}
+	sources, less := fanout.Buckets(codec, store.Range{KeysOnly: true})
+	scanner := fanout.Scan(context.Background(), s, sources, less, fanout.Options{})
+	defer scanner.Close()
+	analysis, err := Analyze(regions, scanner, codec, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bizIDs int64
+	for b, region := range analysis.Regions {
+		if region.Rows != bucketRows[b] {
+			t.Errorf("region %d rows = %d, want %d", b, region.Rows, bucketRows[b])
+		}
+		bizIDs += region.BizIDs
+	}
+	if bizIDs != 40 {
+		t.Errorf("Analyze() biz_ids = %d, want 40", bizIDs)
+	}
+	if len(analysis.Suggested) != 3 || !sort.SliceIsSorted(analysis.Suggested, func(i, j int) bool {
+		return bytes.Compare(analysis.Suggested[i], analysis.Suggested[j]) < 0
+	}) {
+		t.Errorf("Analyze() suggested = %q, want 3 sorted split keys", analysis.Suggested)
+	}
+}
+
+func TestSampler(t *testing.T) {
+	s := &sampler{stride: 1}
+	for i := 0; i < 3*maxSamples; i++ {
+		s.add([]byte{byte(i >> 8), byte(i)})
+	}
+	if len(s.keys) > maxSamples || s.stride != 4 {
+		t.Fatalf("sampler kept %d keys with stride %d", len(s.keys), s.stride)
+	}
+	for i, key := range s.keys {
+		if n := int(key[0])<<8 | int(key[1]); n != i*4 {
+			t.Fatalf("sample %d = %d, want %d", i, n, i*4)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package schema
+
+import "go-hbase-demo/rowkey"
+
+// legacyAlphabet 是 Legacy 编码常见的盐值字符（BizId 的最后一个字节），按字节序排列
+const legacyAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// SplitKeys 返回与 rowkey 编码对应的预分区边界，regions 是期望的 region 数
+//   - salted：rowkey 以 1 字节桶号开头，每个桶一个 region，忽略 regions
+//   - legacy：rowkey 以 BizId 的最后一个字节开头，将 [0-9A-Za-z] 均分为 regions 段（最多 62 段）
+//   - binary：rowkey 以 BizId 的长度开头，分布取决于 BizId，不预分区；可用 Analyze 根据已有数据给出 split key
+func SplitKeys(codec rowkey.Codec, regions int) [][]byte {
+	switch c := codec.(type) {
+	case *rowkey.Salted:
+		keys := make([][]byte, 0, c.Buckets()-1)
+		for bucket := 1; bucket < c.Buckets(); bucket++ {
+			keys = append(keys, []byte{byte(bucket)})
+		}
+		return keys
+	case rowkey.Legacy:
+		regions = min(regions, len(legacyAlphabet))
+		var keys [][]byte
+		for i := 1; i < regions; i++ {
+			keys = append(keys, []byte{legacyAlphabet[i*len(legacyAlphabet)/regions]})
+		}
+		return keys
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+// seqdb-admin 是 SeqDb 的表管理工具，通过 HBase Thrift2 管理接口操作表结构
+//
+// 用法：seqdb-admin <command> [-config seqdb.yaml] [flags]
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"go-hbase-demo/config"
+	"go-hbase-demo/fanout"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/schema"
+	"go-hbase-demo/store"
+	"go-hbase-demo/tenant"
+)
+
+// command 是一个子命令，args 不含子命令名
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"migrate": {"创建表，或将已有表的列族属性迁移到配置中的结构", migrate},
+	"analyze": {"统计已有数据在 region 上的分布，并给出均分数据的 split key", analyze},
+	"bizids":  {"按逻辑顺序列出表中的 BizId 及其行数", bizIDs},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: seqdb-admin <command> [flags]")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		log.Fatalf("%s: %v", os.Args[1], err)
+	}
+}
+
+// tableName 返回要管理的表名：namespace 为空时为 table.name，否则为租户表 namespace:tenant.table
+func tableName(cfg *config.Config, namespace string) (string, error) {
+	if namespace == "" {
+		return cfg.Table.Name, nil
+	}
+	if err := tenant.Validate(namespace); err != nil {
+		return "", err
+	}
+	return tenant.Table(namespace, cfg.Tenant.Table), nil
+}
+
+// desiredTable 根据配置生成期望的表结构
+func desiredTable(cfg *config.Config, table string) (schema.Table, error) {
+	desired := schema.Table{
+		Name: table,
+		Families: []schema.Family{{
+			Name:        cfg.Table.Family,
+			Compression: cfg.Schema.Compression,
+			BloomFilter: cfg.Schema.BloomFilter,
+			TTL:         cfg.Schema.TTL,
+			MaxVersions: cfg.Schema.MaxVersions,
+		}},
+	}
+	if cfg.Schema.PreSplit {
+		codec, err := rowkey.New(cfg.Table.RowKey, cfg.Table.SaltBuckets, cfg.Table.SaltHash)
+		if err != nil {
+			return schema.Table{}, err
+		}
+		desired.SplitKeys = schema.SplitKeys(codec, cfg.Schema.Regions)
+	}
+	return desired, nil
+}
+
+func migrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := fs.Bool("dry_run", false, "只输出与配置的差异，不修改表")
+	namespace := fs.String("namespace", "", "管理租户 namespace 的表（namespace:tenant.table），为空时管理 table.name")
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return err
+	}
+	table, err := tableName(cfg, *namespace)
+	if err != nil {
+		return err
+	}
+	desired, err := desiredTable(cfg, table)
+	if err != nil {
+		return err
+	}
+
+	client, trans, err := store.DialThrift(cfg.Thrift.Host, cfg.Thrift.User, cfg.Thrift.Password)
+	if err != nil {
+		return err
+	}
+	defer trans.Close()
+	result, err := schema.Migrate(context.Background(), client, desired, *dryRun)
+	if err != nil {
+		return err
+	}
+	report(os.Stdout, desired, result, *dryRun)
+	return nil
+}
+
+// report 输出 Migrate 的结果
+func report(w io.Writer, desired schema.Table, result schema.Result, dryRun bool) {
+	action := "applied"
+	if dryRun {
+		action = "pending"
+	}
+	switch {
+	case result.Created:
+		fmt.Fprintf(w, "table %s: create with %d regions (%s)\n", desired.Name, len(desired.SplitKeys)+1, action)
+	case len(result.Changes) == 0:
+		fmt.Fprintf(w, "table %s: up to date\n", desired.Name)
+	default:
+		fmt.Fprintf(w, "table %s: %d changes (%s)\n", desired.Name, len(result.Changes), action)
+		for _, change := range result.Changes {
+			fmt.Fprintf(w, "  %s\n", change)
+		}
+	}
+}
+
+func analyze(args []string) error {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	namespace := fs.String("namespace", "", "分析租户 namespace 的表（namespace:tenant.table），为空时分析 table.name")
+	maxRows := fs.Int("max_rows", 0, "最多扫描的行数，0 表示扫描整张表")
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return err
+	}
+	table, err := tableName(cfg, *namespace)
+	if err != nil {
+		return err
+	}
+	codec, err := rowkey.New(cfg.Table.RowKey, cfg.Table.SaltBuckets, cfg.Table.SaltHash)
+	if err != nil {
+		return err
+	}
+
+	client, trans, err := store.DialThrift(cfg.Thrift.Host, cfg.Thrift.User, cfg.Thrift.Password)
+	if err != nil {
+		return err
+	}
+	defer trans.Close()
+	ctx := context.Background()
+	locations, err := client.GetAllRegionLocations(ctx, []byte(table))
+	if err != nil {
+		return fmt.Errorf("get region locations of %s: %v", table, err)
+	}
+	// 每个盐值桶并行扫描，max_rows 限制时各桶都有行被统计，而不是只统计排在前面的桶
+	opts := store.Options{Table: table, Family: cfg.Table.Family, Qualifier: cfg.Table.Qualifier, RowKey: codec.Encode}
+	sources, less := fanout.Buckets(codec, store.Range{KeysOnly: true})
+	scanner := fanout.Scan(ctx, store.NewThriftStore(client, opts), sources, less, fanout.Options{Limit: *maxRows})
+	defer scanner.Close()
+	analysis, err := schema.Analyze(schema.RegionsFromLocations(locations), scanner, codec, cfg.Schema.Regions)
+	if err != nil {
+		return err
+	}
+	reportAnalysis(os.Stdout, table, analysis, schema.SplitKeys(codec, cfg.Schema.Regions))
+	return nil
+}
+
+func bizIDs(args []string) error {
+	fs := flag.NewFlagSet("bizids", flag.ExitOnError)
+	namespace := fs.String("namespace", "", "列出租户 namespace 的表（namespace:tenant.table），为空时列出 table.name")
+	prefix := fs.String("prefix", "", "只列出以 prefix 开头的 BizId")
+	maxRows := fs.Int("max_rows", 0, "最多扫描的行数，0 表示扫描整张表")
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return err
+	}
+	table, err := tableName(cfg, *namespace)
+	if err != nil {
+		return err
+	}
+	codec, err := rowkey.New(cfg.Table.RowKey, cfg.Table.SaltBuckets, cfg.Table.SaltHash)
+	if err != nil {
+		return err
+	}
+
+	client, trans, err := store.DialThrift(cfg.Thrift.Host, cfg.Thrift.User, cfg.Thrift.Password)
+	if err != nil {
+		return err
+	}
+	defer trans.Close()
+	opts := store.Options{Table: table, Family: cfg.Table.Family, Qualifier: cfg.Table.Qualifier, RowKey: codec.Encode}
+	sources, less := fanout.Buckets(codec, store.Range{KeysOnly: true})
+	scanner := fanout.Scan(context.Background(), store.NewThriftStore(client, opts), sources, less, fanout.Options{Limit: *maxRows})
+	defer scanner.Close()
+	return listBizIDs(os.Stdout, scanner, codec, []byte(*prefix))
+}
+
+// listBizIDs 输出 scanner 中以 prefix 开头的 BizId 及其行数，scanner 中同一 BizId 的行须连续
+func listBizIDs(w io.Writer, scanner store.Scanner, codec rowkey.Codec, prefix []byte) error {
+	var (
+		bizID []byte
+		rows  int64
+	)
+	flush := func() {
+		if rows > 0 {
+			fmt.Fprintf(w, "%q\t%d\n", bizID, rows)
+		}
+	}
+	for {
+		row, err := scanner.Next()
+		if err == io.EOF {
+			flush()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		key, err := codec.Decode(row.Key)
+		if err != nil || !bytes.HasPrefix(key.BizId, prefix) {
+			continue // 计数器、预写日志所在的 BizId 前缀行等
+		}
+		if rows > 0 && !bytes.Equal(key.BizId, bizID) {
+			flush()
+			rows = 0
+		}
+		bizID = key.BizId
+		rows++
+	}
+}
+
+// reportAnalysis 输出 Analyze 的结果，strategy 为按 rowkey 编码预分区时的 split key
+func reportAnalysis(w io.Writer, table string, analysis schema.Analysis, strategy [][]byte) {
+	fmt.Fprintf(w, "table %s: %d rows in %d regions, skew %.2f", table, analysis.Rows, len(analysis.Regions), analysis.Skew)
+	if analysis.Undecoded > 0 {
+		fmt.Fprintf(w, ", %d rows not decodable by the current row key codec", analysis.Undecoded)
+	}
+	fmt.Fprintln(w)
+	for i, region := range analysis.Regions {
+		share := 0.0
+		if analysis.Rows > 0 {
+			share = float64(region.Rows) * 100 / float64(analysis.Rows)
+		}
+		fmt.Fprintf(w, "  #%-3d [%q, %q) %s: %d rows (%.1f%%), %d biz_ids\n", i, region.StartKey, region.EndKey, region.Server, region.Rows, share, region.BizIDs)
+	}
+	printKeys := func(title string, keys [][]byte) {
+		fmt.Fprintf(w, "%s (%d regions):\n", title, len(keys)+1)
+		for _, key := range keys {
+			fmt.Fprintf(w, "  %q\n", key)
+		}
+	}
+	printKeys("split keys balancing the scanned rows", analysis.Suggested)
+	printKeys("split keys of the row key strategy", strategy)
+}
@@ -0,0 +1,220 @@
+package seqrange
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"sort"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/store"
+)
+
+// IsMulti 判断 req 是否为跨 BizId 的查询（设置了 biz_ids 或 biz_prefix）
+func IsMulti(req *pb.RangeReq) bool {
+	return len(req.GetBizIds()) > 0 || len(req.GetBizPrefix()) > 0
+}
+
+// MultiBounds 计算跨 BizId 查询中 bizID 的扫描范围，区间为空时 ok 为 false
+// req.Start 和 req.End 只提供 seq（为空时分别为 0 和 math.MaxInt32），Option 的含义不变；
+// 结果按 seq 升序排列，req.Reverse 为 true 时按降序排列，与其他 BizId 的结果拼接后即为 (BizId, seq) 顺序
+func MultiBounds(codec rowkey.Codec, req *pb.RangeReq, bizID []byte) (store.Range, bool, error) {
+	start, end := int32(0), int32(math.MaxInt32)
+	if req.Start != nil {
+		start = req.Start.Seq
+	}
+	if req.End != nil {
+		end = req.End.Seq
+	}
+	option := req.Option
+	if start > end {
+		// 交换端点使结果从小的 seq 开始，端点是否包含随端点交换
+		start, end = end, start
+		switch option {
+		case pb.RangeOption_WithoutStart:
+			option = pb.RangeOption_WithoutEnd
+		case pb.RangeOption_WithoutEnd:
+			option = pb.RangeOption_WithoutStart
+		}
+	}
+	return Bounds(codec, &pb.RangeReq{
+		Start:   &pb.SeqKey{BizId: bizID, Seq: start},
+		End:     &pb.SeqKey{BizId: bizID, Seq: end},
+		Option:  option,
+		Reverse: req.Reverse,
+	})
+}
+
+// MultiBizIDs 返回跨 BizId 查询涉及的 BizId，按结果顺序排列（BizId 升序，req.Reverse 为 true 时降序）
+// 设置了 biz_prefix 时通过 BizIDs 在表中查找
+func MultiBizIDs(ctx context.Context, st store.SeqStore, codec rowkey.Codec, req *pb.RangeReq) ([][]byte, error) {
+	var bizIDs [][]byte
+	if len(req.BizIds) > 0 {
+		bizIDs = sortUnique(append([][]byte(nil), req.BizIds...))
+	} else {
+		var err error
+		if bizIDs, err = BizIDs(ctx, st, codec, req.BizPrefix); err != nil {
+			return nil, err
+		}
+	}
+	if req.Reverse {
+		for i, j := 0, len(bizIDs)-1; i < j; i, j = i+1, j-1 {
+			bizIDs[i], bizIDs[j] = bizIDs[j], bizIDs[i]
+		}
+	}
+	return bizIDs, nil
+}
+
+func sortUnique(bizIDs [][]byte) [][]byte {
+	sort.Slice(bizIDs, func(i, j int) bool { return bytes.Compare(bizIDs[i], bizIDs[j]) < 0 })
+	unique := bizIDs[:0]
+	for i, bizID := range bizIDs {
+		if i == 0 || !bytes.Equal(bizID, bizIDs[i-1]) {
+			unique = append(unique, bizID)
+		}
+	}
+	return unique
+}
+
+// BizIDs 返回表中所有以 prefix 开头的 BizId，按升序排列
+//
+// 各编码都不会把以 prefix 开头的 BizId 放在一段连续的 rowkey 中（Binary 以长度开头，Salted 和 Legacy 以盐值开头），
+// 这里用跳跃扫描查找：每次只读一行，解码出 BizId 后直接跳到下一个可能匹配的位置，
+// 匹配的 BizId 跳过其所有行，不匹配时跳过整个盐值桶或长度分组；
+// 扫描次数与匹配的 BizId 数以及桶、长度分组的数量成正比，与行数无关
+// Legacy 格式中 BizId 含有 '_' 时不同 BizId 的行会交错（见 rowkey.Legacy），无法按 BizId 跳跃，改为由 scanBizIDs 扫描整张表
+func BizIDs(ctx context.Context, st store.SeqStore, codec rowkey.Codec, prefix []byte) ([][]byte, error) {
+	if _, ok := codec.(rowkey.Legacy); ok {
+		return scanBizIDs(ctx, st, codec, prefix)
+	}
+	seek := prefixSeeker(codec, prefix)
+	var (
+		bizIDs [][]byte
+		pos    []byte // 下一次扫描的起点，nil 表示从表头开始
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		row, err := FirstRow(ctx, st, store.Range{StartRow: pos, Limit: 1, KeysOnly: true})
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			return sortUnique(bizIDs), nil
+		}
+		key, err := codec.Decode(row.Key)
+		if err != nil {
+			// 计数器、预写日志所在的 BizId 前缀行等，继续查看下一行
+			pos = append(bytes.Clone(row.Key), 0)
+			continue
+		}
+		if bytes.HasPrefix(key.BizId, prefix) {
+			bizIDs = append(bizIDs, key.BizId)
+		}
+		next, done := seek(row.Key, key.BizId)
+		if done {
+			return sortUnique(bizIDs), nil
+		}
+		if next == nil || bytes.Compare(next, row.Key) <= 0 {
+			// 匹配的 BizId，或跳跃位置不在当前行之后，跳过当前 BizId 的所有行
+			next = afterBiz(codec, key.BizId)
+		}
+		pos = next
+	}
+}
+
+// scanBizIDs 逐行扫描整张表，返回以 prefix 开头的 BizId，按升序排列
+// 每一行都要读取，只用于无法跳跃扫描的 Legacy 格式
+func scanBizIDs(ctx context.Context, st store.SeqStore, codec rowkey.Codec, prefix []byte) ([][]byte, error) {
+	scanner, err := st.Scan(ctx, store.Range{KeysOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer scanner.Close()
+	var bizIDs [][]byte
+	for {
+		row, err := scanner.Next()
+		if err == io.EOF {
+			return sortUnique(bizIDs), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		key, err := codec.Decode(row.Key)
+		if err != nil {
+			// 计数器、预写日志所在的 BizId 前缀行等
+			continue
+		}
+		if bytes.HasPrefix(key.BizId, prefix) && (len(bizIDs) == 0 || !bytes.Equal(key.BizId, bizIDs[len(bizIDs)-1])) {
+			bizIDs = append(bizIDs, key.BizId)
+		}
+	}
+}
+
+// FirstRow 返回 st 中 rng 的第一行，没有数据时返回 nil
+func FirstRow(ctx context.Context, st store.SeqStore, rng store.Range) (*store.Row, error) {
+	scanner, err := st.Scan(ctx, rng)
+	if err != nil {
+		return nil, err
+	}
+	defer scanner.Close()
+	row, err := scanner.Next()
+	if err == io.EOF {
+		return nil, nil
+	}
+	return row, err
+}
+
+// afterBiz 返回紧随 bizID 最后一行之后的 rowkey
+func afterBiz(codec rowkey.Codec, bizID []byte) []byte {
+	return append(codec.Encode(&pb.SeqKey{BizId: bizID, Seq: codec.SeqAt(math.MaxUint32)}), 0)
+}
+
+// prefixEnd 返回大于所有以 b 开头的 rowkey 的最小 rowkey，b 全为 0xff 时返回 nil
+func prefixEnd(b []byte) []byte {
+	end := bytes.Clone(b)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// prefixSeeker 返回按编码计算跳跃位置的函数：rowKey 是刚读到的行，bizID 是其 BizId，
+// 返回下一次扫描的起点；done 为 true 表示之后不会再有匹配的 BizId，next 为 nil 表示跳过 bizID 的所有行
+func prefixSeeker(codec rowkey.Codec, prefix []byte) func(rowKey, bizID []byte) (next []byte, done bool) {
+	switch codec.(type) {
+	case rowkey.Binary:
+		return func(rowKey, bizID []byte) ([]byte, bool) {
+			return binarySeek(nil, bizID, prefix), false
+		}
+	case *rowkey.Salted:
+		return func(rowKey, bizID []byte) ([]byte, bool) {
+			// 盐值桶内是 Binary 格式
+			return binarySeek(rowKey[:1], bizID, prefix), false
+		}
+	}
+	return func(rowKey, bizID []byte) ([]byte, bool) { return nil, false }
+}
+
+// binarySeek 是 Binary 格式的跳跃规则，base 是 rowkey 在 Binary 格式之前的部分（Salted 的桶号）
+// Binary 的 rowkey 以 uvarint(len(BizId)) 开头，相同长度的 BizId 构成连续的分组，分组内按 BizId 排列；
+// 注意 uvarint 不保持数值顺序（长度 256 排在 129 之前），因此只跳到当前分组之后，而不是下一个长度的分组
+func binarySeek(base, bizID, prefix []byte) []byte {
+	group := binary.AppendUvarint(bytes.Clone(base), uint64(len(bizID)))
+	switch {
+	case bytes.HasPrefix(bizID, prefix):
+		return nil
+	case len(bizID) >= len(prefix) && bytes.Compare(bizID[:len(prefix)], prefix) < 0:
+		// 跳到本分组中第一个可能匹配的 BizId
+		return append(group, prefix...)
+	}
+	// 本分组中不会再有匹配的 BizId；group 的最后一个字节小于 0x80，prefixEnd 不会进位
+	return prefixEnd(group)
+}
@@ -0,0 +1,89 @@
+// Package seqrange 将 RangeReq 描述的 Seq 区间映射为精确的 rowkey 扫描范围
+//
+// 区间由 Start 和 End 两个端点确定，Option 决定是否包含各端点：
+// WithBoth 为 [Start, End]，WithoutStart 为 (Start, End]，WithoutEnd 为 [Start, End)，WithoutBoth 为 (Start, End)
+// 结果从 Start 向 End 排列，Reverse 为 true 时从 End 向 Start 排列
+// Start 与 End 的先后不限：Seq 在 rowkey 中的排列顺序由编码决定（见 rowkey.Codec.Ordinal），
+// 这里只按序号计算边界，端点的 ±1 调整在序号上进行，并处理 uint32 序号两端的溢出
+package seqrange
+
+import (
+	"bytes"
+	"errors"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/store"
+)
+
+// ErrBizMismatch 表示 Start 和 End 不属于同一个 BizId
+var ErrBizMismatch = errors.New("seqrange: start and end must have the same biz_id")
+
+// ErrMissingKey 表示缺少 Start 或 End
+var ErrMissingKey = errors.New("seqrange: start and end are required")
+
+// Bounds 计算 req 对应的扫描范围，区间为空时 ok 为 false
+func Bounds(codec rowkey.Codec, req *pb.RangeReq) (rng store.Range, ok bool, err error) {
+	if req.GetStart() == nil || req.GetEnd() == nil {
+		return store.Range{}, false, ErrMissingKey
+	}
+	if !bytes.Equal(req.Start.BizId, req.End.BizId) {
+		return store.Range{}, false, ErrBizMismatch
+	}
+
+	startOrd, endOrd := codec.Ordinal(req.Start.Seq), codec.Ordinal(req.End.Seq)
+	withStart := req.Option == pb.RangeOption_WithBoth || req.Option == pb.RangeOption_WithoutEnd
+	withEnd := req.Option == pb.RangeOption_WithBoth || req.Option == pb.RangeOption_WithoutStart
+
+	// 统一成序号上的闭区间 [lo, hi]，ascending 表示结果按 rowkey 升序排列
+	lo, hi, withLo, withHi := startOrd, endOrd, withStart, withEnd
+	ascending := startOrd <= endOrd
+	if !ascending {
+		lo, hi, withLo, withHi = endOrd, startOrd, withEnd, withStart
+	}
+	if req.Reverse {
+		ascending = !ascending
+	}
+	if !withLo {
+		if lo == ^uint32(0) {
+			return store.Range{}, false, nil
+		}
+		lo++
+	}
+	if !withHi {
+		if hi == 0 {
+			return store.Range{}, false, nil
+		}
+		hi--
+	}
+	if lo > hi {
+		return store.Range{}, false, nil
+	}
+	return Ordinals(codec, req.Start.BizId, lo, hi, !ascending), true, nil
+}
+
+// Ordinals 返回 BizId 内序号 [lo, hi] 的扫描范围，reverse 为 true 时按 rowkey 降序扫描
+func Ordinals(codec rowkey.Codec, bizID []byte, lo, hi uint32, reverse bool) store.Range {
+	encode := func(ordinal uint32) []byte {
+		return codec.Encode(&pb.SeqKey{BizId: bizID, Seq: codec.SeqAt(ordinal)})
+	}
+	if !reverse {
+		// 正序扫描 [StartRow, StopRow)，hi 为最大序号时用紧随其后的 rowkey 作为上界
+		stop := append(encode(hi), 0)
+		if hi < ^uint32(0) {
+			stop = encode(hi + 1)
+		}
+		return store.Range{StartRow: encode(lo), StopRow: stop}
+	}
+	// 倒序扫描从 StartRow（含）到 StopRow（不含），lo 为 0 时以 BizId 前缀作为下界
+	stop := codec.Prefix(bizID)
+	if lo > 0 {
+		stop = encode(lo - 1)
+	}
+	return store.Range{StartRow: encode(hi), StopRow: stop, Reverse: true}
+}
+
+// Biz 返回 BizId 所有行的扫描范围，按 rowkey 升序（reverse 为 true 时降序）
+func Biz(codec rowkey.Codec, bizID []byte, reverse bool) store.Range {
+	return Ordinals(codec, bizID, 0, ^uint32(0), reverse)
+}
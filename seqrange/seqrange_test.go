@@ -0,0 +1,443 @@
+package seqrange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/quick"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/rowkey"
+	"go-hbase-demo/store"
+)
+
+// codecCase 是被测编码及其预期的 Seq 排列顺序，before(a, b) 表示 a 的 rowkey 排在 b 之前
+type codecCase struct {
+	name   string
+	codec  rowkey.Codec
+	before func(a, b int32) bool
+}
+
+func codecCases(t *testing.T) []codecCase {
+	salted, err := rowkey.NewSalted(4, rowkey.XXHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := func(a, b int32) bool { return a > b }
+	return []codecCase{
+		{"legacy", rowkey.Legacy{}, func(a, b int32) bool { return uint32(a) > uint32(b) }},
+		{"binary", rowkey.Binary{}, desc},
+		{"salted", salted, desc},
+	}
+}
+
+const testBiz = "biz1"
+
+// 与 testBiz 相邻的 BizId，扫描结果中不应出现
+var neighbourBizIDs = []string{"biz", "biz0", "biz10", "biz2", "a", "c"}
+
+// interesting 包含 int32 两端及 0 附近的值，用于触发边界上的溢出
+var interesting = []int32{math.MinInt32, math.MinInt32 + 1, -2, -1, 0, 1, 2, math.MaxInt32 - 1, math.MaxInt32}
+
+func newStore(t *testing.T, codec rowkey.Codec, seqs []int32) store.SeqStore {
+	t.Helper()
+	s := store.NewMemoryStore(store.Options{RowKey: codec.Encode})
+	var items []*pb.SeqItem
+	for _, seq := range seqs {
+		items = append(items, &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte(testBiz), Seq: seq}})
+		for _, biz := range neighbourBizIDs {
+			items = append(items, &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte(biz), Seq: seq}})
+		}
+	}
+	if _, err := s.Put(context.Background(), items, 0); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// scanSeqs 按 Bounds 计算的范围扫描 s，返回结果的 BizId 和 Seq
+func scanSeqs(t *testing.T, s store.SeqStore, codec rowkey.Codec, req *pb.RangeReq) []int32 {
+	t.Helper()
+	rng, ok, err := Bounds(codec, req)
+	if err != nil {
+		t.Fatalf("Bounds(%v) error = %v", req, err)
+	}
+	if !ok {
+		return nil
+	}
+	scanner, err := s.Scan(context.Background(), rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer scanner.Close()
+	var seqs []int32
+	for {
+		row, err := scanner.Next()
+		if err == io.EOF {
+			return seqs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := codec.Decode(row.Key)
+		if err != nil {
+			t.Fatalf("Decode(%q) error = %v", row.Key, err)
+		}
+		if string(key.BizId) != testBiz {
+			t.Fatalf("Bounds(%v) scanned row %q of another biz", req, row.Key)
+		}
+		seqs = append(seqs, key.Seq)
+	}
+}
+
+// expectSeqs 按区间定义直接从 seqs 中筛选并排序，作为 Bounds 的参照实现
+func expectSeqs(c codecCase, seqs []int32, req *pb.RangeReq) []int32 {
+	start, end := req.Start.Seq, req.End.Seq
+	withStart := req.Option == pb.RangeOption_WithBoth || req.Option == pb.RangeOption_WithoutEnd
+	withEnd := req.Option == pb.RangeOption_WithBoth || req.Option == pb.RangeOption_WithoutStart
+	lo, hi := start, end
+	if c.before(end, start) {
+		lo, hi = end, start
+	}
+	var want []int32
+	for _, seq := range seqs {
+		if c.before(seq, lo) || c.before(hi, seq) {
+			continue
+		}
+		if (seq == start && !withStart) || (seq == end && !withEnd) {
+			continue
+		}
+		want = append(want, seq)
+	}
+	// 从 Start 向 End 排列，Reverse 时反过来
+	ascending := !c.before(end, start) != req.Reverse
+	sort.Slice(want, func(i, j int) bool {
+		if ascending {
+			return c.before(want[i], want[j])
+		}
+		return c.before(want[j], want[i])
+	})
+	return want
+}
+
+func newReq(start, end int32, option pb.RangeOption, reverse bool) *pb.RangeReq {
+	return &pb.RangeReq{
+		Start:   &pb.SeqKey{BizId: []byte(testBiz), Seq: start},
+		End:     &pb.SeqKey{BizId: []byte(testBiz), Seq: end},
+		Option:  option,
+		Reverse: reverse,
+	}
+}
+
+func TestBounds_Examples(t *testing.T) {
+	seqs := []int32{1, 2, 3, 4, 5}
+	tests := []struct {
+		start, end int32
+		option     pb.RangeOption
+		reverse    bool
+		want       []int32
+	}{
+		{4, 2, pb.RangeOption_WithBoth, false, []int32{4, 3, 2}},
+		{4, 2, pb.RangeOption_WithoutStart, false, []int32{3, 2}},
+		{4, 2, pb.RangeOption_WithoutEnd, false, []int32{4, 3}},
+		{4, 2, pb.RangeOption_WithoutBoth, false, []int32{3}},
+		{4, 2, pb.RangeOption_WithBoth, true, []int32{2, 3, 4}},
+		{4, 2, pb.RangeOption_WithoutStart, true, []int32{2, 3}},
+		{4, 2, pb.RangeOption_WithoutEnd, true, []int32{3, 4}},
+		{4, 2, pb.RangeOption_WithoutBoth, true, []int32{3}},
+		{2, 4, pb.RangeOption_WithoutEnd, false, []int32{2, 3}},
+		{2, 4, pb.RangeOption_WithoutEnd, true, []int32{3, 2}},
+		{3, 3, pb.RangeOption_WithBoth, false, []int32{3}},
+		{3, 3, pb.RangeOption_WithoutEnd, false, nil},
+		{3, 2, pb.RangeOption_WithoutBoth, false, nil},
+		{10, 0, pb.RangeOption_WithBoth, false, []int32{5, 4, 3, 2, 1}},
+	}
+	for _, c := range codecCases(t) {
+		s := newStore(t, c.codec, seqs)
+		for _, tt := range tests {
+			req := newReq(tt.start, tt.end, tt.option, tt.reverse)
+			if got := scanSeqs(t, s, c.codec, req); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s: %d..%d %v reverse=%v = %v, want %v", c.name, tt.start, tt.end, tt.option, tt.reverse, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestBounds_Overflow(t *testing.T) {
+	for _, c := range codecCases(t) {
+		s := newStore(t, c.codec, interesting)
+		for _, start := range interesting {
+			for _, end := range interesting {
+				for option := range pb.RangeOption_name {
+					for _, reverse := range []bool{false, true} {
+						req := newReq(start, end, pb.RangeOption(option), reverse)
+						got := scanSeqs(t, s, c.codec, req)
+						if want := expectSeqs(c, interesting, req); !reflect.DeepEqual(got, want) {
+							t.Errorf("%s: %v = %v, want %v", c.name, req, got, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// pick 从 interesting、已写入的 seq 和随机值中选取端点
+func pick(r *rand.Rand, seqs []int32) int32 {
+	switch r.Intn(3) {
+	case 0:
+		return interesting[r.Intn(len(interesting))]
+	case 1:
+		if len(seqs) > 0 {
+			return seqs[r.Intn(len(seqs))]
+		}
+	}
+	return int32(r.Uint32())
+}
+
+func TestBounds_Property(t *testing.T) {
+	for _, c := range codecCases(t) {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			property := func(seed int64, option uint8, reverse bool) bool {
+				r := rand.New(rand.NewSource(seed))
+				seen := map[int32]bool{}
+				var seqs []int32
+				for i := r.Intn(20); i > 0; i-- {
+					seq := pick(r, seqs)
+					if r.Intn(2) == 0 {
+						seq = int32(r.Intn(41) - 20) // 集中在小范围内，增加端点命中已有行的概率
+					}
+					if !seen[seq] {
+						seen[seq] = true
+						seqs = append(seqs, seq)
+					}
+				}
+				req := newReq(pick(r, seqs), pick(r, seqs), pb.RangeOption(option%4), reverse)
+				got := scanSeqs(t, newStore(t, c.codec, seqs), c.codec, req)
+				want := expectSeqs(c, seqs, req)
+				if !reflect.DeepEqual(got, want) {
+					t.Logf("seqs %v, %v = %v, want %v", seqs, req, got, want)
+					return false
+				}
+				return true
+			}
+			if err := quick.Check(property, &quick.Config{MaxCount: 500}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestBounds_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *pb.RangeReq
+		want error
+	}{
+		{"missing start", &pb.RangeReq{End: &pb.SeqKey{BizId: []byte(testBiz)}}, ErrMissingKey},
+		{"missing end", &pb.RangeReq{Start: &pb.SeqKey{BizId: []byte(testBiz)}}, ErrMissingKey},
+		{"biz mismatch", &pb.RangeReq{Start: &pb.SeqKey{BizId: []byte("a")}, End: &pb.SeqKey{BizId: []byte("b")}}, ErrBizMismatch},
+	}
+	for _, tt := range tests {
+		if _, _, err := Bounds(rowkey.Binary{}, tt.req); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Bounds() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestBiz(t *testing.T) {
+	for _, c := range codecCases(t) {
+		s := newStore(t, c.codec, interesting)
+		req := newReq(0, 0, pb.RangeOption_WithBoth, false)
+		want := expectSeqs(c, interesting, newReq(c.codec.SeqAt(0), c.codec.SeqAt(math.MaxUint32), pb.RangeOption_WithBoth, false))
+		for _, reverse := range []bool{false, true} {
+			scanner, err := s.Scan(context.Background(), Biz(c.codec, req.Start.BizId, reverse))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int32
+			for row, err := scanner.Next(); err == nil; row, err = scanner.Next() {
+				key, err := c.codec.Decode(row.Key)
+				if err != nil || string(key.BizId) != testBiz {
+					t.Fatalf("%s: Biz() scanned unexpected row %q", c.name, row.Key)
+				}
+				got = append(got, key.Seq)
+			}
+			if reverse {
+				for i, j := 0, len(got)-1; i < j; i, j = i+1, j-1 {
+					got[i], got[j] = got[j], got[i]
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: Biz(reverse=%v) = %v, want %v", c.name, reverse, got, want)
+			}
+		}
+	}
+}
+
+// countingStore 统计 Scan 的调用次数
+type countingStore struct {
+	store.SeqStore
+	scans int
+}
+
+func (s *countingStore) Scan(ctx context.Context, rng store.Range) (store.Scanner, error) {
+	s.scans++
+	return s.SeqStore.Scan(ctx, rng)
+}
+
+func TestBizIDs(t *testing.T) {
+	long := func(n int, prefix string) string {
+		b := []byte(prefix)
+		for len(b) < n {
+			b = append(b, 'x')
+		}
+		return string(b)
+	}
+	// 长度跨过 uvarint 的一字节边界，且 256 字节的分组排在 129 字节之前
+	bizIDs := []string{"a", "ab", "abc", "abd", "ab_b", "b", "ba", "zab", long(129, "ab"), long(256, "ab"), long(256, "b")}
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"ab", []string{"ab", "ab_b", "abc", "abd", long(129, "ab"), long(256, "ab")}},
+		{"abc", []string{"abc"}},
+		{"b", []string{"b", "ba", long(256, "b")}},
+		{"c", nil},
+	}
+	for _, c := range codecCases(t) {
+		mem := store.NewMemoryStore(store.Options{RowKey: c.codec.Encode})
+		var items []*pb.SeqItem
+		for _, biz := range bizIDs {
+			for seq := int32(0); seq < 20; seq++ {
+				items = append(items, &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte(biz), Seq: seq}})
+			}
+		}
+		if _, err := mem.Put(context.Background(), items, 0); err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range tests {
+			s := &countingStore{SeqStore: mem}
+			got, err := BizIDs(context.Background(), s, c.codec, []byte(tt.prefix))
+			if err != nil {
+				t.Fatalf("%s: BizIDs(%q) error = %v", c.name, tt.prefix, err)
+			}
+			var gotStrings []string
+			for _, biz := range got {
+				gotStrings = append(gotStrings, string(biz))
+			}
+			if !reflect.DeepEqual(gotStrings, tt.want) {
+				t.Errorf("%s: BizIDs(%q) = %q, want %q", c.name, tt.prefix, gotStrings, tt.want)
+			}
+			if s.scans > 2*len(bizIDs)+8 {
+				t.Errorf("%s: BizIDs(%q) scanned %d times, want skip scans", c.name, tt.prefix, s.scans)
+			}
+		}
+	}
+}
+
+func TestBizIDs_Underscore(t *testing.T) {
+	// Legacy 中 "1" 和 "1_1" 盐值相同，行相互交错；"a" 和 "a_b" 分属不同的盐值桶
+	bizIDs := []string{"a", "a_b", "a_", "1", "1_1", "b_a"}
+	seqs := []int32{-1, 0, 1, 5, 10, 100, math.MaxInt32}
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"a", []string{"a", "a_", "a_b"}},
+		{"a_", []string{"a_", "a_b"}},
+		{"1", []string{"1", "1_1"}},
+		{"b", []string{"b_a"}},
+	}
+	for _, c := range codecCases(t) {
+		s := store.NewMemoryStore(store.Options{RowKey: c.codec.Encode})
+		var items []*pb.SeqItem
+		for _, biz := range bizIDs {
+			for _, seq := range seqs {
+				items = append(items, &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte(biz), Seq: seq}})
+			}
+		}
+		if _, err := s.Put(context.Background(), items, 0); err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range tests {
+			got, err := BizIDs(context.Background(), s, c.codec, []byte(tt.prefix))
+			if err != nil {
+				t.Fatalf("%s: BizIDs(%q) error = %v", c.name, tt.prefix, err)
+			}
+			var gotStrings []string
+			for _, biz := range got {
+				gotStrings = append(gotStrings, string(biz))
+			}
+			if !reflect.DeepEqual(gotStrings, tt.want) {
+				t.Errorf("%s: BizIDs(%q) = %q, want %q", c.name, tt.prefix, gotStrings, tt.want)
+			}
+		}
+	}
+}
+
+func TestMultiBounds(t *testing.T) {
+	seqs := []int32{0, 1, 2, 3, math.MaxInt32}
+	bizIDs := []string{"biz1", "biz2"}
+	tests := []struct {
+		name string
+		req  *pb.RangeReq
+		want []string
+	}{
+		{"all", &pb.RangeReq{}, []string{"biz1/0", "biz1/1", "biz1/2", "biz1/3", "biz1/2147483647", "biz2/0", "biz2/1", "biz2/2", "biz2/3", "biz2/2147483647"}},
+		{"reverse", &pb.RangeReq{Start: &pb.SeqKey{Seq: 1}, End: &pb.SeqKey{Seq: 2}, Reverse: true}, []string{"biz2/2", "biz2/1", "biz1/2", "biz1/1"}},
+		{"swapped without start", &pb.RangeReq{Start: &pb.SeqKey{Seq: 3}, End: &pb.SeqKey{Seq: 1}, Option: pb.RangeOption_WithoutStart}, []string{"biz1/1", "biz1/2", "biz2/1", "biz2/2"}},
+		{"empty", &pb.RangeReq{Start: &pb.SeqKey{Seq: 1}, End: &pb.SeqKey{Seq: 1}, Option: pb.RangeOption_WithoutEnd}, nil},
+	}
+	for _, c := range codecCases(t) {
+		s := store.NewMemoryStore(store.Options{RowKey: c.codec.Encode})
+		var items []*pb.SeqItem
+		for _, biz := range append([]string{"biz", "biz10", "biz3"}, bizIDs...) {
+			for _, seq := range seqs {
+				items = append(items, &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte(biz), Seq: seq}})
+			}
+		}
+		if _, err := s.Put(context.Background(), items, 0); err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range tests {
+			tt.req.BizIds = [][]byte{[]byte("biz2"), []byte("biz1"), []byte("biz2")}
+			order, err := MultiBizIDs(context.Background(), s, c.codec, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, biz := range order {
+				rng, ok, err := MultiBounds(c.codec, tt.req, biz)
+				if err != nil {
+					t.Fatalf("%s: MultiBounds() error = %v", c.name, err)
+				}
+				if !ok {
+					continue
+				}
+				scanner, err := s.Scan(context.Background(), rng)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for row, err := scanner.Next(); err == nil; row, err = scanner.Next() {
+					key, err := c.codec.Decode(row.Key)
+					if err != nil {
+						t.Fatal(err)
+					}
+					got = append(got, fmt.Sprintf("%s/%d", key.BizId, key.Seq))
+				}
+				scanner.Close()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s %s: got %v, want %v", c.name, tt.name, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"demo/gen-go/hbase" // Thrift 生成的 HBase 客户端代码
+
+	"github.com/tsuna/gohbase"
+	"github.com/tsuna/gohbase/hrpc"
+	hbasepb "github.com/tsuna/gohbase/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// SplitTableName 将 ns:table 形式的表名拆分为 namespace 和表名，没有 namespace 时 ns 为空
+func SplitTableName(table string) (ns, name string) {
+	if i := strings.IndexByte(table, ':'); i >= 0 {
+		return table[:i], table[i+1:]
+	}
+	return "", table
+}
+
+// IsExists 判断 HBase 返回的错误是否为表或 namespace 已存在，并发创建时后创建的一方会收到该错误
+func IsExists(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "TableExists") || strings.Contains(err.Error(), "NamespaceExist"))
+}
+
+// Provision 在表不存在时创建表，namespace 不存在时先创建 namespace；
+// 表只包含 Options.Family 一个列族，使用默认的列族属性
+func (s *ThriftStore) Provision(ctx context.Context, table string) error {
+	ns, name := SplitTableName(table)
+	tableName := &hbase.TTableName{Qualifier: []byte(name)}
+	if ns != "" {
+		tableName.Ns = []byte(ns)
+	}
+	exists, err := s.client.TableExists(ctx, tableName)
+	if err != nil || exists {
+		return err
+	}
+	if ns != "" {
+		if _, err := s.client.GetNamespaceDescriptor(ctx, ns); err != nil {
+			if err := s.client.CreateNamespace(ctx, &hbase.TNamespaceDescriptor{Name: ns}); err != nil && !IsExists(err) {
+				return err
+			}
+		}
+	}
+	err = s.client.CreateTable(ctx, &hbase.TTableDescriptor{
+		TableName: tableName,
+		Columns:   []*hbase.TColumnFamilyDescriptor{{Name: []byte(s.opts.Family)}},
+	}, nil)
+	if IsExists(err) {
+		return nil
+	}
+	return err
+}
+
+// HBaseProvisioner 通过 gohbase 的 AdminClient 创建 namespace 和表
+// AdminClient 没有提供 namespace 相关的接口，CreateNamespace 通过其底层的 RPCClient 直接发送给 Master
+type HBaseProvisioner struct {
+	admin  gohbase.AdminClient
+	rpc    gohbase.RPCClient // admin 不支持直接发送 RPC 时为 nil，此时 namespace 须事先创建
+	family string
+}
+
+// NewHBaseProvisioner 创建只包含 family 一个列族的表的 HBaseProvisioner
+func NewHBaseProvisioner(admin gohbase.AdminClient, family string) *HBaseProvisioner {
+	rpc, _ := admin.(gohbase.RPCClient)
+	return &HBaseProvisioner{admin: admin, rpc: rpc, family: family}
+}
+
+// 刚创建的 namespace 可能还不能用于建表，建表以 NamespaceNotFound 失败时按退避重试
+const (
+	createTableRetries = 5
+	createTableBackoff = 100 * time.Millisecond
+)
+
+// Provision 在表不存在时创建表，namespace 不存在时先创建 namespace
+func (p *HBaseProvisioner) Provision(ctx context.Context, table string) error {
+	if ns, _ := SplitTableName(table); ns != "" && p.rpc != nil {
+		if _, err := p.rpc.SendRPC(newCreateNamespace(ctx, ns)); err != nil && !IsExists(err) {
+			return fmt.Errorf("create namespace %s: %v", ns, err)
+		}
+	}
+	backoff := createTableBackoff
+	for i := 0; ; i++ {
+		err := p.admin.CreateTable(hrpc.NewCreateTable(ctx, []byte(table), map[string]map[string]string{p.family: nil}))
+		if err == nil || IsExists(err) {
+			return nil
+		}
+		if i == createTableRetries || !strings.Contains(err.Error(), "NamespaceNotFound") {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// createNamespace 是 Master 的 CreateNamespace RPC
+type createNamespace struct {
+	ctx      context.Context
+	ns       string
+	region   hrpc.RegionInfo
+	resultch chan hrpc.RPCResult
+}
+
+func newCreateNamespace(ctx context.Context, ns string) *createNamespace {
+	return &createNamespace{ctx: ctx, ns: ns, resultch: make(chan hrpc.RPCResult, 1)}
+}
+
+func (c *createNamespace) Table() []byte                    { return nil }
+func (c *createNamespace) Key() []byte                      { return nil }
+func (c *createNamespace) Name() string                     { return "CreateNamespace" }
+func (c *createNamespace) Description() string              { return c.Name() }
+func (c *createNamespace) Context() context.Context         { return c.ctx }
+func (c *createNamespace) Region() hrpc.RegionInfo          { return c.region }
+func (c *createNamespace) SetRegion(region hrpc.RegionInfo) { c.region = region }
+func (c *createNamespace) ResultChan() chan hrpc.RPCResult  { return c.resultch }
+func (c *createNamespace) NewResponse() proto.Message       { return &hbasepb.CreateNamespaceResponse{} }
+func (c *createNamespace) ToProto() proto.Message {
+	return &hbasepb.CreateNamespaceRequest{NamespaceDescriptor: &hbasepb.NamespaceDescriptor{Name: []byte(c.ns)}}
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tsuna/gohbase"
+	"github.com/tsuna/gohbase/hrpc"
+	hbasepb "github.com/tsuna/gohbase/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeAdmin 记录收到的管理请求，建表前 notFound 次返回 NamespaceNotFound
+type fakeAdmin struct {
+	gohbase.AdminClient
+	calls    []string
+	nsErr    error
+	notFound int
+}
+
+func (a *fakeAdmin) SendRPC(rpc hrpc.Call) (proto.Message, error) {
+	req := rpc.ToProto().(*hbasepb.CreateNamespaceRequest)
+	a.calls = append(a.calls, rpc.Name()+" "+string(req.NamespaceDescriptor.Name))
+	return rpc.NewResponse(), a.nsErr
+}
+
+func (a *fakeAdmin) CreateTable(t *hrpc.CreateTable) error {
+	a.calls = append(a.calls, "CreateTable "+string(t.Table()))
+	if a.notFound > 0 {
+		a.notFound--
+		return errors.New("org.apache.hadoop.hbase.NamespaceNotFoundException: t1")
+	}
+	return nil
+}
+
+func TestHBaseProvisioner(t *testing.T) {
+	tests := []struct {
+		name    string
+		admin   *fakeAdmin
+		table   string
+		want    []string
+		wantErr bool
+	}{
+		{"no namespace", &fakeAdmin{}, "seqdb", []string{"CreateTable seqdb"}, false},
+		{"namespace", &fakeAdmin{}, "t1:seqdb", []string{"CreateNamespace t1", "CreateTable t1:seqdb"}, false},
+		{
+			name:  "namespace exists",
+			admin: &fakeAdmin{nsErr: errors.New("org.apache.hadoop.hbase.NamespaceExistException: t1")},
+			table: "t1:seqdb",
+			want:  []string{"CreateNamespace t1", "CreateTable t1:seqdb"},
+		},
+		{
+			name:    "namespace error",
+			admin:   &fakeAdmin{nsErr: errors.New("master unavailable")},
+			table:   "t1:seqdb",
+			want:    []string{"CreateNamespace t1"},
+			wantErr: true,
+		},
+		{
+			name:  "namespace not ready",
+			admin: &fakeAdmin{notFound: 2},
+			table: "t1:seqdb",
+			want:  []string{"CreateNamespace t1", "CreateTable t1:seqdb", "CreateTable t1:seqdb", "CreateTable t1:seqdb"},
+		},
+	}
+	for _, tt := range tests {
+		err := NewHBaseProvisioner(tt.admin, "cf").Provision(context.Background(), tt.table)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Provision() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if !reflect.DeepEqual(tt.admin.calls, tt.want) {
+			t.Errorf("%s: calls = %q, want %q", tt.name, tt.admin.calls, tt.want)
+		}
+	}
+}
+
+// CreateNamespace 依赖 gohbase 的 AdminClient 同时实现 RPCClient
+func TestHBaseProvisioner_RPCClient(t *testing.T) {
+	if p := NewHBaseProvisioner(gohbase.NewAdminClient("localhost:2181"), "cf"); p.rpc == nil {
+		t.Error("gohbase admin client does not implement RPCClient, namespaces cannot be created")
+	}
+}
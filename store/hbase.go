@@ -0,0 +1,320 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	pb "go-hbase-demo/cloudpb"
+
+	"github.com/tsuna/gohbase"
+	"github.com/tsuna/gohbase/filter"
+	"github.com/tsuna/gohbase/hrpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// HBaseStore 通过 gohbase 原生 RPC 访问 HBase / Lindorm
+type HBaseStore struct {
+	client gohbase.Client
+	opts   Options
+	shared bool // client 由 WithTable 的调用方持有，Close 时不关闭
+}
+
+// NewHBaseStore 基于已创建的 gohbase 客户端创建存储后端
+func NewHBaseStore(client gohbase.Client, opts Options) *HBaseStore {
+	return &HBaseStore{client: client, opts: opts}
+}
+
+// WithTable 返回访问 table 的存储后端，与 s 共用 gohbase 客户端，客户端由 s 关闭
+func (s *HBaseStore) WithTable(table string) *HBaseStore {
+	opts := s.opts
+	opts.Table = table
+	return &HBaseStore{client: s.client, opts: opts, shared: true}
+}
+
+// Put 并发发出所有 Put 请求，由 gohbase 的 region client 按 RegionServer 合并为 MultiRequest
+// 各请求独立成功或失败，失败的 item 记录在 *PutError 中；每个 item 以 putTimestamp 指定的时间戳写入
+func (s *HBaseStore) Put(ctx context.Context, items []*pb.SeqItem, ttl time.Duration) ([]int64, error) {
+	batch := make([]*hrpc.Mutate, 0, len(items))
+	written := make([]int64, 0, len(items))
+	for _, item := range items {
+		// 序列化 SeqItem
+		data, err := marshalItem(item)
+		if err != nil {
+			return nil, err
+		}
+		ts := putTimestamp()
+		putRequest, err := hrpc.NewPut(ctx, []byte(s.opts.Table), s.opts.RowKey(item.Key), map[string]map[string][]byte{
+			s.opts.Family: {s.opts.Qualifier: data},
+		}, append(ttlOptions(ttl), hrpc.TimestampUint64(uint64(ts)))...)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, putRequest)
+		written = append(written, ts)
+	}
+
+	errs := make([]error, len(batch))
+	timestamps := make([]int64, len(batch))
+	var wg sync.WaitGroup
+	for i, putRequest := range batch {
+		wg.Add(1)
+		go func(i int, putRequest *hrpc.Mutate) {
+			defer wg.Done()
+			if _, errs[i] = s.client.Put(putRequest); errs[i] == nil {
+				timestamps[i] = written[i]
+			}
+		}(i, putRequest)
+	}
+	wg.Wait()
+	return timestamps, newPutError(errs)
+}
+
+// Get 与 Put 一样并发发出所有 Get 请求
+func (s *HBaseStore) Get(ctx context.Context, keys []*pb.SeqKey) ([]*pb.SeqItem, error) {
+	batch := make([]*hrpc.Get, 0, len(keys))
+	for _, key := range keys {
+		getRequest, err := hrpc.NewGet(ctx, []byte(s.opts.Table), s.opts.RowKey(key), s.columns())
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, getRequest)
+	}
+
+	rsps := make([]*hrpc.Result, len(batch))
+	errs := make([]error, len(batch))
+	var wg sync.WaitGroup
+	for i, getRequest := range batch {
+		wg.Add(1)
+		go func(i int, getRequest *hrpc.Get) {
+			defer wg.Done()
+			rsps[i], errs[i] = s.client.Get(getRequest)
+		}(i, getRequest)
+	}
+	wg.Wait()
+	if err := firstError(errs); err != nil {
+		return nil, err
+	}
+
+	items := make([]*pb.SeqItem, len(batch))
+	for i, getRsp := range rsps {
+		if getRsp == nil || len(getRsp.Cells) == 0 {
+			continue
+		}
+		item := &pb.SeqItem{}
+		if err := proto.Unmarshal(getRsp.Cells[0].Value, item); err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// GetRow 读取一行中满足 versions 的版本
+func (s *HBaseStore) GetRow(ctx context.Context, rowKey []byte, versions Versions) (*Row, error) {
+	options := append([]func(hrpc.Call) error{s.columns()}, versionOptions(versions)...)
+	getRequest, err := hrpc.NewGet(ctx, []byte(s.opts.Table), rowKey, options...)
+	if err != nil {
+		return nil, err
+	}
+	getRsp, err := s.client.Get(getRequest)
+	if err != nil || getRsp == nil || len(getRsp.Cells) == 0 {
+		return nil, err
+	}
+	return resultRow(getRsp.Cells), nil
+}
+
+// CheckAndPut 通过 HBase CheckAndPut 原子地比较并写入
+func (s *HBaseStore) CheckAndPut(ctx context.Context, item, expected *pb.SeqItem, ttl time.Duration) (int64, error) {
+	data, err := marshalItem(item)
+	if err != nil {
+		return 0, err
+	}
+	expectedData, err := marshalExpected(expected)
+	if err != nil {
+		return 0, err
+	}
+	ts := putTimestamp()
+	putRequest, err := hrpc.NewPut(ctx, []byte(s.opts.Table), s.opts.RowKey(item.Key), map[string]map[string][]byte{
+		s.opts.Family: {s.opts.Qualifier: data},
+	}, append(ttlOptions(ttl), hrpc.TimestampUint64(uint64(ts)))...)
+	if err != nil {
+		return 0, err
+	}
+	applied, err := s.client.CheckAndPut(putRequest, s.opts.Family, s.opts.Qualifier, expectedData)
+	if err != nil || !applied {
+		return 0, err
+	}
+	return ts, nil
+}
+
+// Scan 创建 HBase 扫描请求
+func (s *HBaseStore) Scan(ctx context.Context, rng Range) (Scanner, error) {
+	options := []func(hrpc.Call) error{s.columns()}
+	if rng.Reverse {
+		options = append(options, hrpc.Reversed())
+	}
+	if rng.KeysOnly {
+		options = append(options, hrpc.Filters(filter.NewKeyOnlyFilter(false)))
+	}
+	options = append(options, versionOptions(rng.Versions)...)
+	if rng.Limit > 0 {
+		// 每次 RPC 最多拉取 Limit 行，避免为少量结果读取整个 region
+		options = append(options, hrpc.NumberOfRows(uint32(rng.Limit)))
+	}
+	scanRequest, err := hrpc.NewScanRange(ctx, []byte(s.opts.Table), rng.StartRow, rng.StopRow, options...)
+	if err != nil {
+		return nil, err
+	}
+	return limitRows(&hbaseScanner{scanner: s.client.Scan(scanRequest)}, rng.Limit), nil
+}
+
+// Delete 并发删除所有行
+func (s *HBaseStore) Delete(ctx context.Context, rowKeys [][]byte) error {
+	errs := make([]error, len(rowKeys))
+	var wg sync.WaitGroup
+	for i, rowKey := range rowKeys {
+		deleteRequest, err := hrpc.NewDel(ctx, []byte(s.opts.Table), rowKey, nil)
+		if err != nil {
+			return err
+		}
+		wg.Add(1)
+		go func(i int, deleteRequest *hrpc.Mutate) {
+			defer wg.Done()
+			_, errs[i] = s.client.Delete(deleteRequest)
+		}(i, deleteRequest)
+	}
+	wg.Wait()
+	return firstError(errs)
+}
+
+// Increment 通过 HBase Increment 原子地更新计数器
+func (s *HBaseStore) Increment(ctx context.Context, rowKey []byte, delta int64) (int64, error) {
+	inc, err := hrpc.NewIncSingle(ctx, []byte(s.opts.Table), rowKey, s.opts.Family, s.opts.Counter, delta)
+	if err != nil {
+		return 0, err
+	}
+	return s.client.Increment(inc)
+}
+
+// InitCounter 通过 CheckAndPut 在计数器列不存在时写入初始值
+func (s *HBaseStore) InitCounter(ctx context.Context, rowKey []byte, value int64) (bool, error) {
+	put, err := hrpc.NewPut(ctx, []byte(s.opts.Table), rowKey, map[string]map[string][]byte{
+		s.opts.Family: {s.opts.Counter: encodeCounter(value)},
+	})
+	if err != nil {
+		return false, err
+	}
+	return s.client.CheckAndPut(put, s.opts.Family, s.opts.Counter, nil)
+}
+
+// PutWAL 通过 CheckAndPut 比较并写入日志列
+func (s *HBaseStore) PutWAL(ctx context.Context, rowKey, expected, record []byte) (bool, error) {
+	put, err := hrpc.NewPut(ctx, []byte(s.opts.Table), rowKey, map[string]map[string][]byte{
+		s.opts.Family: {s.opts.WAL: record},
+	})
+	if err != nil {
+		return false, err
+	}
+	return s.client.CheckAndPut(put, s.opts.Family, s.opts.WAL, expected)
+}
+
+// GetWAL 只读取日志列
+func (s *HBaseStore) GetWAL(ctx context.Context, rowKey []byte) ([]byte, error) {
+	getRequest, err := hrpc.NewGet(ctx, []byte(s.opts.Table), rowKey, hrpc.Families(map[string][]string{s.opts.Family: {s.opts.WAL}}))
+	if err != nil {
+		return nil, err
+	}
+	getRsp, err := s.client.Get(getRequest)
+	if err != nil || getRsp == nil || len(getRsp.Cells) == 0 || len(getRsp.Cells[0].Value) == 0 {
+		return nil, err // 空值是 DeleteWAL 留下的删除标记
+	}
+	return getRsp.Cells[0].Value, nil
+}
+
+// DeleteWAL 以 CheckAndPut 将日志列置为空值作为删除标记
+// gohbase 不支持 CheckAndDelete；HBase 的 CheckAndPut 在期望值为空时同样匹配空值，之后的 PutWAL 可以照常加锁
+func (s *HBaseStore) DeleteWAL(ctx context.Context, rowKey, expected []byte) (bool, error) {
+	return s.PutWAL(ctx, rowKey, expected, []byte{})
+}
+
+// Close 关闭 gohbase 客户端，WithTable 返回的存储后端不关闭共用的客户端
+func (s *HBaseStore) Close() {
+	if !s.shared {
+		s.client.Close()
+	}
+}
+
+// columns 将读取限定在 Options 指定的列上，结果中的 Cells[0] 即为 SeqItem
+func (s *HBaseStore) columns() func(hrpc.Call) error {
+	return hrpc.Families(map[string][]string{s.opts.Family: {s.opts.Qualifier}})
+}
+
+// ttlOptions 在 ttl 大于 0 时设置 cell TTL，hrpc.TTL(0) 会使 cell 立即过期
+func ttlOptions(ttl time.Duration) []func(hrpc.Call) error {
+	if ttl <= 0 {
+		return nil
+	}
+	return []func(hrpc.Call) error{hrpc.TTL(ttl)}
+}
+
+// versionOptions 将 versions 转换为 Get / Scan 的 TimeRange 和 MaxVersions 选项
+func versionOptions(versions Versions) []func(hrpc.Call) error {
+	var options []func(hrpc.Call) error
+	if min, max, ok := versions.timeRange(); ok {
+		options = append(options, hrpc.TimeRangeUint64(uint64(min), uint64(max)))
+	}
+	if n := versions.maxVersions(); n > 1 {
+		options = append(options, hrpc.MaxVersions(uint32(n)))
+	}
+	return options
+}
+
+// resultRow 将同一列的多个版本转换为 Row，HBase 返回的版本已按时间戳从新到旧排列
+func resultRow(cells []*hrpc.Cell) *Row {
+	versions := make([]Cell, len(cells))
+	for i, cell := range cells {
+		versions[i] = Cell{Value: cell.Value}
+		if cell.Timestamp != nil {
+			versions[i].Timestamp = int64(*cell.Timestamp)
+		}
+	}
+	return newRow(bytes.Clone(cells[0].Row), versions)
+}
+
+// hbaseScanner 将 hrpc.Scanner 的结果转换为 Row
+type hbaseScanner struct {
+	scanner hrpc.Scanner
+}
+
+func (s *hbaseScanner) Next() (*Row, error) {
+	for {
+		res, err := s.scanner.Next()
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			return nil, io.EOF // 扫描结束
+		}
+		if len(res.Cells) == 0 {
+			continue
+		}
+		return resultRow(res.Cells), nil
+	}
+}
+
+func (s *hbaseScanner) Close() error {
+	return s.scanner.Close()
+}
+
+// firstError 返回第一个非空错误
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
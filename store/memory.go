@@ -0,0 +1,315 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	pb "go-hbase-demo/cloudpb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// memoryMaxVersions 是内存存储每行保留的版本数
+const memoryMaxVersions = 16
+
+// MemoryStore 是基于有序 map 的内存存储，rowkey 按字节序排列，与 HBase 的扫描顺序一致
+// 每行保留最近 memoryMaxVersions 个版本，过期的版本在读取时跳过，适用于本地开发和不依赖集群的测试
+type MemoryStore struct {
+	mu   sync.RWMutex
+	keys []string                // 按字节序排列的 rowkey
+	rows map[string][]memoryCell // rowkey -> 序列化的 SeqItem 的各个版本，按时间戳从新到旧
+	// counters 保存计数器，计数器列与 SeqItem 列相互独立，不参与 Scan
+	counters map[string]int64
+	// wals 保存原子 Put 的预写日志，同样不参与 Scan
+	wals map[string][]byte
+	opts Options
+}
+
+// NewMemoryStore 创建空的内存存储，只使用 opts.RowKey
+func NewMemoryStore(opts Options) *MemoryStore {
+	return &MemoryStore{rows: map[string][]memoryCell{}, counters: map[string]int64{}, wals: map[string][]byte{}, opts: opts}
+}
+
+// memoryCell 是带过期时间的版本
+type memoryCell struct {
+	Cell
+	expires int64 // 过期时间，Unix 毫秒，0 表示不过期
+}
+
+// newMemoryCell 生成以 ts 写入、ttl 后过期的版本
+func newMemoryCell(ts int64, value []byte, ttl time.Duration) memoryCell {
+	cell := memoryCell{Cell: Cell{Timestamp: ts, Value: value}}
+	if ttl > 0 {
+		cell.expires = ts + ttl.Milliseconds()
+	}
+	return cell
+}
+
+func (c memoryCell) expired(now int64) bool {
+	return c.expires != 0 && c.expires <= now
+}
+
+// Put 写入 items，时间戳由 nextTimestamp 分配
+func (s *MemoryStore) Put(ctx context.Context, items []*pb.SeqItem, ttl time.Duration) ([]int64, error) {
+	values := make([][]byte, 0, len(items))
+	for _, item := range items {
+		// 序列化 SeqItem
+		data, err := marshalItem(item)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, data)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	timestamps := make([]int64, len(items))
+	for i, item := range items {
+		rowKey := string(s.opts.RowKey(item.Key))
+		timestamps[i] = s.nextTimestamp(rowKey)
+		s.insert(rowKey, newMemoryCell(timestamps[i], values[i], ttl))
+	}
+	return timestamps, nil
+}
+
+// nextTimestamp 为 rowKey 的新版本分配时间戳：当前毫秒时间，且大于该行已有的最新版本，
+// 同一毫秒内的多次写入不会合并为一个版本，调用方需持有写锁
+func (s *MemoryStore) nextTimestamp(rowKey string) int64 {
+	ts := time.Now().UnixMilli()
+	if versions := s.rows[rowKey]; len(versions) > 0 && versions[0].Timestamp >= ts {
+		ts = versions[0].Timestamp + 1
+	}
+	return ts
+}
+
+// insert 写入一个版本并维护 keys 的顺序，相同时间戳的版本会被覆盖，调用方需持有写锁
+func (s *MemoryStore) insert(rowKey string, cell memoryCell) {
+	versions, ok := s.rows[rowKey]
+	if !ok {
+		idx := sort.SearchStrings(s.keys, rowKey)
+		s.keys = append(s.keys, "")
+		copy(s.keys[idx+1:], s.keys[idx:])
+		s.keys[idx] = rowKey
+	}
+	idx := sort.Search(len(versions), func(i int) bool { return versions[i].Timestamp <= cell.Timestamp })
+	if idx < len(versions) && versions[idx].Timestamp == cell.Timestamp {
+		versions[idx] = cell
+	} else {
+		versions = slices.Insert(versions, idx, cell)
+	}
+	if len(versions) > memoryMaxVersions {
+		versions = versions[:memoryMaxVersions]
+	}
+	s.rows[rowKey] = versions
+}
+
+// latest 返回 rowKey 未过期的最新版本，调用方需持有锁
+func (s *MemoryStore) latest(rowKey string) ([]byte, bool) {
+	now := time.Now().UnixMilli()
+	for _, cell := range s.rows[rowKey] {
+		if !cell.expired(now) {
+			return cell.Value, true
+		}
+	}
+	return nil, false
+}
+
+// CheckAndPut 在当前值与 expected 的序列化结果相同时写入 item
+func (s *MemoryStore) CheckAndPut(ctx context.Context, item, expected *pb.SeqItem, ttl time.Duration) (int64, error) {
+	data, err := marshalItem(item)
+	if err != nil {
+		return 0, err
+	}
+	expectedData, err := marshalExpected(expected)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rowKey := string(s.opts.RowKey(item.Key))
+	current, ok := s.latest(rowKey)
+	if ok != (expected != nil) || !bytes.Equal(current, expectedData) {
+		return 0, nil
+	}
+	ts := s.nextTimestamp(rowKey)
+	s.insert(rowKey, newMemoryCell(ts, data, ttl))
+	return ts, nil
+}
+
+// Get 读取 keys 对应的 SeqItem
+func (s *MemoryStore) Get(ctx context.Context, keys []*pb.SeqKey) ([]*pb.SeqItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]*pb.SeqItem, len(keys))
+	for i, key := range keys {
+		value, ok := s.latest(string(s.opts.RowKey(key)))
+		if !ok {
+			continue
+		}
+		item := &pb.SeqItem{}
+		if err := proto.Unmarshal(value, item); err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// GetRow 读取一行中满足 versions 的版本
+func (s *MemoryStore) GetRow(ctx context.Context, rowKey []byte, versions Versions) (*Row, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.row(string(rowKey), false, versions), nil
+}
+
+// Scan 复制区间内的行作为快照，之后的写入不影响已创建的 Scanner
+func (s *MemoryStore) Scan(ctx context.Context, rng Range) (Scanner, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var rows []*Row
+	full := func() bool { return rng.Limit > 0 && len(rows) >= rng.Limit }
+	if !rng.Reverse {
+		// 正序：[StartRow, StopRow)
+		for i := sort.SearchStrings(s.keys, string(rng.StartRow)); i < len(s.keys) && !full(); i++ {
+			if len(rng.StopRow) > 0 && s.keys[i] >= string(rng.StopRow) {
+				break
+			}
+			if row := s.row(s.keys[i], rng.KeysOnly, rng.Versions); row != nil {
+				rows = append(rows, row)
+			}
+		}
+	} else {
+		// 倒序：从 StartRow（含）向下到 StopRow（不含），StartRow 为空表示从最大的 rowkey 开始
+		i := len(s.keys) - 1
+		if len(rng.StartRow) > 0 {
+			i = sort.Search(len(s.keys), func(i int) bool { return s.keys[i] > string(rng.StartRow) }) - 1
+		}
+		for ; i >= 0 && !full(); i-- {
+			if len(rng.StopRow) > 0 && s.keys[i] <= string(rng.StopRow) {
+				break
+			}
+			if row := s.row(s.keys[i], rng.KeysOnly, rng.Versions); row != nil {
+				rows = append(rows, row)
+			}
+		}
+	}
+	return &memoryScanner{rows: rows}, nil
+}
+
+// Delete 删除指定 rowkey 的行，不存在的行被忽略
+func (s *MemoryStore) Delete(ctx context.Context, rowKeys [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rowKey := range rowKeys {
+		key := string(rowKey)
+		if _, ok := s.rows[key]; !ok {
+			continue
+		}
+		delete(s.rows, key)
+		idx := sort.SearchStrings(s.keys, key)
+		s.keys = append(s.keys[:idx], s.keys[idx+1:]...)
+	}
+	return nil
+}
+
+// Increment 为计数器加上 delta 并返回新值
+func (s *MemoryStore) Increment(ctx context.Context, rowKey []byte, delta int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counters[string(rowKey)] += delta
+	return s.counters[string(rowKey)], nil
+}
+
+// InitCounter 在计数器不存在时将其设为 value
+func (s *MemoryStore) InitCounter(ctx context.Context, rowKey []byte, value int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.counters[string(rowKey)]; ok {
+		return false, nil
+	}
+	s.counters[string(rowKey)] = value
+	return true, nil
+}
+
+// PutWAL 在当前日志与 expected 相同时写入 record
+func (s *MemoryStore) PutWAL(ctx context.Context, rowKey, expected, record []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.wals[string(rowKey)]
+	if ok != (expected != nil) || !bytes.Equal(current, expected) {
+		return false, nil
+	}
+	s.wals[string(rowKey)] = bytes.Clone(record)
+	return true, nil
+}
+
+// GetWAL 读取日志
+func (s *MemoryStore) GetWAL(ctx context.Context, rowKey []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return bytes.Clone(s.wals[string(rowKey)]), nil
+}
+
+// DeleteWAL 在当前日志与 expected 相同时删除日志
+func (s *MemoryStore) DeleteWAL(ctx context.Context, rowKey, expected []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.wals[string(rowKey)]
+	if !ok || !bytes.Equal(current, expected) {
+		return false, nil
+	}
+	delete(s.wals, string(rowKey))
+	return true, nil
+}
+
+// Close 对内存存储无操作
+func (s *MemoryStore) Close() {}
+
+// row 返回 key 行中未过期且满足 versions 的版本，没有满足条件的版本时返回 nil，调用方需持有锁
+func (s *MemoryStore) row(key string, keysOnly bool, versions Versions) *Row {
+	min, max, timed := versions.timeRange()
+	now := time.Now().UnixMilli()
+	var cells []Cell
+	for _, cell := range s.rows[key] {
+		if cell.expired(now) || timed && (cell.Timestamp < min || cell.Timestamp >= max) {
+			continue
+		}
+		cells = append(cells, Cell{Timestamp: cell.Timestamp, Value: bytes.Clone(cell.Value)})
+		if len(cells) == versions.maxVersions() {
+			break
+		}
+	}
+	switch {
+	case len(cells) == 0:
+		return nil
+	case keysOnly:
+		return &Row{Key: []byte(key)}
+	}
+	return newRow([]byte(key), cells)
+}
+
+// memoryScanner 逐行返回快照中的数据
+type memoryScanner struct {
+	rows []*Row
+}
+
+func (s *memoryScanner) Next() (*Row, error) {
+	if len(s.rows) == 0 {
+		return nil, io.EOF
+	}
+	row := s.rows[0]
+	s.rows = s.rows[1:]
+	return row, nil
+}
+
+func (s *memoryScanner) Close() error {
+	s.rows = nil
+	return nil
+}
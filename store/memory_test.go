@@ -0,0 +1,299 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	pb "go-hbase-demo/cloudpb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var testOptions = Options{
+	Table:     "t",
+	Family:    "cf",
+	Qualifier: "value",
+	RowKey: func(key *pb.SeqKey) []byte {
+		return []byte(fmt.Sprintf("%s_%03d", key.BizId, key.Seq))
+	},
+}
+
+func newTestItem(bizID string, seq int32) *pb.SeqItem {
+	return &pb.SeqItem{
+		Key:   &pb.SeqKey{BizId: []byte(bizID), Seq: seq},
+		Value: []byte(fmt.Sprintf("%s-%d", bizID, seq)),
+	}
+}
+
+// scanRowKeys 扫描 rng 并返回所有 rowkey
+func scanRowKeys(t *testing.T, s SeqStore, rng Range) []string {
+	t.Helper()
+	scanner, err := s.Scan(context.Background(), rng)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	defer scanner.Close()
+	var keys []string
+	for {
+		row, err := scanner.Next()
+		if err == io.EOF {
+			return keys
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		keys = append(keys, string(row.Key))
+	}
+}
+
+func TestMemoryStore_PutGet(t *testing.T) {
+	s := NewMemoryStore(testOptions)
+	ctx := context.Background()
+	item := newTestItem("biz1", 1)
+	if _, err := s.Put(ctx, []*pb.SeqItem{item, newTestItem("biz1", 2)}, 0); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	got, err := s.Get(ctx, []*pb.SeqKey{item.Key, {BizId: []byte("biz1"), Seq: 3}})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !proto.Equal(got[0], item) {
+		t.Errorf("Get()[0] = %v, want %v", got[0], item)
+	}
+	if got[1] != nil {
+		t.Errorf("Get()[1] = %v, want nil", got[1])
+	}
+
+	// 覆盖写入
+	updated := newTestItem("biz1", 1)
+	updated.Value = []byte("updated")
+	if _, err := s.Put(ctx, []*pb.SeqItem{updated}, 0); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, _ = s.Get(ctx, []*pb.SeqKey{item.Key})
+	if !proto.Equal(got[0], updated) {
+		t.Errorf("Get() after overwrite = %v, want %v", got[0], updated)
+	}
+}
+
+func TestMemoryStore_Scan(t *testing.T) {
+	s := NewMemoryStore(testOptions)
+	ctx := context.Background()
+	for _, seq := range []int32{3, 1, 5, 2, 4} {
+		if _, err := s.Put(ctx, []*pb.SeqItem{newTestItem("biz1", seq)}, 0); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		rng  Range
+		want []string
+	}{
+		{
+			name: "forward half-open",
+			rng:  Range{StartRow: []byte("biz1_002"), StopRow: []byte("biz1_004")},
+			want: []string{"biz1_002", "biz1_003"},
+		},
+		{
+			name: "forward unbounded stop",
+			rng:  Range{StartRow: []byte("biz1_004")},
+			want: []string{"biz1_004", "biz1_005"},
+		},
+		{
+			name: "reverse from start inclusive to stop exclusive",
+			rng:  Range{StartRow: []byte("biz1_004"), StopRow: []byte("biz1_001"), Reverse: true},
+			want: []string{"biz1_004", "biz1_003", "biz1_002"},
+		},
+		{
+			name: "reverse start between rows",
+			rng:  Range{StartRow: []byte("biz1_0035"), StopRow: []byte("biz1_001"), Reverse: true},
+			want: []string{"biz1_003", "biz1_002"},
+		},
+		{
+			name: "forward limit",
+			rng:  Range{StartRow: []byte("biz1_002"), Limit: 2},
+			want: []string{"biz1_002", "biz1_003"},
+		},
+		{
+			name: "reverse limit",
+			rng:  Range{Reverse: true, Limit: 1},
+			want: []string{"biz1_005"},
+		},
+		{
+			name: "reverse unbounded",
+			rng:  Range{Reverse: true},
+			want: []string{"biz1_005", "biz1_004", "biz1_003", "biz1_002", "biz1_001"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scanRowKeys(t, s, tt.rng); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStore_Delete(t *testing.T) {
+	s := NewMemoryStore(testOptions)
+	ctx := context.Background()
+	for seq := int32(1); seq <= 3; seq++ {
+		if _, err := s.Put(ctx, []*pb.SeqItem{newTestItem("biz1", seq)}, 0); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	if err := s.Delete(ctx, [][]byte{[]byte("biz1_002"), []byte("missing")}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	want := []string{"biz1_001", "biz1_003"}
+	if got := scanRowKeys(t, s, Range{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan() after Delete = %v, want %v", got, want)
+	}
+}
+
+func TestMemoryStore_Counter(t *testing.T) {
+	s := NewMemoryStore(testOptions)
+	ctx := context.Background()
+	if ok, err := s.InitCounter(ctx, []byte("c"), 10); err != nil || !ok {
+		t.Fatalf("InitCounter() = %v, %v, want true", ok, err)
+	}
+	if ok, err := s.InitCounter(ctx, []byte("c"), 20); err != nil || ok {
+		t.Fatalf("InitCounter() on existing counter = %v, %v, want false", ok, err)
+	}
+	if got, err := s.Increment(ctx, []byte("c"), 5); err != nil || got != 15 {
+		t.Errorf("Increment() = %v, %v, want 15", got, err)
+	}
+	if got, err := s.Increment(ctx, []byte("other"), 1); err != nil || got != 1 {
+		t.Errorf("Increment() on absent counter = %v, %v, want 1", got, err)
+	}
+	if keys := scanRowKeys(t, s, Range{}); len(keys) != 0 {
+		t.Errorf("Scan() = %v, want counters excluded", keys)
+	}
+}
+
+func TestMemoryStore_CheckAndPut(t *testing.T) {
+	s := NewMemoryStore(testOptions)
+	ctx := context.Background()
+	item := newTestItem("biz1", 1)
+	first, err := s.CheckAndPut(ctx, item, nil, 0)
+	if err != nil || first == 0 {
+		t.Fatalf("CheckAndPut() on absent key = %v, %v, want written", first, err)
+	}
+	if ts, err := s.CheckAndPut(ctx, item, nil, 0); err != nil || ts != 0 {
+		t.Fatalf("CheckAndPut() expecting absent on existing key = %v, %v, want not written", ts, err)
+	}
+	updated := &pb.SeqItem{Key: item.Key, Value: []byte("updated")}
+	if ts, err := s.CheckAndPut(ctx, updated, updated, 0); err != nil || ts != 0 {
+		t.Fatalf("CheckAndPut() with wrong expected = %v, %v, want not written", ts, err)
+	}
+	if ts, err := s.CheckAndPut(ctx, updated, item, 0); err != nil || ts <= first {
+		t.Fatalf("CheckAndPut() with current value = %v, %v, want written after %d", ts, err, first)
+	}
+	got, _ := s.Get(ctx, []*pb.SeqKey{item.Key})
+	if !proto.Equal(got[0], updated) {
+		t.Errorf("Get() = %v, want %v", got[0], updated)
+	}
+	if keys := scanRowKeys(t, s, Range{}); len(keys) != 1 {
+		t.Errorf("Scan() = %v, want one row", keys)
+	}
+}
+
+func TestMemoryStore_WAL(t *testing.T) {
+	s := NewMemoryStore(testOptions)
+	ctx := context.Background()
+	row := []byte("h_biz_")
+	if ok, err := s.PutWAL(ctx, row, nil, []byte("a")); err != nil || !ok {
+		t.Fatalf("PutWAL() on absent log = %v, %v, want true", ok, err)
+	}
+	if ok, err := s.PutWAL(ctx, row, nil, []byte("b")); err != nil || ok {
+		t.Fatalf("PutWAL() expecting absent on existing log = %v, %v, want false", ok, err)
+	}
+	if ok, err := s.PutWAL(ctx, row, []byte("a"), []byte("b")); err != nil || !ok {
+		t.Fatalf("PutWAL() with current log = %v, %v, want true", ok, err)
+	}
+	if got, _ := s.GetWAL(ctx, row); string(got) != "b" {
+		t.Errorf("GetWAL() = %q, want %q", got, "b")
+	}
+	if ok, err := s.DeleteWAL(ctx, row, []byte("a")); err != nil || ok {
+		t.Fatalf("DeleteWAL() with stale log = %v, %v, want false", ok, err)
+	}
+	if ok, err := s.DeleteWAL(ctx, row, []byte("b")); err != nil || !ok {
+		t.Fatalf("DeleteWAL() with current log = %v, %v, want true", ok, err)
+	}
+	if got, _ := s.GetWAL(ctx, row); got != nil {
+		t.Errorf("GetWAL() after DeleteWAL = %q, want nil", got)
+	}
+	if keys := scanRowKeys(t, s, Range{}); len(keys) != 0 {
+		t.Errorf("Scan() = %v, want no rows", keys)
+	}
+}
+
+func TestMemoryStore_Versions(t *testing.T) {
+	s := NewMemoryStore(testOptions)
+	ctx := context.Background()
+	var timestamps []int64
+	for i := 0; i < 3; i++ {
+		ts, err := s.Put(ctx, []*pb.SeqItem{{Key: &pb.SeqKey{BizId: []byte("biz1"), Seq: 1}, Value: []byte{byte(i)}}}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		timestamps = append(timestamps, ts[0])
+	}
+	rowKey := testOptions.RowKey(&pb.SeqKey{BizId: []byte("biz1"), Seq: 1})
+
+	row, err := s.GetRow(ctx, rowKey, Versions{MaxVersions: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(row.Versions) != 2 || row.Timestamp != timestamps[2] || row.Versions[1].Timestamp != timestamps[1] {
+		t.Errorf("GetRow() = %+v, want the 2 newest of %v", row, timestamps)
+	}
+	row, _ = s.GetRow(ctx, rowKey, Versions{MaxTimestamp: timestamps[1], MaxVersions: 5})
+	if len(row.Versions) != 1 || row.Timestamp != timestamps[0] {
+		t.Errorf("GetRow() before %d = %+v, want only %d", timestamps[1], row, timestamps[0])
+	}
+	if row, _ := s.GetRow(ctx, rowKey, Versions{MaxTimestamp: timestamps[0]}); row != nil {
+		t.Errorf("GetRow() before first version = %+v, want nil", row)
+	}
+	if keys := scanRowKeys(t, s, Range{Versions: Versions{MinTimestamp: timestamps[2] + 1}}); len(keys) != 0 {
+		t.Errorf("Scan() after last version = %v, want no rows", keys)
+	}
+	got, _ := s.Get(ctx, []*pb.SeqKey{{BizId: []byte("biz1"), Seq: 1}})
+	if !bytes.Equal(got[0].Value, []byte{2}) {
+		t.Errorf("Get() = %v, want the newest version", got[0])
+	}
+}
+
+func TestMemoryStore_TTL(t *testing.T) {
+	s := NewMemoryStore(testOptions)
+	ctx := context.Background()
+	key := &pb.SeqKey{BizId: []byte("biz1"), Seq: 1}
+	if _, err := s.Put(ctx, []*pb.SeqItem{newTestItem("biz1", 1)}, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	expiring := newTestItem("biz1", 1)
+	expiring.Value = []byte("expiring")
+	if _, err := s.Put(ctx, []*pb.SeqItem{expiring, newTestItem("biz1", 2)}, 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Get(ctx, []*pb.SeqKey{key}); !proto.Equal(got[0], expiring) {
+		t.Errorf("Get() before expiry = %v, want %v", got[0], expiring)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	// 过期的版本不再可见，更早的未过期版本重新成为最新版本
+	if got, _ := s.Get(ctx, []*pb.SeqKey{key}); !proto.Equal(got[0], newTestItem("biz1", 1)) {
+		t.Errorf("Get() after expiry = %v, want the version without ttl", got[0])
+	}
+	if keys := scanRowKeys(t, s, Range{}); !reflect.DeepEqual(keys, []string{"biz1_001"}) {
+		t.Errorf("Scan() after expiry = %v, want only biz1_001", keys)
+	}
+}
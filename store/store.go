@@ -0,0 +1,214 @@
+// Package store 定义 SeqDb 服务背后的存储后端抽象及其实现：
+// gohbase 原生 RPC、HBase Thrift2 接口以及不依赖任何集群的内存实现
+package store
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync/atomic"
+	"time"
+
+	pb "go-hbase-demo/cloudpb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// SeqStore 是 SeqDb 服务使用的存储后端
+// 单点读写以 SeqKey 为单位，由 Options.RowKey 映射为 rowkey；范围扫描与删除直接作用于 rowkey
+type SeqStore interface {
+	// Put 写入 items，已存在的 key 会被覆盖，返回与 items 一一对应的 HBase 时间戳（Unix 毫秒）
+	// 时间戳由 putTimestamp 在写入前指定，写入失败的 item 为 0
+	// ttl 大于 0 时写入的 cell 在 ttl 后过期；部分 item 写入失败时返回 *PutError
+	Put(ctx context.Context, items []*pb.SeqItem, ttl time.Duration) ([]int64, error)
+	// Get 读取 keys 对应的 SeqItem，结果与 keys 一一对应，不存在的 key 对应 nil
+	Get(ctx context.Context, keys []*pb.SeqKey) ([]*pb.SeqItem, error)
+	// GetRow 读取 rowKey 行中满足 versions 的版本，行不存在或没有满足条件的版本时返回 nil
+	GetRow(ctx context.Context, rowKey []byte, versions Versions) (*Row, error)
+	// Scan 按 rowkey 顺序扫描 rng 内的行
+	Scan(ctx context.Context, rng Range) (Scanner, error)
+	// CheckAndPut 仅当 item.Key 当前存储的 SeqItem 等于 expected 时写入 item，expected 为 nil 表示 key 不存在
+	// 比较基于序列化后的字节，返回写入的时间戳（与 Put 相同由 putTimestamp 指定），未写入时为 0；ttl 与 Put 相同
+	CheckAndPut(ctx context.Context, item, expected *pb.SeqItem, ttl time.Duration) (int64, error)
+	// Delete 删除指定 rowkey 的整行
+	Delete(ctx context.Context, rowKeys [][]byte) error
+	// Increment 原子地为 rowKey 行的计数器加上 delta 并返回新值，计数器不存在时视为 0
+	Increment(ctx context.Context, rowKey []byte, delta int64) (int64, error)
+	// InitCounter 仅在计数器不存在时将其设为 value，返回是否写入
+	InitCounter(ctx context.Context, rowKey []byte, value int64) (bool, error)
+	// PutWAL 仅当 rowKey 行的日志列等于 expected 时写入 record，expected 为 nil 表示日志不存在，返回是否写入
+	PutWAL(ctx context.Context, rowKey, expected, record []byte) (bool, error)
+	// GetWAL 读取 rowKey 行的日志列，日志不存在时返回 nil
+	GetWAL(ctx context.Context, rowKey []byte) ([]byte, error)
+	// DeleteWAL 仅当 rowKey 行的日志列等于 expected 时删除日志，返回是否删除；同一行的其他列不受影响
+	DeleteWAL(ctx context.Context, rowKey, expected []byte) (bool, error)
+	// Close 释放后端连接
+	Close()
+}
+
+// Range 描述一次 rowkey 区间扫描，语义与 hrpc.NewScanRange 一致：
+// 正序扫描 [StartRow, StopRow)；倒序时从 StartRow（含）向下扫描到 StopRow（不含）
+// StopRow 为空表示不设上界（倒序时为不设下界）
+type Range struct {
+	StartRow []byte
+	StopRow  []byte
+	Reverse  bool
+	Limit    int      // 最多返回的行数，0 表示不限制，后端据此限制每次 RPC 拉取的行数
+	KeysOnly bool     // 只需要 rowkey，后端可以不返回 value（Row.Value 可能为空）
+	Versions Versions // 读取的版本，没有满足条件的版本的行不会返回
+}
+
+// Versions 限定读取的版本，零值表示只读取最新版本
+type Versions struct {
+	MinTimestamp int64 // 只读取时间戳不小于 MinTimestamp 的版本，Unix 毫秒
+	MaxTimestamp int64 // 只读取时间戳小于 MaxTimestamp 的版本，0 表示不限制
+	MaxVersions  int   // 每行最多读取的版本数，0 表示 1
+}
+
+// timeRange 返回 HBase TimeRange 的 [min, max)，未限制时间时 ok 为 false
+func (v Versions) timeRange() (min, max int64, ok bool) {
+	if v.MinTimestamp == 0 && v.MaxTimestamp == 0 {
+		return 0, 0, false
+	}
+	max = v.MaxTimestamp
+	if max == 0 {
+		max = math.MaxInt64
+	}
+	return v.MinTimestamp, max, true
+}
+
+// maxVersions 返回每行最多读取的版本数
+func (v Versions) maxVersions() int {
+	return max(v.MaxVersions, 1)
+}
+
+// Row 是扫描得到的一行数据，Value 为 Options 指定列中的原始字节
+type Row struct {
+	Key       []byte
+	Value     []byte
+	Timestamp int64  // Value 的 HBase 时间戳，Unix 毫秒
+	Versions  []Cell // 读取到的所有版本，按时间戳从新到旧，第一个即 Value；KeysOnly 时不可用
+}
+
+// Cell 是 SeqItem 列的一个版本
+type Cell struct {
+	Timestamp int64
+	Value     []byte
+}
+
+// newRow 由按时间戳从新到旧排列的版本生成 Row，versions 不能为空
+func newRow(key []byte, versions []Cell) *Row {
+	return &Row{Key: key, Value: versions[0].Value, Timestamp: versions[0].Timestamp, Versions: versions}
+}
+
+// Scanner 逐行返回扫描结果，扫描结束时 Next 返回 io.EOF
+type Scanner interface {
+	Next() (*Row, error)
+	Close() error
+}
+
+// limitScanner 在返回 remaining 行后结束扫描
+type limitScanner struct {
+	Scanner
+	remaining int
+}
+
+// limitRows 将 scanner 的结果限制为 limit 行，limit 为 0 时原样返回
+func limitRows(scanner Scanner, limit int) Scanner {
+	if limit <= 0 {
+		return scanner
+	}
+	return &limitScanner{Scanner: scanner, remaining: limit}
+}
+
+func (s *limitScanner) Next() (*Row, error) {
+	if s.remaining <= 0 {
+		return nil, io.EOF
+	}
+	row, err := s.Scanner.Next()
+	if err == nil {
+		s.remaining--
+	}
+	return row, err
+}
+
+// Options 描述 SeqItem 在表中的存储布局
+type Options struct {
+	Table     string                  // 表名
+	Family    string                  // 列族
+	Qualifier string                  // 存放序列化 SeqItem 的列名
+	RowKey    func(*pb.SeqKey) []byte // SeqKey 到 rowkey 的映射
+	Counter   string                  // 计数器的列名，与 Qualifier 同在 Family 列族下
+	WAL       string                  // 原子 Put 预写日志的列名，与 Qualifier 同在 Family 列族下
+}
+
+// PutError 是 Put 部分失败时返回的错误，Errs 与 items 一一对应，写入成功的 item 对应 nil
+type PutError struct {
+	Errs []error
+}
+
+// newPutError 在 errs 中有非空错误时返回 *PutError，否则返回 nil
+func newPutError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return &PutError{Errs: errs}
+		}
+	}
+	return nil
+}
+
+func (e *PutError) Error() string {
+	var (
+		failed int
+		first  error
+	)
+	for _, err := range e.Errs {
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			failed++
+		}
+	}
+	return fmt.Sprintf("failed to put %d of %d items, first error: %v", failed, len(e.Errs), first)
+}
+
+// lastPutTimestamp 是本进程最近一次分配的写入时间戳
+var lastPutTimestamp atomic.Int64
+
+// putTimestamp 返回本次写入使用的时间戳，由客户端指定以便在写入结果中返回而无需读回
+// 时间戳为当前毫秒时间且在本进程内严格递增，同一毫秒内对同一行的多次写入不会合并为一个版本；
+// 多个实例之间的顺序取决于各自的时钟
+func putTimestamp() int64 {
+	for {
+		last := lastPutTimestamp.Load()
+		ts := max(time.Now().UnixMilli(), last+1)
+		if lastPutTimestamp.CompareAndSwap(last, ts) {
+			return ts
+		}
+	}
+}
+
+// marshalItem 序列化要写入的 SeqItem，只在读取时返回的 timestamp 和 versions 不写入存储
+func marshalItem(item *pb.SeqItem) ([]byte, error) {
+	if item.Timestamp != 0 || len(item.Versions) > 0 {
+		item = proto.Clone(item).(*pb.SeqItem)
+		item.Timestamp, item.Versions = 0, nil
+	}
+	return proto.Marshal(item)
+}
+
+// marshalExpected 序列化 CheckAndPut 的期望值，nil 表示 key 不存在
+func marshalExpected(expected *pb.SeqItem) ([]byte, error) {
+	if expected == nil {
+		return nil, nil
+	}
+	return marshalItem(expected)
+}
+
+// encodeCounter 按 HBase Increment 的格式将计数器编码为 8 字节大端整数
+func encodeCounter(value int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(value))
+}
@@ -0,0 +1,350 @@
+package store
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	pb "go-hbase-demo/cloudpb"
+
+	"demo/gen-go/hbase" // Thrift 生成的 HBase 客户端代码
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"google.golang.org/protobuf/proto"
+)
+
+// thriftScanBatch 是每次 GetScannerRows 拉取的行数
+const thriftScanBatch = 100
+
+// ThriftStore 通过 HBase Thrift2 接口（如 Lindorm 的 Thrift 代理）访问数据
+type ThriftStore struct {
+	client hbase.THBaseService
+	trans  thrift.TTransport // 由 DialThriftStore 创建时持有，Close 时关闭
+	opts   Options
+}
+
+// NewThriftStore 基于已创建的 Thrift 客户端创建存储后端
+func NewThriftStore(client hbase.THBaseService, opts Options) *ThriftStore {
+	return &ThriftStore{client: client, opts: opts}
+}
+
+// DialThriftStore 以 HTTP 方式连接 Thrift 服务并创建存储后端
+// user 和 password 通过 ACCESSKEYID / ACCESSSIGNATURE 请求头传递
+func DialThriftStore(host, user, password string, opts Options) (*ThriftStore, error) {
+	client, trans, err := DialThrift(host, user, password)
+	if err != nil {
+		return nil, err
+	}
+	return &ThriftStore{client: client, trans: trans, opts: opts}, nil
+}
+
+// DialThrift 以 HTTP 方式连接 Thrift 服务，返回的连接由调用方关闭
+func DialThrift(host, user, password string) (*hbase.THBaseServiceClient, thrift.TTransport, error) {
+	if host == "" {
+		return nil, nil, fmt.Errorf("thrift host is not set")
+	}
+	protocolFactory := thrift.NewTBinaryProtocolFactoryDefault()
+	trans, err := thrift.NewTHttpClient(host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error resolving address: %v", err)
+	}
+
+	// 设置用户名和密码
+	httpClient := trans.(*thrift.THttpClient)
+	httpClient.SetHeader("ACCESSKEYID", user)
+	httpClient.SetHeader("ACCESSSIGNATURE", password)
+
+	client := hbase.NewTHBaseServiceClientFactory(trans, protocolFactory)
+	if err := trans.Open(); err != nil {
+		return nil, nil, fmt.Errorf("error opening %s: %v", host, err)
+	}
+	return client, trans, nil
+}
+
+// WithTable 返回访问 table 的存储后端，与 s 共用 Thrift 连接，连接由 s 关闭
+func (s *ThriftStore) WithTable(table string) *ThriftStore {
+	opts := s.opts
+	opts.Table = table
+	return &ThriftStore{client: s.client, opts: opts}
+}
+
+// Put 通过 PutMultiple 一次写入所有 items，PutMultiple 不返回单个 item 的结果，失败时视为全部失败
+// 每个 item 以 putTimestamp 指定的时间戳写入
+func (s *ThriftStore) Put(ctx context.Context, items []*pb.SeqItem, ttl time.Duration) ([]int64, error) {
+	puts := make([]*hbase.TPut, 0, len(items))
+	timestamps := make([]int64, len(items))
+	for i, item := range items {
+		// 序列化 SeqItem
+		data, err := marshalItem(item)
+		if err != nil {
+			return nil, err
+		}
+		timestamps[i] = putTimestamp()
+		puts = append(puts, &hbase.TPut{
+			Row:        s.opts.RowKey(item.Key),
+			Timestamp:  &timestamps[i],
+			Attributes: ttlAttributes(ttl),
+			ColumnValues: []*hbase.TColumnValue{
+				{
+					Family:    []byte(s.opts.Family),
+					Qualifier: []byte(s.opts.Qualifier),
+					Value:     data,
+				},
+			},
+		})
+	}
+	if err := s.client.PutMultiple(ctx, []byte(s.opts.Table), puts); err != nil {
+		return nil, err
+	}
+	return timestamps, nil
+}
+
+// Get 通过 GetMultiple 一次读取所有 keys
+func (s *ThriftStore) Get(ctx context.Context, keys []*pb.SeqKey) ([]*pb.SeqItem, error) {
+	gets := make([]*hbase.TGet, 0, len(keys))
+	for _, key := range keys {
+		gets = append(gets, &hbase.TGet{Row: s.opts.RowKey(key), Columns: s.columns()})
+	}
+	results, err := s.client.GetMultiple(ctx, []byte(s.opts.Table), gets)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*pb.SeqItem, len(keys))
+	for i, result := range results {
+		if i >= len(items) || result == nil || len(result.ColumnValues) == 0 {
+			continue
+		}
+		item := &pb.SeqItem{}
+		if err := proto.Unmarshal(result.ColumnValues[0].Value, item); err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// GetRow 读取一行中满足 versions 的版本
+func (s *ThriftStore) GetRow(ctx context.Context, rowKey []byte, versions Versions) (*Row, error) {
+	get := &hbase.TGet{Row: rowKey, Columns: s.columns(), TimeRange: thriftTimeRange(versions)}
+	if n := int32(versions.maxVersions()); n > 1 {
+		get.MaxVersions = &n
+	}
+	result, err := s.client.Get(ctx, []byte(s.opts.Table), get)
+	if err != nil || result == nil || len(result.ColumnValues) == 0 {
+		return nil, err
+	}
+	row := thriftRow(result)
+	row.Key = rowKey
+	return row, nil
+}
+
+// CheckAndPut 通过 Thrift CheckAndPut 原子地比较并写入
+func (s *ThriftStore) CheckAndPut(ctx context.Context, item, expected *pb.SeqItem, ttl time.Duration) (int64, error) {
+	data, err := marshalItem(item)
+	if err != nil {
+		return 0, err
+	}
+	expectedData, err := marshalExpected(expected)
+	if err != nil {
+		return 0, err
+	}
+	rowKey := s.opts.RowKey(item.Key)
+	ts := putTimestamp()
+	applied, err := s.client.CheckAndPut(ctx, []byte(s.opts.Table), rowKey, []byte(s.opts.Family), []byte(s.opts.Qualifier), expectedData, &hbase.TPut{
+		Row:        rowKey,
+		Timestamp:  &ts,
+		Attributes: ttlAttributes(ttl),
+		ColumnValues: []*hbase.TColumnValue{
+			{Family: []byte(s.opts.Family), Qualifier: []byte(s.opts.Qualifier), Value: data},
+		},
+	})
+	if err != nil || !applied {
+		return 0, err
+	}
+	return ts, nil
+}
+
+// Scan 打开一个服务端 scanner，按批拉取结果
+func (s *ThriftStore) Scan(ctx context.Context, rng Range) (Scanner, error) {
+	scan := &hbase.TScan{
+		StartRow: rng.StartRow,
+		StopRow:  rng.StopRow,
+		Columns:  s.columns(),
+		// TScan.MaxVersions 不是可选字段，零值会被服务端当作 0 个版本
+		MaxVersions: int32(rng.Versions.maxVersions()),
+		TimeRange:   thriftTimeRange(rng.Versions),
+	}
+	if rng.Reverse {
+		reversed := true
+		scan.Reversed = &reversed
+	}
+	if rng.KeysOnly {
+		scan.FilterString = []byte("KeyOnlyFilter()")
+	}
+	batch := int32(thriftScanBatch)
+	if rng.Limit > 0 {
+		limit := int32(rng.Limit)
+		scan.Limit = &limit
+		batch = min(batch, limit)
+	}
+	scannerID, err := s.client.OpenScanner(ctx, []byte(s.opts.Table), scan)
+	if err != nil {
+		return nil, err
+	}
+	return limitRows(&thriftScanner{ctx: ctx, client: s.client, id: scannerID, batch: batch}, rng.Limit), nil
+}
+
+// Delete 通过 DeleteMultiple 一次删除所有行
+func (s *ThriftStore) Delete(ctx context.Context, rowKeys [][]byte) error {
+	deletes := make([]*hbase.TDelete, 0, len(rowKeys))
+	for _, rowKey := range rowKeys {
+		del := hbase.NewTDelete()
+		del.Row = rowKey
+		deletes = append(deletes, del)
+	}
+	failed, err := s.client.DeleteMultiple(ctx, []byte(s.opts.Table), deletes)
+	if err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to delete %d rows, first row: %q", len(failed), failed[0].Row)
+	}
+	return nil
+}
+
+// Increment 通过 Thrift Increment 原子地更新计数器
+func (s *ThriftStore) Increment(ctx context.Context, rowKey []byte, delta int64) (int64, error) {
+	result, err := s.client.Increment(ctx, []byte(s.opts.Table), &hbase.TIncrement{
+		Row: rowKey,
+		Columns: []*hbase.TColumnIncrement{
+			{Family: []byte(s.opts.Family), Qualifier: []byte(s.opts.Counter), Amount: delta},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if result == nil || len(result.ColumnValues) == 0 || len(result.ColumnValues[0].Value) != 8 {
+		return 0, fmt.Errorf("unexpected increment result for row %q", rowKey)
+	}
+	return int64(binary.BigEndian.Uint64(result.ColumnValues[0].Value)), nil
+}
+
+// InitCounter 通过 CheckAndPut 在计数器列不存在时写入初始值
+func (s *ThriftStore) InitCounter(ctx context.Context, rowKey []byte, value int64) (bool, error) {
+	return s.client.CheckAndPut(ctx, []byte(s.opts.Table), rowKey, []byte(s.opts.Family), []byte(s.opts.Counter), nil, &hbase.TPut{
+		Row: rowKey,
+		ColumnValues: []*hbase.TColumnValue{
+			{Family: []byte(s.opts.Family), Qualifier: []byte(s.opts.Counter), Value: encodeCounter(value)},
+		},
+	})
+}
+
+// PutWAL 通过 Thrift CheckAndPut 比较并写入日志列
+func (s *ThriftStore) PutWAL(ctx context.Context, rowKey, expected, record []byte) (bool, error) {
+	return s.client.CheckAndPut(ctx, []byte(s.opts.Table), rowKey, []byte(s.opts.Family), []byte(s.opts.WAL), expected, &hbase.TPut{
+		Row: rowKey,
+		ColumnValues: []*hbase.TColumnValue{
+			{Family: []byte(s.opts.Family), Qualifier: []byte(s.opts.WAL), Value: record},
+		},
+	})
+}
+
+// GetWAL 只读取日志列
+func (s *ThriftStore) GetWAL(ctx context.Context, rowKey []byte) ([]byte, error) {
+	result, err := s.client.Get(ctx, []byte(s.opts.Table), &hbase.TGet{Row: rowKey, Columns: s.walColumns()})
+	if err != nil || result == nil || len(result.ColumnValues) == 0 {
+		return nil, err
+	}
+	return result.ColumnValues[0].Value, nil
+}
+
+// DeleteWAL 通过 Thrift CheckAndDelete 比较并删除日志列的所有版本
+func (s *ThriftStore) DeleteWAL(ctx context.Context, rowKey, expected []byte) (bool, error) {
+	del := hbase.NewTDelete()
+	del.Row = rowKey
+	del.Columns = s.walColumns()
+	return s.client.CheckAndDelete(ctx, []byte(s.opts.Table), rowKey, []byte(s.opts.Family), []byte(s.opts.WAL), expected, del)
+}
+
+// Close 关闭由 DialThriftStore 打开的连接
+func (s *ThriftStore) Close() {
+	if s.trans != nil {
+		s.trans.Close()
+	}
+}
+
+// columns 将读取限定在 Options 指定的列上
+func (s *ThriftStore) columns() []*hbase.TColumn {
+	return []*hbase.TColumn{{Family: []byte(s.opts.Family), Qualifier: []byte(s.opts.Qualifier)}}
+}
+
+// ttlAttributes 生成设置 cell TTL 的 Mutation 属性，与 HBase Java 客户端的 Mutation.setTTL 相同：
+// 属性名为 _ttl，值为毫秒数的 8 字节大端编码
+func ttlAttributes(ttl time.Duration) map[string][]byte {
+	if ttl <= 0 {
+		return nil
+	}
+	return map[string][]byte{"_ttl": binary.BigEndian.AppendUint64(nil, uint64(ttl.Milliseconds()))}
+}
+
+// thriftTimeRange 将 versions 的时间区间转换为 TTimeRange，未限制时间时返回 nil
+func thriftTimeRange(versions Versions) *hbase.TTimeRange {
+	min, max, ok := versions.timeRange()
+	if !ok {
+		return nil
+	}
+	return &hbase.TTimeRange{MinStamp: min, MaxStamp: max}
+}
+
+// thriftRow 将 TResult_ 中同一列的多个版本转换为 Row，result 至少包含一个版本
+func thriftRow(result *hbase.TResult_) *Row {
+	versions := make([]Cell, len(result.ColumnValues))
+	for i, cv := range result.ColumnValues {
+		versions[i] = Cell{Timestamp: cv.GetTimestamp(), Value: cv.Value}
+	}
+	return newRow(result.Row, versions)
+}
+
+// walColumns 将读取和删除限定在日志列上
+func (s *ThriftStore) walColumns() []*hbase.TColumn {
+	return []*hbase.TColumn{{Family: []byte(s.opts.Family), Qualifier: []byte(s.opts.WAL)}}
+}
+
+// thriftScanner 对服务端 scanner 按批拉取并逐行返回
+type thriftScanner struct {
+	ctx     context.Context
+	client  hbase.THBaseService
+	id      int32
+	batch   int32 // 每次 GetScannerRows 拉取的行数
+	results []*hbase.TResult_
+	done    bool
+}
+
+func (s *thriftScanner) Next() (*Row, error) {
+	for len(s.results) == 0 {
+		if s.done {
+			return nil, io.EOF
+		}
+		results, err := s.client.GetScannerRows(s.ctx, s.id, s.batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(results) < int(s.batch) {
+			s.done = true
+		}
+		s.results = results
+	}
+	result := s.results[0]
+	s.results = s.results[1:]
+	if len(result.ColumnValues) == 0 {
+		return s.Next()
+	}
+	return thriftRow(result), nil
+}
+
+func (s *thriftScanner) Close() error {
+	return s.client.CloseScanner(s.ctx, s.id)
+}
@@ -0,0 +1,219 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/store"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// provisionTimeout 是为一个租户建表的最长时间
+var provisionTimeout = time.Minute
+
+// Provisioner 在表不存在时创建表（及其 namespace）
+type Provisioner interface {
+	Provision(ctx context.Context, table string) error
+}
+
+// Table 返回 namespace ns 中名为 table 的表的完整表名
+func Table(ns, table string) string {
+	return ns + ":" + table
+}
+
+// Store 按 context 中的 namespace 路由到各租户的存储后端
+// 每个 namespace 第一次被访问时，先由 Provisioner 创建表，再通过 open 打开 ns:table，之后复用同一个存储后端
+type Store struct {
+	def         store.SeqStore // 未指定 namespace 时使用的存储后端
+	table       string
+	open        func(table string) store.SeqStore
+	provisioner Provisioner // 为 nil 时不自动创建表
+
+	mu     sync.RWMutex
+	stores map[string]store.SeqStore // namespace -> 存储后端
+	group  singleflight.Group        // 按 namespace 合并并发的建表
+}
+
+// NewStore 创建路由存储，provisioner 为 nil 时表须事先创建
+func NewStore(def store.SeqStore, table string, open func(table string) store.SeqStore, provisioner Provisioner) *Store {
+	return &Store{def: def, table: table, open: open, provisioner: provisioner, stores: map[string]store.SeqStore{}}
+}
+
+// resolve 返回 ctx 中的 namespace 对应的存储后端
+func (s *Store) resolve(ctx context.Context) (store.SeqStore, error) {
+	ns := FromContext(ctx)
+	if ns == "" {
+		return s.def, nil
+	}
+	s.mu.RLock()
+	st, ok := s.stores[ns]
+	s.mu.RUnlock()
+	if ok {
+		return st, nil
+	}
+
+	// 建表是耗时的管理操作，不能持有 mu，否则会阻塞所有已缓存 namespace 的请求；
+	// 同一 namespace 的并发请求只建一次表，建表不随任何一个请求取消，请求取消时只是不再等待
+	ch := s.group.DoChan(ns, func() (any, error) {
+		s.mu.RLock()
+		st, ok := s.stores[ns]
+		s.mu.RUnlock()
+		if ok {
+			return st, nil
+		}
+		table := Table(ns, s.table)
+		if s.provisioner != nil {
+			// 创建失败时不缓存，下一次请求重试
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), provisionTimeout)
+			defer cancel()
+			if err := s.provisioner.Provision(ctx, table); err != nil {
+				return nil, fmt.Errorf("provision table %s: %v", table, err)
+			}
+		}
+		st = s.open(table)
+		s.mu.Lock()
+		s.stores[ns] = st
+		s.mu.Unlock()
+		return st, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(store.SeqStore), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Put 见 store.SeqStore
+func (s *Store) Put(ctx context.Context, items []*pb.SeqItem, ttl time.Duration) ([]int64, error) {
+	st, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return st.Put(ctx, items, ttl)
+}
+
+// Get 见 store.SeqStore
+func (s *Store) Get(ctx context.Context, keys []*pb.SeqKey) ([]*pb.SeqItem, error) {
+	st, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return st.Get(ctx, keys)
+}
+
+// GetRow 见 store.SeqStore
+func (s *Store) GetRow(ctx context.Context, rowKey []byte, versions store.Versions) (*store.Row, error) {
+	st, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return st.GetRow(ctx, rowKey, versions)
+}
+
+// Scan 见 store.SeqStore
+func (s *Store) Scan(ctx context.Context, rng store.Range) (store.Scanner, error) {
+	st, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return st.Scan(ctx, rng)
+}
+
+// CheckAndPut 见 store.SeqStore
+func (s *Store) CheckAndPut(ctx context.Context, item, expected *pb.SeqItem, ttl time.Duration) (int64, error) {
+	st, err := s.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return st.CheckAndPut(ctx, item, expected, ttl)
+}
+
+// Delete 见 store.SeqStore
+func (s *Store) Delete(ctx context.Context, rowKeys [][]byte) error {
+	st, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return st.Delete(ctx, rowKeys)
+}
+
+// Increment 见 store.SeqStore
+func (s *Store) Increment(ctx context.Context, rowKey []byte, delta int64) (int64, error) {
+	st, err := s.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return st.Increment(ctx, rowKey, delta)
+}
+
+// InitCounter 见 store.SeqStore
+func (s *Store) InitCounter(ctx context.Context, rowKey []byte, value int64) (bool, error) {
+	st, err := s.resolve(ctx)
+	if err != nil {
+		return false, err
+	}
+	return st.InitCounter(ctx, rowKey, value)
+}
+
+// PutWAL 见 store.SeqStore
+func (s *Store) PutWAL(ctx context.Context, rowKey, expected, record []byte) (bool, error) {
+	st, err := s.resolve(ctx)
+	if err != nil {
+		return false, err
+	}
+	return st.PutWAL(ctx, rowKey, expected, record)
+}
+
+// GetWAL 见 store.SeqStore
+func (s *Store) GetWAL(ctx context.Context, rowKey []byte) ([]byte, error) {
+	st, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return st.GetWAL(ctx, rowKey)
+}
+
+// DeleteWAL 见 store.SeqStore
+func (s *Store) DeleteWAL(ctx context.Context, rowKey, expected []byte) (bool, error) {
+	st, err := s.resolve(ctx)
+	if err != nil {
+		return false, err
+	}
+	return st.DeleteWAL(ctx, rowKey, expected)
+}
+
+// Tables 返回默认存储后端和已打开的各租户存储后端，租户按 namespace 排序（见 retention.Tables）
+// 只包含本进程启动后访问过的 namespace
+func (s *Store) Tables() []store.SeqStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	namespaces := make([]string, 0, len(s.stores))
+	for ns := range s.stores {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	tables := []store.SeqStore{s.def}
+	for _, ns := range namespaces {
+		tables = append(tables, s.stores[ns])
+	}
+	return tables
+}
+
+// Close 关闭所有租户的存储后端和默认存储后端
+func (s *Store) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range s.stores {
+		st.Close()
+	}
+	s.def.Close()
+}
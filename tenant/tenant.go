@@ -0,0 +1,114 @@
+// Package tenant 实现 SeqDb 的多租户路由
+//
+// 租户（namespace）由请求的 gRPC metadata 中的 seqdb-namespace 指定，服务端拦截器将其放入 context，
+// Store 按 context 中的 namespace 将请求路由到 ns:表名，不同租户的数据位于不同的表中，互不可见；
+// 未指定 namespace 的请求访问默认表，与单租户部署的行为一致
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataKey 是携带 namespace 的 gRPC metadata 键
+const MetadataKey = "seqdb-namespace"
+
+// namespacePattern 是 HBase 允许的 namespace 名称
+var namespacePattern = regexp.MustCompile(`^[a-zA-Z0-9_]{1,128}$`)
+
+// Validate 检查 ns 是否可以作为租户的 namespace，HBase 的系统 namespace 不能使用
+func Validate(ns string) error {
+	if !namespacePattern.MatchString(ns) {
+		return fmt.Errorf("invalid namespace %q: must be 1-128 letters, digits or underscores", ns)
+	}
+	if ns == "hbase" || ns == "default" {
+		return fmt.Errorf("namespace %q is reserved", ns)
+	}
+	return nil
+}
+
+type contextKey struct{}
+
+// NewContext 返回携带 namespace 的 context
+func NewContext(ctx context.Context, ns string) context.Context {
+	return context.WithValue(ctx, contextKey{}, ns)
+}
+
+// FromContext 返回 context 中的 namespace，未指定时为空
+func FromContext(ctx context.Context) string {
+	ns, _ := ctx.Value(contextKey{}).(string)
+	return ns
+}
+
+// fromIncoming 从请求的 metadata 中读取 namespace 并放入 context
+func fromIncoming(ctx context.Context) (context.Context, error) {
+	values := metadata.ValueFromIncomingContext(ctx, MetadataKey)
+	switch len(values) {
+	case 0:
+		return ctx, nil
+	case 1:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "multiple %s values", MetadataKey)
+	}
+	if err := Validate(values[0]); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return NewContext(ctx, values[0]), nil
+}
+
+// UnaryServerInterceptor 将请求 metadata 中的 namespace 放入 context，namespace 不合法时返回 InvalidArgument
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := fromIncoming(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 是流式 RPC 的 UnaryServerInterceptor
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := fromIncoming(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// serverStream 替换 ServerStream 的 context
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
+
+// UnaryClientInterceptor 在每个请求的 metadata 中附加 namespace，ns 为空时不附加
+func UnaryClientInterceptor(ns string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoing(ctx, ns), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor 是流式 RPC 的 UnaryClientInterceptor
+func StreamClientInterceptor(ns string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoing(ctx, ns), desc, cc, method, opts...)
+	}
+}
+
+func outgoing(ctx context.Context, ns string) context.Context {
+	if ns == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, MetadataKey, ns)
+}
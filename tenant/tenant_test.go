@@ -0,0 +1,252 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/store"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		ns    string
+		valid bool
+	}{
+		{"tenant_1", true},
+		{"A", true},
+		{"", false},
+		{"a:b", false},
+		{"a-b", false},
+		{"hbase", false},
+		{"default", false},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.ns); (err == nil) != tt.valid {
+			t.Errorf("Validate(%q) error = %v, want valid %v", tt.ns, err, tt.valid)
+		}
+	}
+}
+
+// fakeProvisioner 记录创建的表，err 非空时创建失败
+type fakeProvisioner struct {
+	tables []string
+	err    error
+}
+
+func (p *fakeProvisioner) Provision(ctx context.Context, table string) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.tables = append(p.tables, table)
+	return nil
+}
+
+func newTestStore(provisioner Provisioner) (*Store, map[string]*store.MemoryStore) {
+	opened := map[string]*store.MemoryStore{}
+	open := func(table string) store.SeqStore {
+		opened[table] = store.NewMemoryStore(store.Options{Table: table, RowKey: func(key *pb.SeqKey) []byte { return key.BizId }})
+		return opened[table]
+	}
+	return NewStore(open("default_table"), "seqdb", open, provisioner), opened
+}
+
+func TestStore_Routing(t *testing.T) {
+	provisioner := &fakeProvisioner{}
+	s, opened := newTestStore(provisioner)
+	ctx := context.Background()
+	items := map[string]*pb.SeqItem{
+		"":   {Key: &pb.SeqKey{BizId: []byte("biz")}, Value: []byte("default")},
+		"t1": {Key: &pb.SeqKey{BizId: []byte("biz")}, Value: []byte("t1")},
+		"t2": {Key: &pb.SeqKey{BizId: []byte("biz")}, Value: []byte("t2")},
+	}
+	for ns, item := range items {
+		if _, err := s.Put(NewContext(ctx, ns), []*pb.SeqItem{item}, 0); err != nil {
+			t.Fatalf("Put(%q) error = %v", ns, err)
+		}
+	}
+	// 同一个 namespace 再次访问时不重复创建表
+	for ns, item := range items {
+		got, err := s.Get(NewContext(ctx, ns), []*pb.SeqKey{item.Key})
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", ns, err)
+		}
+		if !proto.Equal(got[0], item) {
+			t.Errorf("Get(%q) = %v, want %v", ns, got[0], item)
+		}
+	}
+	if len(provisioner.tables) != 2 || len(opened) != 3 {
+		t.Errorf("provisioned %v, opened %d stores, want t1:seqdb and t2:seqdb provisioned once", provisioner.tables, len(opened))
+	}
+	if _, ok := opened["t1:seqdb"]; !ok {
+		t.Error("t1:seqdb not opened")
+	}
+	// Tables 依次返回默认表和按 namespace 排序的租户表
+	want := []store.SeqStore{opened["default_table"], opened["t1:seqdb"], opened["t2:seqdb"]}
+	if got := s.Tables(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Tables() = %v, want default, t1 and t2 stores", got)
+	}
+}
+
+func TestStore_ProvisionError(t *testing.T) {
+	provisioner := &fakeProvisioner{err: errors.New("master unavailable")}
+	s, opened := newTestStore(provisioner)
+	ctx := NewContext(context.Background(), "t1")
+	if _, err := s.Get(ctx, []*pb.SeqKey{{BizId: []byte("biz")}}); err == nil {
+		t.Fatal("Get() error = nil, want provision error")
+	}
+	if _, ok := opened["t1:seqdb"]; ok {
+		t.Error("store opened after provision failure")
+	}
+
+	// 创建失败不缓存，恢复后重试成功
+	provisioner.err = nil
+	if _, err := s.Get(ctx, []*pb.SeqKey{{BizId: []byte("biz")}}); err != nil {
+		t.Fatalf("Get() after recovery error = %v", err)
+	}
+	if !reflect.DeepEqual(provisioner.tables, []string{"t1:seqdb"}) {
+		t.Errorf("provisioned %v, want [t1:seqdb]", provisioner.tables)
+	}
+}
+
+// ctxProvisioner 阻塞到 release 被关闭，通过 done 报告此时 ctx 的错误
+type ctxProvisioner struct {
+	started chan struct{}
+	release chan struct{}
+	done    chan error
+}
+
+func (p *ctxProvisioner) Provision(ctx context.Context, table string) error {
+	close(p.started)
+	<-p.release
+	p.done <- ctx.Err()
+	return ctx.Err()
+}
+
+func TestStore_ProvisionCanceled(t *testing.T) {
+	provisioner := &ctxProvisioner{started: make(chan struct{}), release: make(chan struct{}), done: make(chan error, 1)}
+	s, _ := newTestStore(provisioner)
+	keys := []*pb.SeqKey{{BizId: []byte("biz")}}
+
+	// 触发建表的请求被取消时立即返回，建表继续完成
+	ctx, cancel := context.WithCancel(NewContext(context.Background(), "t1"))
+	canceled := make(chan error, 1)
+	go func() {
+		_, err := s.Get(ctx, keys)
+		canceled <- err
+	}()
+	<-provisioner.started
+	cancel()
+	if err := <-canceled; !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled Get() error = %v, want context.Canceled", err)
+	}
+	close(provisioner.release)
+	if err := <-provisioner.done; err != nil {
+		t.Fatalf("Provision() ctx error = %v, want nil", err)
+	}
+	if _, err := s.Get(NewContext(context.Background(), "t1"), keys); err != nil {
+		t.Fatalf("Get() after provisioning error = %v", err)
+	}
+}
+
+// slowProvisioner 创建 slow 表时阻塞到 release 被关闭，其他表立即创建
+type slowProvisioner struct {
+	slow    string
+	started chan struct{}
+	release chan struct{}
+	calls   atomic.Int32 // 创建 slow 表的次数
+}
+
+func (p *slowProvisioner) Provision(ctx context.Context, table string) error {
+	if table == p.slow {
+		if p.calls.Add(1) == 1 {
+			close(p.started)
+		}
+		<-p.release
+	}
+	return nil
+}
+
+func TestStore_SlowProvision(t *testing.T) {
+	provisioner := &slowProvisioner{slow: "t2:seqdb", started: make(chan struct{}), release: make(chan struct{})}
+	s, _ := newTestStore(provisioner)
+	ctx := context.Background()
+	keys := []*pb.SeqKey{{BizId: []byte("biz")}}
+	if _, err := s.Get(NewContext(ctx, "t1"), keys); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Get(NewContext(ctx, "t2"), keys); err != nil {
+				t.Errorf("Get(t2) error = %v", err)
+			}
+		}()
+	}
+	<-provisioner.started
+
+	// t2 建表期间，已缓存的 t1 和默认表不受影响
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Get(NewContext(ctx, "t1"), keys)
+		if err == nil {
+			_, err = s.Get(ctx, keys)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("requests for a cached namespace blocked by provisioning")
+	}
+
+	close(provisioner.release)
+	wg.Wait()
+	if n := provisioner.calls.Load(); n != 1 {
+		t.Errorf("t2:seqdb provisioned %d times, want 1", n)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	handler := func(ctx context.Context, req any) (any, error) { return FromContext(ctx), nil }
+	tests := []struct {
+		name string
+		md   metadata.MD
+		want string
+		code codes.Code
+	}{
+		{"none", metadata.MD{}, "", codes.OK},
+		{"namespace", metadata.Pairs(MetadataKey, "t1"), "t1", codes.OK},
+		{"invalid", metadata.Pairs(MetadataKey, "t:1"), "", codes.InvalidArgument},
+		{"multiple", metadata.Pairs(MetadataKey, "t1", MetadataKey, "t2"), "", codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+		got, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+		if status.Code(err) != tt.code {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.code)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("%s: namespace = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+// Package validate 校验 SeqDb 的请求消息
+//
+// 每种请求消息的规则在 rules 中声明，Check 收集所有不满足的字段，返回带 errdetails.BadRequest 的
+// InvalidArgument 错误；字段路径使用 proto 字段名，如 items[2].key.biz_id
+// 服务端通过 UnaryServerInterceptor 和 StreamServerInterceptor 在调用 handler 之前校验请求
+package validate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "go-hbase-demo/cloudpb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MaxBizIDLen 是 BizId 的最大字节数
+const MaxBizIDLen = 1024
+
+// MaxBizIDs 是跨 BizId 范围查询中 biz_ids 的最大个数
+const MaxBizIDs = 1000
+
+// checker 收集字段违规
+type checker struct {
+	violations []*errdetails.BadRequest_FieldViolation
+}
+
+func (c *checker) add(field, format string, args ...any) {
+	c.violations = append(c.violations, &errdetails.BadRequest_FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+}
+
+// join 拼接字段路径
+func join(prefix, field string) string {
+	if prefix == "" {
+		return field
+	}
+	return prefix + "." + field
+}
+
+func (c *checker) bizID(field string, bizID []byte) {
+	switch {
+	case len(bizID) == 0:
+		c.add(field, "must not be empty")
+	case len(bizID) > MaxBizIDLen:
+		c.add(field, "must be at most %d bytes, got %d", MaxBizIDLen, len(bizID))
+	}
+}
+
+func (c *checker) seq(field string, seq int32) {
+	if seq < 0 {
+		c.add(field, "must not be negative, got %d", seq)
+	}
+}
+
+func (c *checker) nonNegative(field string, v int64) {
+	if v < 0 {
+		c.add(field, "must not be negative, got %d", v)
+	}
+}
+
+// key 校验必须存在的 SeqKey
+func (c *checker) key(field string, key *pb.SeqKey) {
+	if key == nil {
+		c.add(field, "is required")
+		return
+	}
+	c.bizID(join(field, "biz_id"), key.BizId)
+	c.seq(join(field, "seq"), key.Seq)
+}
+
+// item 校验要写入的 SeqItem
+func (c *checker) item(field string, item *pb.SeqItem) {
+	if item == nil {
+		c.add(field, "is required")
+		return
+	}
+	c.key(join(field, "key"), item.Key)
+}
+
+// items 校验要写入的 SeqItems，prefix 为 SeqItems 本身的路径
+func (c *checker) items(prefix string, items *pb.SeqItems) {
+	for i, item := range items.Items {
+		c.item(fmt.Sprintf("%s[%d]", join(prefix, "items"), i), item)
+	}
+	c.nonNegative(join(prefix, "ttl_seconds"), items.TtlSeconds)
+}
+
+// versions 校验 as_of_timestamp、time_range 和 max_versions
+func (c *checker) versions(asOf int64, tr *pb.TimeRange, maxVersions int32) {
+	c.nonNegative("as_of_timestamp", asOf)
+	c.nonNegative("max_versions", int64(maxVersions))
+	if tr == nil {
+		return
+	}
+	c.nonNegative("time_range.min", tr.Min)
+	c.nonNegative("time_range.max", tr.Max)
+	if tr.Max != 0 && tr.Max <= tr.Min {
+		c.add("time_range", "[%d, %d) is empty", tr.Min, tr.Max)
+	}
+}
+
+// multiRange 校验跨 BizId 的范围查询：start 和 end 可以为空，只提供 seq
+func (c *checker) multiRange(r *pb.RangeReq) {
+	switch {
+	case len(r.BizIds) > 0 && len(r.BizPrefix) > 0:
+		c.add("biz_prefix", "must be empty when biz_ids is set")
+	case len(r.BizIds) > MaxBizIDs:
+		c.add("biz_ids", "must have at most %d entries, got %d", MaxBizIDs, len(r.BizIds))
+	case len(r.BizPrefix) > MaxBizIDLen:
+		c.add("biz_prefix", "must be at most %d bytes, got %d", MaxBizIDLen, len(r.BizPrefix))
+	}
+	for i, bizID := range r.BizIds {
+		c.bizID(fmt.Sprintf("biz_ids[%d]", i), bizID)
+	}
+	seqOnly := func(field string, key *pb.SeqKey) {
+		if key == nil {
+			return
+		}
+		if len(key.BizId) > 0 {
+			c.add(join(field, "biz_id"), "must be empty when biz_ids or biz_prefix is set")
+		}
+		c.seq(join(field, "seq"), key.Seq)
+	}
+	seqOnly("start", r.Start)
+	seqOnly("end", r.End)
+}
+
+// rule 是一种请求消息的校验规则
+type rule func(msg any, c *checker)
+
+// typed 将针对具体消息类型的规则转换为 rule
+func typed[T any](fn func(T, *checker)) rule {
+	return func(msg any, c *checker) { fn(msg.(T), c) }
+}
+
+// rules 按请求消息的类型声明校验规则，未声明的消息不校验
+var rules = map[protoreflect.FullName]rule{
+	// Put、PutIfAbsent
+	"cloudpb.SeqItems": typed(func(r *pb.SeqItems, c *checker) {
+		c.items("", r)
+	}),
+	// BatchPut
+	"cloudpb.SeqItemsList": typed(func(r *pb.SeqItemsList, c *checker) {
+		for i, items := range r.ItemsList {
+			if items == nil {
+				c.add(fmt.Sprintf("items_list[%d]", i), "is required")
+				continue
+			}
+			c.items(fmt.Sprintf("items_list[%d]", i), items)
+		}
+	}),
+	// Get
+	"cloudpb.GetReq": typed(func(r *pb.GetReq, c *checker) {
+		c.bizID("biz_id", r.BizId)
+		c.seq("seq", r.Seq)
+		c.versions(r.AsOfTimestamp, r.TimeRange, r.MaxVersions)
+	}),
+	// GetMaxKey、GetMinKey、GetKeyCount 只使用 biz_id
+	"cloudpb.SeqKey": typed(func(r *pb.SeqKey, c *checker) {
+		c.bizID("biz_id", r.BizId)
+	}),
+	// BatchGet
+	"cloudpb.SeqKeys": typed(func(r *pb.SeqKeys, c *checker) {
+		for i, key := range r.Keys {
+			c.key(fmt.Sprintf("keys[%d]", i), key)
+		}
+	}),
+	// CompareAndSwap
+	"cloudpb.CasReq": typed(func(r *pb.CasReq, c *checker) {
+		for i, item := range r.Items {
+			field := fmt.Sprintf("items[%d]", i)
+			if item == nil {
+				c.add(field, "is required")
+				continue
+			}
+			c.item(join(field, "item"), item.Item)
+		}
+		c.nonNegative("ttl_seconds", r.TtlSeconds)
+	}),
+	// QueryRange、StreamRange、DeleteRange
+	"cloudpb.RangeReq": typed(func(r *pb.RangeReq, c *checker) {
+		if len(r.BizIds) > 0 || len(r.BizPrefix) > 0 {
+			c.multiRange(r)
+		} else {
+			c.key("start", r.Start)
+			c.key("end", r.End)
+			if r.Start != nil && r.End != nil && len(r.Start.BizId) > 0 && string(r.Start.BizId) != string(r.End.BizId) {
+				c.add("end.biz_id", "must equal start.biz_id %q, got %q", r.Start.BizId, r.End.BizId)
+			}
+		}
+		if _, ok := pb.RangeOption_name[int32(r.Option)]; !ok {
+			c.add("option", "unknown range option %d", r.Option)
+		}
+		c.nonNegative("limit", int64(r.Limit))
+		c.nonNegative("max_rows", r.MaxRows)
+		c.versions(r.AsOfTimestamp, r.TimeRange, r.MaxVersions)
+	}),
+	// Watch
+	"cloudpb.WatchReq": typed(func(r *pb.WatchReq, c *checker) {
+		c.bizID("biz_id", r.BizId)
+		c.seq("from_seq", r.FromSeq)
+	}),
+	// AllocateSeq
+	"cloudpb.AllocateSeqReq": typed(func(r *pb.AllocateSeqReq, c *checker) {
+		c.bizID("biz_id", r.BizId)
+		if r.Count <= 0 {
+			c.add("count", "must be positive, got %d", r.Count)
+		}
+	}),
+}
+
+// Violations 返回 msg 不满足的规则，msg 没有声明规则时返回 nil
+func Violations(msg any) []*errdetails.BadRequest_FieldViolation {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return nil
+	}
+	r, ok := rules[m.ProtoReflect().Descriptor().FullName()]
+	if !ok {
+		return nil
+	}
+	c := &checker{}
+	r(msg, c)
+	return c.violations
+}
+
+// Check 校验 msg，不满足规则时返回带 errdetails.BadRequest 的 InvalidArgument 错误
+func Check(msg any) error {
+	violations := Violations(msg)
+	if len(violations) == 0 {
+		return nil
+	}
+	descriptions := make([]string, len(violations))
+	for i, v := range violations {
+		descriptions[i] = v.Field + " " + v.Description
+	}
+	st := status.New(codes.InvalidArgument, "invalid request: "+strings.Join(descriptions, "; "))
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// UnaryServerInterceptor 在调用 handler 之前校验请求
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := Check(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 校验流式 RPC 收到的每个请求
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+}
+
+func (s *serverStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return Check(m)
+}
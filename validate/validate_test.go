@@ -0,0 +1,85 @@
+package validate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "go-hbase-demo/cloudpb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func key(biz string, seq int32) *pb.SeqKey {
+	return &pb.SeqKey{BizId: []byte(biz), Seq: seq}
+}
+
+func bizIDs(n int) [][]byte {
+	ids := make([][]byte, n)
+	for i := range ids {
+		ids[i] = []byte("biz")
+	}
+	return ids
+}
+
+func TestViolations(t *testing.T) {
+	tests := []struct {
+		name   string
+		msg    proto.Message
+		fields []string
+	}{
+		{"valid put", &pb.SeqItems{Items: []*pb.SeqItem{{Key: key("biz", 0)}}, TtlSeconds: 10}, nil},
+		{"put without key", &pb.SeqItems{Items: []*pb.SeqItem{{Key: key("biz", 1)}, {Value: []byte("v")}}}, []string{"items[1].key"}},
+		{"put bad key and ttl", &pb.SeqItems{Items: []*pb.SeqItem{{Key: key("", -1)}}, TtlSeconds: -1}, []string{"items[0].key.biz_id", "items[0].key.seq", "ttl_seconds"}},
+		{"batch put", &pb.SeqItemsList{ItemsList: []*pb.SeqItems{nil, {Items: []*pb.SeqItem{nil}}}}, []string{"items_list[0]", "items_list[1].items[0]"}},
+		{"long biz id", &pb.GetReq{BizId: make([]byte, MaxBizIDLen+1)}, []string{"biz_id"}},
+		{"get versions", &pb.GetReq{BizId: []byte("biz"), AsOfTimestamp: -1, TimeRange: &pb.TimeRange{Min: 5, Max: 5}}, []string{"as_of_timestamp", "time_range"}},
+		{"max key ignores seq", key("biz", -1), nil},
+		{"max key without biz", &pb.SeqKey{}, []string{"biz_id"}},
+		{"batch get", &pb.SeqKeys{Keys: []*pb.SeqKey{key("biz", 1), nil}}, []string{"keys[1]"}},
+		{"cas", &pb.CasReq{Items: []*pb.CasItem{nil, {}}}, []string{"items[0]", "items[1].item"}},
+		{"range", &pb.RangeReq{Start: key("a", 1), End: key("b", 2), Limit: -1, Option: 9}, []string{"end.biz_id", "option", "limit"}},
+		{"range without end", &pb.RangeReq{Start: key("a", 1)}, []string{"end"}},
+		{"multi range", &pb.RangeReq{BizIds: [][]byte{[]byte("a"), nil}, End: key("", 5)}, []string{"biz_ids[1]"}},
+		{"multi range with biz", &pb.RangeReq{BizPrefix: []byte("a"), Start: key("a", -1)}, []string{"start.biz_id", "start.seq"}},
+		{"multi range both", &pb.RangeReq{BizIds: [][]byte{[]byte("a")}, BizPrefix: []byte("a")}, []string{"biz_prefix"}},
+		{"multi range too many", &pb.RangeReq{BizIds: bizIDs(MaxBizIDs + 1)}, []string{"biz_ids"}},
+		{"watch", &pb.WatchReq{FromSeq: -1}, []string{"biz_id", "from_seq"}},
+		{"allocate", &pb.AllocateSeqReq{BizId: []byte("biz")}, []string{"count"}},
+		{"no rules", &pb.PutItemResp{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, v := range Violations(tt.msg) {
+				fields = append(fields, v.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("Violations() fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check(&pb.GetReq{BizId: []byte("biz"), Seq: 1}); err != nil {
+		t.Fatalf("Check(valid) = %v", err)
+	}
+	err := Check(&pb.GetReq{Seq: -1})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument || !strings.Contains(st.Message(), "biz_id must not be empty") {
+		t.Fatalf("Check() = %v, want InvalidArgument", err)
+	}
+	var badRequest *errdetails.BadRequest
+	for _, detail := range st.Details() {
+		if b, ok := detail.(*errdetails.BadRequest); ok {
+			badRequest = b
+		}
+	}
+	if len(badRequest.GetFieldViolations()) != 2 {
+		t.Errorf("BadRequest = %v, want 2 field violations", badRequest)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"math"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/store"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// readVersions 将请求中的 as_of_timestamp、time_range 和 max_versions 转换为 store.Versions
+// 三者都未设置时 versioned 为 false，此时按普通读取处理，结果中不返回时间戳和版本
+func readVersions(asOf int64, tr *pb.TimeRange, maxVersions int32) (v store.Versions, versioned bool, err error) {
+	switch {
+	case asOf < 0:
+		return v, false, status.Errorf(codes.InvalidArgument, "as_of_timestamp must not be negative, got %d", asOf)
+	case maxVersions < 0:
+		return v, false, status.Errorf(codes.InvalidArgument, "max_versions must not be negative, got %d", maxVersions)
+	case tr.GetMin() < 0 || tr.GetMax() < 0:
+		return v, false, status.Errorf(codes.InvalidArgument, "time_range must not be negative, got [%d, %d)", tr.GetMin(), tr.GetMax())
+	case tr.GetMax() != 0 && tr.GetMax() <= tr.GetMin():
+		return v, false, status.Errorf(codes.InvalidArgument, "time_range [%d, %d) is empty", tr.GetMin(), tr.GetMax())
+	}
+
+	v = store.Versions{MinTimestamp: tr.GetMin(), MaxTimestamp: tr.GetMax(), MaxVersions: int(maxVersions)}
+	// as_of_timestamp 包含该时刻，对应开区间上界 asOf+1
+	if asOf > 0 && asOf < math.MaxInt64 && (v.MaxTimestamp == 0 || asOf+1 < v.MaxTimestamp) {
+		v.MaxTimestamp = asOf + 1
+	}
+	if v.MaxTimestamp != 0 && v.MaxTimestamp <= v.MinTimestamp {
+		return v, false, status.Errorf(codes.InvalidArgument, "as_of_timestamp %d is before time_range [%d, %d)", asOf, tr.GetMin(), tr.GetMax())
+	}
+	return v, asOf != 0 || tr != nil || maxVersions != 0, nil
+}
+
+// decodeVersions 为 decodeRow 得到的 item 填充时间戳和 row 中的所有版本
+func (s *server) decodeVersions(row *store.Row, item *pb.SeqItem) error {
+	item.Timestamp = row.Timestamp
+	item.Versions = make([]*pb.SeqItemVersion, len(row.Versions))
+	for i, cell := range row.Versions {
+		version, err := s.decodeRow(&store.Row{Key: row.Key, Value: cell.Value})
+		if err != nil {
+			return err
+		}
+		item.Versions[i] = &pb.SeqItemVersion{Timestamp: cell.Timestamp, Value: version.Value}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"math"
+
+	pb "go-hbase-demo/cloudpb"
+	"go-hbase-demo/tenant"
+	"go-hbase-demo/watch"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// watchBuffer 是每个 Watch 缓冲的新写入 SeqItem 数，客户端消费跟不上时 Watch 以 Aborted 结束，由客户端续传
+const watchBuffer = watch.DefaultBuffer
+
+// 实现 gRPC 服务的 Watch 方法
+// 先订阅 BizId 的新写入再回放已有数据，回放期间写入的 SeqItem 在回放结束后推送，不会遗漏；
+// 其中 seq 和时间戳都与回放的某个 SeqItem 相同的视为已包含在回放中，不再推送，
+// 回放扫过之后才被覆盖的 seq 时间戳不同，仍会推送；时间戳未知的写入总是推送
+func (s *server) Watch(req *pb.WatchReq, stream pb.SeqDb_WatchServer) error {
+	ctx := stream.Context()
+	sub := s.hub.Subscribe(tenant.FromContext(ctx), req.BizId, watchBuffer)
+	defer sub.Close()
+
+	next := int64(req.FromSeq) // 续传位置，即已推送的最大 seq + 1
+	send := func(item *pb.SeqItem) error {
+		// 时间戳和版本只用于去重，不推送给客户端
+		if err := stream.Send(&pb.SeqItem{Key: item.Key, Value: item.Value}); err != nil {
+			return err
+		}
+		next = max(next, int64(item.Key.Seq)+1)
+		return nil
+	}
+	replayed := map[int32]int64{} // 回放的 seq -> 时间戳
+	replay := &pb.RangeReq{
+		Start:       &pb.SeqKey{BizId: req.BizId, Seq: req.FromSeq},
+		End:         &pb.SeqKey{BizId: req.BizId, Seq: math.MaxInt32},
+		MaxVersions: 1, // 读取时间戳
+	}
+	if _, err := s.scanRange(ctx, replay, func(item *pb.SeqItem) error {
+		replayed[item.Key.Seq] = item.Timestamp
+		return send(item)
+	}); err != nil {
+		return err
+	}
+	pending := len(sub.C) // 回放期间写入、已在缓冲区中的 SeqItem 数
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-sub.C:
+			if !ok {
+				return watchAborted(req.BizId, next)
+			}
+			skip := item.Key.Seq < req.FromSeq
+			if pending > 0 {
+				ts, ok := replayed[item.Key.Seq]
+				skip = skip || ok && item.Timestamp != 0 && item.Timestamp == ts
+				if pending--; pending == 0 {
+					replayed = nil
+				}
+			}
+			if skip {
+				continue
+			}
+			if err := send(item); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watchAborted 返回消费过慢时结束 Watch 的错误，附带续传位置的 SeqKey（见 apierr.KeyOf）
+func watchAborted(bizID []byte, next int64) error {
+	st := status.Newf(codes.Aborted, "watch of biz_id %q fell behind, resume with from_seq %d", bizID, next)
+	if next <= math.MaxInt32 {
+		if detailed, err := st.WithDetails(&pb.SeqKey{BizId: bizID, Seq: int32(next)}); err == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
+
+// publish 将写入成功的 items 分发给 Watch，statuses 是与 items 一一对应的写入结果
+// 分发的 SeqItem 带有写入的时间戳，Watch 据此与回放的 SeqItem 去重
+func (s *server) publish(ctx context.Context, items []*pb.SeqItem, statuses []*pb.PutStatus) {
+	var committed []*pb.SeqItem
+	for i, item := range items {
+		if statuses[i].Code == int32(codes.OK) {
+			committed = append(committed, &pb.SeqItem{Key: item.Key, Value: item.Value, Timestamp: statuses[i].Timestamp})
+		}
+	}
+	if len(committed) > 0 {
+		s.hub.Publish(tenant.FromContext(ctx), committed)
+	}
+}
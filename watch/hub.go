@@ -0,0 +1,111 @@
+// Package watch 将写入成功的 SeqItem 分发给订阅了其 BizId 的 Watch
+//
+// Hub 只在进程内分发：写入路径在写入成功后调用 Publish，Watch 通过 Subscribe 订阅。
+// Publish 不会阻塞写入，订阅者的缓冲区满时该订阅被终止（Subscription.C 被关闭，Overflowed 返回 true），
+// 由 Watch 通知客户端按 seq 续传
+package watch
+
+import (
+	"sync"
+
+	pb "go-hbase-demo/cloudpb"
+)
+
+// DefaultBuffer 是每个订阅默认缓冲的 SeqItem 数
+const DefaultBuffer = 1024
+
+// Hub 按 (namespace, BizId) 分发 SeqItem，零值不可用，使用 NewHub 创建
+type Hub struct {
+	mu     sync.Mutex
+	topics map[string]map[*Subscription]struct{}
+}
+
+// NewHub 创建 Hub
+func NewHub() *Hub {
+	return &Hub{topics: map[string]map[*Subscription]struct{}{}}
+}
+
+// Subscription 是对一个 BizId 的订阅
+type Subscription struct {
+	C <-chan *pb.SeqItem // 订阅之后写入的 SeqItem，按 Publish 的顺序到达
+
+	hub        *Hub
+	topic      string
+	ch         chan *pb.SeqItem
+	closed     bool // 已从 hub 中移除，ch 已关闭；由 hub.mu 保护
+	overflowed bool // 因缓冲区满被终止；由 hub.mu 保护
+}
+
+// topic 返回 namespace 中 bizID 的订阅主题，不同租户的同名 BizId 互不影响
+func topic(ns string, bizID []byte) string {
+	return ns + "\x00" + string(bizID)
+}
+
+// Subscribe 订阅 namespace 中 bizID 之后写入的 SeqItem，buffer 为缓冲的 SeqItem 数，0 表示 DefaultBuffer
+// 订阅者须调用 Close 取消订阅
+func (h *Hub) Subscribe(ns string, bizID []byte, buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	ch := make(chan *pb.SeqItem, buffer)
+	sub := &Subscription{C: ch, hub: h, topic: topic(ns, bizID), ch: ch}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subs, ok := h.topics[sub.topic]
+	if !ok {
+		subs = map[*Subscription]struct{}{}
+		h.topics[sub.topic] = subs
+	}
+	subs[sub] = struct{}{}
+	return sub
+}
+
+// Publish 将 namespace 中写入成功的 items 分发给订阅者，不会阻塞；h 为 nil 时不做任何事
+// items 的 Timestamp 为写入的 HBase 时间戳，未知时为 0
+func (h *Hub) Publish(ns string, items []*pb.SeqItem) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, item := range items {
+		subs := h.topics[topic(ns, item.GetKey().GetBizId())]
+		for sub := range subs {
+			select {
+			case sub.ch <- item:
+			default:
+				// 订阅者跟不上写入，终止订阅而不是阻塞写入或静默丢弃
+				sub.overflowed = true
+				h.remove(sub)
+			}
+		}
+	}
+}
+
+// remove 将 sub 从 hub 中移除并关闭其 channel，调用方须持有 h.mu
+func (h *Hub) remove(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.ch)
+	subs := h.topics[sub.topic]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.topics, sub.topic)
+	}
+}
+
+// Close 取消订阅，可以重复调用
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Overflowed 判断订阅是否因缓冲区满而被终止
+func (s *Subscription) Overflowed() bool {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.overflowed
+}
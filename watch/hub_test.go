@@ -0,0 +1,87 @@
+package watch
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	pb "go-hbase-demo/cloudpb"
+)
+
+func item(biz string, seq int32) *pb.SeqItem {
+	return &pb.SeqItem{Key: &pb.SeqKey{BizId: []byte(biz), Seq: seq}}
+}
+
+// drain 读出 sub.C 中已缓冲的 SeqItem
+func drain(sub *Subscription) []string {
+	var got []string
+	for {
+		select {
+		case item, ok := <-sub.C:
+			if !ok {
+				return append(got, "closed")
+			}
+			got = append(got, fmt.Sprintf("%s/%d", item.Key.BizId, item.Key.Seq))
+		default:
+			return got
+		}
+	}
+}
+
+func TestHub(t *testing.T) {
+	h := NewHub()
+	biz1 := h.Subscribe("", []byte("biz1"), 0)
+	biz1Again := h.Subscribe("", []byte("biz1"), 0)
+	tenant := h.Subscribe("ns1", []byte("biz1"), 0)
+	biz2 := h.Subscribe("", []byte("biz2"), 0)
+
+	h.Publish("", []*pb.SeqItem{item("biz1", 1), item("biz2", 1), item("biz1", 2)})
+	h.Publish("ns1", []*pb.SeqItem{item("biz1", 7)})
+	biz1Again.Close()
+	biz1Again.Close()
+	h.Publish("", []*pb.SeqItem{item("biz1", 3)})
+
+	tests := []struct {
+		name string
+		sub  *Subscription
+		want []string
+	}{
+		{"biz1", biz1, []string{"biz1/1", "biz1/2", "biz1/3"}},
+		{"closed", biz1Again, []string{"biz1/1", "biz1/2", "closed"}},
+		{"tenant", tenant, []string{"biz1/7"}},
+		{"biz2", biz2, []string{"biz2/1"}},
+	}
+	for _, tt := range tests {
+		if got := drain(tt.sub); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// 所有订阅关闭后主题被清理
+	for _, sub := range []*Subscription{biz1, tenant, biz2} {
+		sub.Close()
+	}
+	if len(h.topics) != 0 {
+		t.Errorf("topics = %v, want empty", h.topics)
+	}
+	var nilHub *Hub
+	nilHub.Publish("", []*pb.SeqItem{item("biz1", 1)})
+}
+
+func TestHub_Overflow(t *testing.T) {
+	h := NewHub()
+	slow := h.Subscribe("", []byte("biz1"), 2)
+	fast := h.Subscribe("", []byte("biz1"), 10)
+	h.Publish("", []*pb.SeqItem{item("biz1", 1), item("biz1", 2), item("biz1", 3)})
+
+	if !slow.Overflowed() || fast.Overflowed() {
+		t.Fatalf("Overflowed() = %v, %v, want true, false", slow.Overflowed(), fast.Overflowed())
+	}
+	if got, want := drain(slow), []string{"biz1/1", "biz1/2", "closed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("slow subscriber got %v, want %v", got, want)
+	}
+	if got := drain(fast); len(got) != 3 {
+		t.Errorf("fast subscriber got %v, want 3 items", got)
+	}
+	slow.Close()
+}